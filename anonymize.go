@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A renameMap records the names that anonymize gave variables and rows.
+type renameMap struct {
+	Variables   map[string]string `json:"variables"`
+	Constraints map[string]string `json:"constraints"`
+}
+
+// anonymizeCmd implements "lpvet anonymize", which renames the variables
+// and constraints of a model so that it can be shared.
+func anonymizeCmd(args []string) {
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	mapFile := fs.String("map", "", "write the mapping from old to new names to this JSON `file`")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet anonymize [-map map.json] [-o out] in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 {
+		fs.Usage()
+	}
+	in := ins[0]
+	name := in
+	if in == "-" {
+		name = *cmdStdinName
+	}
+	m := mustReadModel(in)
+	vars, rows := lp.Anonymize(m)
+
+	var b bytes.Buffer
+	var err error
+	switch f := formatOf(name); f {
+	case lp.FormatMPS, lp.FormatFreeMPS:
+		err = lp.WriteMPS(&b, m, "model", f)
+	default:
+		err = lp.WriteLP(&b, m)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *mapFile != "" {
+		data, err := json.MarshalIndent(renameMap{vars, rows}, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*mapFile, append(data, '\n'), 0666); err != nil {
+			log.Fatal(err)
+		}
+	}
+	writeOutput(*out, b.Bytes())
+}
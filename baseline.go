@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A baseline records known problems so that only new ones are reported.
+//
+// Findings are matched by file, check, and message but not by position,
+// so that unrelated edits that move lines around keep them matched.
+// Positions within messages, such as the line of an earlier bound,
+// are left out of the match for the same reason.
+// Each recorded finding matches at most one problem.
+type baseline struct {
+	Version  int               `json:"version"`
+	Findings []baselineFinding `json:"findings"`
+}
+
+type baselineFinding struct {
+	File    string `json:"file"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+func findingOf(d lp.Diagnostic) baselineFinding {
+	return baselineFinding{
+		File:    filepath.ToSlash(filepath.Clean(d.Pos.File)),
+		Check:   d.Check,
+		Message: d.Message,
+	}
+}
+
+// positionRE matches the positions in messages:
+// lists of lines, such as "line 3" or "lines 3, 7, 2 more",
+// and positions such as "at model.lp:3:5".
+var positionRE = regexp.MustCompile(`\blines? \d+(, \d+( more)?)*|\bat \S*:\d+(:\d+)?`)
+
+// matchKey returns f with the positions in its message replaced,
+// for matching regardless of them.
+func (f baselineFinding) matchKey() baselineFinding {
+	f.Message = positionRE.ReplaceAllStringFunc(f.Message, func(m string) string {
+		if m[0] == 'a' {
+			return "at ?"
+		}
+		return "line ?"
+	})
+	return f
+}
+
+func readBaseline(p string) (map[baselineFinding]int, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+	if b.Version != 1 {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", p, b.Version)
+	}
+	known := make(map[baselineFinding]int)
+	for _, f := range b.Findings {
+		known[f.matchKey()]++
+	}
+	return known, nil
+}
+
+// filterBaseline returns the diagnostics in diags that are not in known,
+// removing the ones that match from known.
+func filterBaseline(diags []lp.Diagnostic, known map[baselineFinding]int) []lp.Diagnostic {
+	var fresh []lp.Diagnostic
+	for _, d := range diags {
+		f := findingOf(d).matchKey()
+		if known[f] > 0 {
+			known[f]--
+			continue
+		}
+		fresh = append(fresh, d)
+	}
+	return fresh
+}
+
+func writeBaseline(p string, diags []lp.Diagnostic) error {
+	b := baseline{Version: 1, Findings: []baselineFinding{}}
+	for _, d := range diags {
+		b.Findings = append(b.Findings, findingOf(d))
+	}
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, append(data, '\n'), 0666)
+}
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// browseCmd implements "lpvet browse", which opens a terminal UI
+// for exploring a model: its sections, constraints, and variables,
+// where each is defined, and which constraints use each variable.
+func browseCmd(args []string) {
+	fs := flag.NewFlagSet("browse", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet browse in")
+		fmt.Fprintln(os.Stderr, "\nKeys: arrows or j/k move, enter opens, / searches, tab switches lists,")
+		fmt.Fprintln(os.Stderr, "[ and ] jump between sections, esc or q goes back.")
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 {
+		fs.Usage()
+	}
+	m := mustReadModel(ins[0])
+	b := newBrowser(m)
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		log.Fatalf("browse needs a terminal: %v", err)
+	}
+	state, err := stty(tty, "-g")
+	if err != nil {
+		log.Fatalf("browse needs a terminal: %v", err)
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		log.Fatal(err)
+	}
+	tty.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		tty.WriteString("\x1b[?25h\x1b[?1049l")
+		stty(tty, state)
+	}()
+	b.run(tty)
+}
+
+// stty runs stty on tty and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// A browseView is a scrollable list of items, such as the variables
+// of the model or the lines of its source.
+type browseView struct {
+	title string
+	n     int
+	text  func(i int) string
+	key   func(i int) string // what searches match; text if nil
+	open  func(i int) *browseView
+	marks []int // items that [ and ] jump between, in order
+
+	filter string
+	shown  []int // items matching filter, or nil for all of them
+	cursor int   // index into the shown items
+	top    int
+}
+
+func (v *browseView) len() int {
+	if v.shown != nil {
+		return len(v.shown)
+	}
+	return v.n
+}
+
+func (v *browseView) item(i int) int {
+	if v.shown != nil {
+		return v.shown[i]
+	}
+	return i
+}
+
+// search shows only the items containing s, ignoring case.
+func (v *browseView) search(s string) {
+	cur := -1
+	if v.len() > 0 {
+		cur = v.item(v.cursor)
+	}
+	v.filter, v.shown, v.cursor, v.top = s, nil, 0, 0
+	if s != "" {
+		s = strings.ToLower(s)
+		v.shown = []int{}
+		for i := 0; i < v.n; i++ {
+			k := v.text(i)
+			if v.key != nil {
+				k = v.key(i)
+			}
+			if strings.Contains(strings.ToLower(k), s) {
+				v.shown = append(v.shown, i)
+			}
+		}
+	}
+	v.moveTo(cur)
+}
+
+// moveTo moves the cursor to item i if it is shown.
+func (v *browseView) moveTo(i int) {
+	for j := 0; j < v.len(); j++ {
+		if v.item(j) >= i {
+			v.cursor = j
+			return
+		}
+	}
+}
+
+// A browser holds the model being browsed and the views open on it.
+type browser struct {
+	m        *lp.LP
+	mat      *lp.Matrix
+	colIndex map[string]int
+	uses     [][]int // entries of mat in each column
+	vars     []lp.Variable
+	objs     []*lp.Objective
+
+	tabs  []*browseView
+	tab   int
+	stack []*browseView // views opened from the current tab
+
+	status string // a message shown until the next key
+}
+
+func newBrowser(m *lp.LP) *browser {
+	b := &browser{m: m, mat: lp.ConstraintMatrix(m), colIndex: make(map[string]int), vars: lp.Variables(m)}
+	for i, name := range b.mat.Cols {
+		b.colIndex[name] = i
+	}
+	b.uses = make([][]int, len(b.mat.Cols))
+	for i, e := range b.mat.Entries {
+		b.uses[e.Col] = append(b.uses[e.Col], i)
+	}
+	b.objs = m.MultiObj
+	if len(b.objs) == 0 && m.Obj != nil {
+		b.objs = []*lp.Objective{m.Obj}
+	}
+	b.tabs = []*browseView{b.sectionsView(), b.rowsView(), b.varsView()}
+	return b
+}
+
+func (b *browser) sectionsView() *browseView {
+	secs := lp.ComputeStats(b.m).Sections
+	return &browseView{
+		title: "Sections",
+		n:     len(b.m.Headers),
+		text: func(i int) string {
+			h := b.m.Headers[i]
+			return fmt.Sprintf("%-24s line %d, %s", h.Value, h.Pos.Line, plural(secs[i].Lines, "line"))
+		},
+		key:  func(i int) string { return b.m.Headers[i].Value },
+		open: func(i int) *browseView { return b.source(b.m.Headers[i].Pos) },
+	}
+}
+
+func (b *browser) rowsView() *browseView {
+	no := len(b.objs)
+	return &browseView{
+		title: "Objectives and constraints",
+		n:     no + len(b.m.Rows),
+		text: func(i int) string {
+			if i < no {
+				o := b.objs[i]
+				return fmt.Sprintf("%-24s %s, %s", b.rowName(i), o.Sense, plural(len(o.Expr.Combined().Terms), "term"))
+			}
+			c := b.m.Rows[i-no]
+			e, lo, hi := c.Limits()
+			desc := limitsDesc(lo, hi, c.Ranged)
+			if c.Indicator != nil {
+				desc = "indicator, " + desc
+			}
+			return fmt.Sprintf("%-24s %s, %s", b.rowName(i), desc, plural(len(e.Terms)+len(e.Quad), "term"))
+		},
+		key: b.rowName,
+		open: func(i int) *browseView {
+			if i < no {
+				return b.source(b.objs[i].Pos)
+			}
+			return b.source(b.m.Rows[i-no].Pos)
+		},
+	}
+}
+
+// rowName returns the name of objective i, or of constraint i-len(b.objs).
+func (b *browser) rowName(i int) string {
+	if i >= len(b.objs) {
+		return b.mat.Rows[i-len(b.objs)]
+	}
+	if name := b.objs[i].Name; name != "" {
+		return name
+	}
+	return "(objective)"
+}
+
+func (b *browser) varsView() *browseView {
+	return &browseView{
+		title: "Variables",
+		n:     len(b.vars),
+		text: func(i int) string {
+			v := b.vars[i]
+			n := 0
+			if j, ok := b.colIndex[v.Name]; ok {
+				n = len(b.uses[j])
+			}
+			return fmt.Sprintf("%-24s %s in [%s, %s], %s", v.Name, v.Type, formatBound(v.Lo), formatBound(v.Hi), plural(n, "constraint"))
+		},
+		key:  func(i int) string { return b.vars[i].Name },
+		open: func(i int) *browseView { return b.varView(b.vars[i]) },
+	}
+}
+
+// varView lists where v is defined, bounded, and used.
+func (b *browser) varView(v lp.Variable) *browseView {
+	type use struct {
+		text string
+		pos  lp.Pos
+	}
+	uses := []use{{fmt.Sprintf("first appears at %s: %s in [%s, %s]", v.Pos, v.Type, formatBound(v.Lo), formatBound(v.Hi)), v.Pos}}
+	for i, o := range b.objs {
+		for _, t := range o.Expr.Combined().Terms {
+			if t.Var.Value == v.Name {
+				uses = append(uses, use{fmt.Sprintf("objective %s: %s", b.rowName(i), formatBound(t.Coef)), o.Pos})
+			}
+		}
+	}
+	for _, bd := range b.m.VarBounds {
+		if bd.Var.Value != v.Name {
+			continue
+		}
+		desc := "free"
+		switch {
+		case bd.Free:
+		case bd.HasLower && bd.HasUpper:
+			desc = fmt.Sprintf("in [%s, %s]", formatBound(bd.Lower), formatBound(bd.Upper))
+		case bd.HasLower:
+			desc = ">= " + formatBound(bd.Lower)
+		default:
+			desc = "<= " + formatBound(bd.Upper)
+		}
+		uses = append(uses, use{"bound: " + desc, bd.Pos})
+	}
+	if j, ok := b.colIndex[v.Name]; ok {
+		for _, k := range b.uses[j] {
+			e := b.mat.Entries[k]
+			uses = append(uses, use{fmt.Sprintf("constraint %s: %s", b.mat.Rows[e.Row], formatBound(e.Value)), b.m.Rows[e.Row].Pos})
+		}
+	}
+	return &browseView{
+		title: "Variable " + v.Name,
+		n:     len(uses),
+		text:  func(i int) string { return uses[i].text },
+		open:  func(i int) *browseView { return b.source(uses[i].pos) },
+	}
+}
+
+// source returns a view of the file of pos with the cursor on its line,
+// or nil if the file cannot be read.
+func (b *browser) source(pos lp.Pos) *browseView {
+	lines := linesOf(pos.File)
+	if len(lines) == 0 {
+		b.status = "cannot read " + pos.File
+		return nil
+	}
+	width := len(strconv.Itoa(len(lines)))
+	v := &browseView{
+		title: pos.File,
+		n:     len(lines),
+		text: func(i int) string {
+			return fmt.Sprintf("%*d  %s", width, i+1, strings.TrimRight(lines[i], "\r"))
+		},
+		key: func(i int) string { return lines[i] },
+	}
+	for _, h := range b.m.Headers {
+		if h.Pos.File == pos.File {
+			v.marks = append(v.marks, int(h.Pos.Line)-1)
+		}
+	}
+	v.moveTo(int(pos.Line) - 1)
+	v.top = -1 // center the cursor
+	return v
+}
+
+// limitsDesc describes the limits lo and hi of a constraint.
+func limitsDesc(lo, hi float64, ranged bool) string {
+	switch {
+	case ranged:
+		return fmt.Sprintf("in [%s, %s]", formatBound(lo), formatBound(hi))
+	case math.IsInf(lo, -1):
+		return "<= " + formatBound(hi)
+	case math.IsInf(hi, 1):
+		return ">= " + formatBound(lo)
+	}
+	return "= " + formatBound(lo)
+}
+
+func (b *browser) view() *browseView {
+	if len(b.stack) > 0 {
+		return b.stack[len(b.stack)-1]
+	}
+	return b.tabs[b.tab]
+}
+
+// run draws the current view and handles keys until the user quits.
+func (b *browser) run(tty *os.File) {
+	var searching bool
+	var query string
+	buf := make([]byte, 64)
+	for {
+		height, width := 24, 80
+		if size, err := stty(tty, "size"); err == nil {
+			fmt.Sscan(size, &height, &width)
+		}
+		prompt := ""
+		if searching {
+			prompt = "/" + query
+		}
+		tty.Write(b.draw(height, width, prompt))
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range splitKeys(buf[:n]) {
+			b.status = ""
+			if searching {
+				switch k {
+				case "enter":
+					b.view().search(query)
+					searching = false
+				case "esc":
+					searching = false
+				case "backspace":
+					if query != "" {
+						query = query[:len(query)-1]
+					}
+				default:
+					if len(k) == 1 {
+						query += k
+					}
+				}
+				continue
+			}
+			v := b.view()
+			page := height - 3
+			switch k {
+			case "q", "esc", "backspace":
+				if v.filter != "" {
+					v.search("")
+				} else if len(b.stack) > 0 {
+					b.stack = b.stack[:len(b.stack)-1]
+				} else if k == "q" {
+					return
+				}
+			case "ctrl-c":
+				return
+			case "up", "k":
+				v.cursor--
+			case "down", "j":
+				v.cursor++
+			case "pgup":
+				v.cursor -= page
+				if v.top -= page; v.top < 0 {
+					v.top = 0
+				}
+			case "pgdn", " ":
+				v.cursor += page
+				v.top += page
+			case "home", "g":
+				v.cursor = 0
+			case "end", "G":
+				v.cursor = v.len() - 1
+			case "tab":
+				if len(b.stack) == 0 {
+					b.tab = (b.tab + 1) % len(b.tabs)
+				}
+			case "/":
+				searching, query = true, v.filter
+			case "[", "]":
+				cur := v.item(v.cursor)
+				for i := range v.marks {
+					mark := v.marks[i]
+					if k == "[" {
+						mark = v.marks[len(v.marks)-1-i]
+					}
+					if k == "]" && mark > cur || k == "[" && mark < cur {
+						v.search("")
+						v.moveTo(mark)
+						v.top = -1
+						break
+					}
+				}
+			case "enter":
+				if v.len() == 0 {
+					break
+				}
+				i := v.item(v.cursor)
+				if v.open == nil {
+					// In a search of the source, show the line in context.
+					v.search("")
+					v.moveTo(i)
+					v.top = -1
+				} else if next := v.open(i); next != nil {
+					b.stack = append(b.stack, next)
+				}
+			}
+		}
+	}
+}
+
+// splitKeys splits the input read from a terminal into keys,
+// naming the special ones.
+func splitKeys(in []byte) []string {
+	var keys []string
+	for len(in) > 0 {
+		if in[0] == 0x1b && len(in) >= 3 && (in[1] == '[' || in[1] == 'O') {
+			end := 2
+			for end < len(in) && end < 8 && (in[end] < 0x40 || in[end] > 0x7e) {
+				end++
+			}
+			if end < len(in) {
+				end++
+			}
+			names := map[string]string{"A": "up", "B": "down", "H": "home", "F": "end", "5~": "pgup", "6~": "pgdn", "1~": "home", "4~": "end"}
+			if name, ok := names[string(in[2:end])]; ok {
+				keys = append(keys, name)
+			}
+			in = in[end:]
+			continue
+		}
+		switch in[0] {
+		case 0x1b:
+			keys = append(keys, "esc")
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		case '\t':
+			keys = append(keys, "tab")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			if in[0] >= ' ' && in[0] < 0x7f {
+				keys = append(keys, string(in[:1]))
+			}
+		}
+		in = in[1:]
+	}
+	return keys
+}
+
+// draw returns the escape sequences that draw the current view
+// on a terminal of the given size.
+func (b *browser) draw(height, width int, prompt string) []byte {
+	v := b.view()
+	rows := height - 2
+	if rows < 1 {
+		rows = 1
+	}
+	if v.cursor >= v.len() {
+		v.cursor = v.len() - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	if v.top < 0 {
+		v.top = v.cursor - rows/2
+	}
+	if v.top > v.cursor {
+		v.top = v.cursor
+	}
+	if v.top <= v.cursor-rows {
+		v.top = v.cursor - rows + 1
+	}
+	if v.top > v.len()-rows {
+		v.top = v.len() - rows
+	}
+	if v.top < 0 {
+		v.top = 0
+	}
+
+	clip := func(s string) string {
+		s = strings.ReplaceAll(s, "\t", "    ")
+		if len(s) > width {
+			s = s[:width]
+		}
+		return s
+	}
+	var out bytes.Buffer
+	out.WriteString("\x1b[H\x1b[2J")
+	title := v.title
+	if len(b.stack) == 0 {
+		var names []string
+		for i, t := range b.tabs {
+			if i == b.tab {
+				names = append(names, "["+t.title+"]")
+			} else {
+				names = append(names, t.title)
+			}
+		}
+		title = strings.Join(names, "  ")
+	}
+	if v.filter != "" {
+		title += fmt.Sprintf("  (matching %q)", v.filter)
+	}
+	fmt.Fprintf(&out, "\x1b[1m%s\x1b[0m\r\n", clip(title))
+	for r := 0; r < rows; r++ {
+		i := v.top + r
+		if i < v.len() {
+			text := clip(v.text(v.item(i)))
+			if i == v.cursor {
+				fmt.Fprintf(&out, "\x1b[7m%-*s\x1b[0m", width, text)
+			} else {
+				out.WriteString(text)
+			}
+		}
+		out.WriteString("\r\n")
+	}
+	status := prompt
+	switch {
+	case status != "":
+	case b.status != "":
+		status = b.status
+	default:
+		pos := 0
+		if v.len() > 0 {
+			pos = v.cursor + 1
+		}
+		status = fmt.Sprintf("%d/%d  enter open  / search  tab lists  [ ] sections  esc back  q quit", pos, v.len())
+	}
+	fmt.Fprintf(&out, "\x1b[2m%s\x1b[0m", clip(status))
+	return out.Bytes()
+}
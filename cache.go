@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A resultCache stores the problems found in models on disk,
+// keyed by a hash of the model and the settings that affect them.
+// Problems with the cache are not reported; they only make lpvet slower.
+type resultCache struct {
+	dir  string
+	salt []byte // hash of the lpvet executable
+}
+
+// openCache returns the cache in the user's cache directory,
+// such as ~/.cache/lpvet, or nil if it can't be used.
+func openCache() *resultCache {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	// Results from other versions of lpvet may differ,
+	// so the executable is part of every key.
+	exe, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil
+	}
+	return &resultCache{dir: filepath.Join(base, "lpvet"), salt: h.Sum(nil)}
+}
+
+// key returns the key of the results for the model called name
+// with contents data.
+// It covers the settings that affect which problems are found,
+// including the disabled checks, which let others report their symbols;
+// those applied afterwards, such as -severity, are not included.
+func (c *resultCache) key(name string, format lp.Format, data []byte) string {
+	h := sha256.New()
+	h.Write(c.salt)
+	profileName := ""
+	if profile != nil {
+		profileName = profile.Name
+	}
+	lim := limits()
+	fmt.Fprintf(h, "%q %v %v %q %d %d %q %d %g\n", name, format, *cmdStrictDecls, profileName, lim.MaxVarLen, lim.MaxLineLen, lim.NameChars, cfg.truncateLen, cfg.maxCoefRange)
+	var off []string
+	for id, d := range disabled {
+		if d {
+			off = append(off, id)
+		}
+	}
+	sort.Strings(off)
+	fmt.Fprintf(h, "%q\n", off)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *resultCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func (c *resultCache) get(key string) ([]lp.Diagnostic, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var diags []lp.Diagnostic
+	if err := json.Unmarshal(data, &diags); err != nil {
+		return nil, false
+	}
+	return diags, true
+}
+
+func (c *resultCache) put(key string, diags []lp.Diagnostic) {
+	data, err := json.Marshal(diags)
+	if err != nil {
+		return
+	}
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return
+	}
+	// Write to a temporary file first so that concurrent runs
+	// never see a partial result.
+	f, err := os.CreateTemp(filepath.Dir(p), "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), p)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
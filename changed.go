@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// changedLines maps files to the line numbers (in their new version)
+// that a unified diff adds or modifies.
+type changedLines map[string]map[int32]bool
+
+// gitChanges returns the lines changed since the git revision base.
+func gitChanges(base string) (changedLines, error) {
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--relative", "-U0", base, "--")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %v", base, err)
+	}
+	return parseUnifiedDiff(strings.NewReader(string(out)))
+}
+
+// parseUnifiedDiff returns the lines added by the unified diff in r.
+// Paths have their "b/" prefix removed.
+func parseUnifiedDiff(r io.Reader) (changedLines, error) {
+	ch := make(changedLines)
+	var (
+		cur  map[int32]bool
+		next int32 // number in the new file of the next hunk line
+		left int   // lines left in the current hunk on the new side
+	)
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case left > 0 && strings.HasPrefix(line, "+"):
+			cur[next] = true
+			next++
+			left--
+		case left > 0 && (strings.HasPrefix(line, " ") || line == ""):
+			// Context.
+			next++
+			left--
+		case left > 0 && (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "\\")):
+			// Removed lines and "\ No newline at end of file".
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if i := strings.IndexByte(name, '\t'); i >= 0 {
+				name = name[:i] // timestamp
+			}
+			if name == "/dev/null" {
+				cur = nil
+				continue
+			}
+			name = filepath.Clean(strings.TrimPrefix(name, "b/"))
+			if cur = ch[name]; cur == nil {
+				cur = make(map[int32]bool)
+				ch[name] = cur
+			}
+		case strings.HasPrefix(line, "@@ ") && cur != nil:
+			// @@ -l,s +l,s @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, n := fields[2][1:], "1"
+			if i := strings.IndexByte(start, ','); i >= 0 {
+				start, n = start[:i], start[i+1:]
+			}
+			first, err1 := strconv.Atoi(start)
+			count, err2 := strconv.Atoi(n)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			next, left = int32(first), count
+		}
+	}
+	return ch, s.Err()
+}
+
+// lines returns the changed lines of file, or nil if it is unchanged.
+// Paths match if they are equal or one is a suffix of the other,
+// since diffs are relative to the repository root.
+func (ch changedLines) lines(file string) map[int32]bool {
+	file = filepath.Clean(file)
+	if l, ok := ch[file]; ok {
+		return l
+	}
+	slashed := filepath.ToSlash(file)
+	for name, l := range ch {
+		n := filepath.ToSlash(name)
+		if strings.HasSuffix(slashed, "/"+n) || strings.HasSuffix(n, "/"+slashed) {
+			return l
+		}
+	}
+	return nil
+}
+
+// filter returns the diagnostics in diags on changed lines.
+// Diagnostics about a whole file are kept if the file changed.
+func (ch changedLines) filter(diags []lp.Diagnostic) []lp.Diagnostic {
+	var kept []lp.Diagnostic
+	for _, d := range diags {
+		l := ch.lines(d.Pos.File)
+		if l != nil && (d.Pos.Line == 0 || l[d.Pos.Line]) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
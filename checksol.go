@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type jsonViolation struct {
+	Kind   string  `json:"kind"`
+	Name   string  `json:"name"`
+	Pos    string  `json:"pos,omitempty"`
+	Value  float64 `json:"value"`
+	Limit  float64 `json:"limit"`
+	Amount float64 `json:"amount"`
+	Detail string  `json:"detail"`
+}
+
+type jsonSolutionCheck struct {
+	Violations []jsonViolation `json:"violations"`
+	Missing    []string        `json:"missing,omitempty"`
+	Unknown    []string        `json:"unknown,omitempty"`
+	Objective  *float64        `json:"objective,omitempty"`
+	Slacks     []jsonSlack     `json:"slacks,omitempty"`
+}
+
+type jsonSlack struct {
+	Name  string  `json:"name"`
+	Pos   string  `json:"pos,omitempty"`
+	Value float64 `json:"value"`
+	Limit float64 `json:"limit"`
+	Slack float64 `json:"slack"`
+}
+
+// checkSolCmd implements "lpvet check-sol", which reports the constraints
+// and bounds of a model that a solution violates.
+func checkSolCmd(args []string) {
+	fs := flag.NewFlagSet("check-sol", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	feasTol := fs.Float64("feastol", lp.DefaultFeasTol, "allow constraints and bounds to be violated by up to `t`")
+	intTol := fs.Float64("inttol", lp.DefaultIntTol, "allow integer variables to be up to `t` from an integer")
+	slack := fs.Int("slack", 0, "also list the `n` constraints with the least slack")
+	if cfg.feasTol > 0 {
+		fs.Set("feastol", strconv.FormatFloat(cfg.feasTol, 'g', -1, 64))
+	}
+	if cfg.intTol > 0 {
+		fs.Set("inttol", strconv.FormatFloat(cfg.intTol, 'g', -1, 64))
+	}
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet [flags] check-sol [-feastol t] [-inttol t] [-slack n] [-format=text|json] model sol.sol")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	rest := parseInterspersed(fs, args)
+	if len(rest) != 2 {
+		fs.Usage()
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("unknown output format %q", *format)
+	}
+	if *feasTol <= 0 || *intTol <= 0 {
+		log.Fatal("tolerances must be positive")
+	}
+	m := mustReadModel(rest[0])
+	f, err := os.Open(rest[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	sol, err := lp.ReadSolution(rest[1], f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	chk := lp.CheckSolution(m, sol, lp.CheckOptions{FeasTol: *feasTol, IntTol: *intTol})
+	// The tightest constraints first, and violated ones before those
+	// that merely hit their limits.
+	sort.SliceStable(chk.Slacks, func(i, j int) bool { return chk.Slacks[i].Slack < chk.Slacks[j].Slack })
+	if len(chk.Slacks) > *slack {
+		chk.Slacks = chk.Slacks[:*slack]
+	}
+
+	if *format == "json" {
+		out := jsonSolutionCheck{Violations: []jsonViolation{}, Missing: chk.Missing, Unknown: chk.Unknown}
+		if chk.HasObjective {
+			out.Objective = &chk.Objective
+		}
+		for _, sl := range chk.Slacks {
+			js := jsonSlack{sl.Name, "", sl.Value, sl.Limit, sl.Slack}
+			if sl.Pos.Line > 0 {
+				js.Pos = sl.Pos.String()
+			}
+			out.Slacks = append(out.Slacks, js)
+		}
+		for _, v := range chk.Violations {
+			jv := jsonViolation{v.Kind, v.Name, "", v.Value, v.Limit, v.Amount, v.Detail}
+			if v.Pos.Line > 0 {
+				jv.Pos = v.Pos.String()
+			}
+			out.Violations = append(out.Violations, jv)
+		}
+		data, err := json.MarshalIndent(out, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+	} else {
+		worst := -1
+		var fractional []lp.Violation
+		for i, v := range chk.Violations {
+			if worst < 0 || v.Amount > chk.Violations[worst].Amount {
+				worst = i
+			}
+			if v.Kind == "integrality" {
+				fractional = append(fractional, v)
+				continue
+			}
+			fmt.Printf("%s: %s %s violated by %.3g: %s\n", posText(v.Pos), v.Kind, v.Name, v.Amount, v.Detail)
+		}
+		// Heuristic solutions can leave many variables slightly fractional,
+		// so only the worst are listed.
+		sort.SliceStable(fractional, func(i, j int) bool { return fractional[i].Amount > fractional[j].Amount })
+		for i, v := range fractional {
+			if i == maxFractional {
+				fmt.Printf("and %s\n", plural(len(fractional)-i, "more fractional variable"))
+				break
+			}
+			fmt.Printf("%s: integrality of %s violated by %.3g: %s\n", posText(v.Pos), v.Name, v.Amount, v.Detail)
+		}
+		if len(chk.Missing) > 0 {
+			fmt.Printf("%s without a value, taken to be 0: %s\n", plural(len(chk.Missing), "variable"), nameList(chk.Missing))
+		}
+		if len(chk.Unknown) > 0 {
+			fmt.Printf("the solution has values for %s not in the model: %s\n", plural(len(chk.Unknown), "variable"), nameList(chk.Unknown))
+		}
+		if len(chk.Slacks) > 0 {
+			fmt.Println("constraints with the least slack:")
+		}
+		for _, sl := range chk.Slacks {
+			note := ""
+			switch {
+			case sl.Slack < -*feasTol:
+				note = " (violated)"
+			case sl.Slack < 0:
+				note = " (violated within tolerance)"
+			case sl.Slack <= *feasTol:
+				note = " (at its limit)"
+			}
+			fmt.Printf("%s: constraint %s: slack %.3g, %g against limit %g%s\n", posText(sl.Pos), sl.Name, sl.Slack, sl.Value, sl.Limit, note)
+		}
+		if chk.HasObjective {
+			fmt.Printf("objective value: %g\n", chk.Objective)
+		}
+		if worst < 0 {
+			fmt.Println("the solution is feasible")
+		} else {
+			v := chk.Violations[worst]
+			fmt.Printf("%s, the largest by %.3g (%s %s)\n", plural(len(chk.Violations), "violation"), v.Amount, v.Kind, v.Name)
+		}
+	}
+	if len(chk.Violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// maxFractional is the number of integer variables with fractional values
+// that check-sol lists.
+const maxFractional = 10
+
+// nameList joins names, eliding all but the first few.
+func nameList(names []string) string {
+	const max = 10
+	if len(names) <= max {
+		return strings.Join(names, ", ")
+	}
+	return strings.Join(names[:max], ", ") + fmt.Sprintf(", and %d more", len(names)-max)
+}
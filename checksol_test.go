@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNameList(t *testing.T) {
+	var many []string
+	for i := 1; i <= 12; i++ {
+		many = append(many, fmt.Sprintf("x%d", i))
+	}
+	for _, tt := range []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"x"}, "x"},
+		{[]string{"x", "y"}, "x, y"},
+		{many[:10], "x1, x2, x3, x4, x5, x6, x7, x8, x9, x10"},
+		{many, "x1, x2, x3, x4, x5, x6, x7, x8, x9, x10, and 2 more"},
+	} {
+		if got := nameList(tt.names); got != tt.want {
+			t.Errorf("nameList(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
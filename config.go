@@ -0,0 +1,261 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// configName is the name of the configuration file,
+// which is looked up in the working directory and its parents.
+const configName = ".lpvet.toml"
+
+// A config holds the settings of a configuration file
+// that have no command-line flag.
+type config struct {
+	dir          string   // directory of the file; ignore patterns are relative to it
+	enable       []string // checks to run despite disable
+	disable      []string // checks not to run; may include "all"
+	severity     map[string]lp.Severity
+	maxVarLen    int    // 0 if unset
+	maxLineLen   int    // 0 if unset
+	truncateLen  int    // 0 if unset
+	nameChars    string // allowed in names in addition to the dialect's
+	maxCoefRange float64
+	feasTol      float64 // defaults of the check-sol flags
+	intTol       float64
+	ignore       []string // patterns of files to skip
+}
+
+// findConfig returns the path of the configuration file
+// closest to dir, or "" if there is none.
+func findConfig(dir string) string {
+	for {
+		p := filepath.Join(dir, configName)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads the configuration file at p.
+//
+// Top-level settings named after flags, such as solver or warn,
+// set those flags unless they were given on the command line.
+// The remaining settings are returned.
+func loadConfig(p string) (*config, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tab, err := parseTOML(p, f)
+	if err != nil {
+		return nil, err
+	}
+
+	onCmdLine := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { onCmdLine[f.Name] = true })
+
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	c := &config{dir: filepath.Dir(abs)}
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%s: %s", p, fmt.Sprintf(format, args...))
+	}
+	stringList := func(key string, v interface{}) ([]string, error) {
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, errorf("%s must be an array of strings", key)
+		}
+		var ss []string
+		for _, e := range arr {
+			s, ok := e.(string)
+			if !ok {
+				return nil, errorf("%s must be an array of strings", key)
+			}
+			ss = append(ss, s)
+		}
+		return ss, nil
+	}
+
+	// number returns v if it is a number, or else -1.
+	number := func(v interface{}) float64 {
+		switch n := v.(type) {
+		case int64:
+			return float64(n)
+		case float64:
+			return n
+		}
+		return -1
+	}
+
+	keys := make([]string, 0, len(tab))
+	for k := range tab {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := tab[k]
+		switch k {
+		case "enable":
+			c.enable, err = stringList(k, v)
+		case "disable":
+			c.disable, err = stringList(k, v)
+		case "ignore":
+			c.ignore, err = stringList(k, v)
+		case "max-var-len", "max-line-len", "truncate-len":
+			n, ok := v.(int64)
+			if !ok || n < 0 {
+				return nil, errorf("%s must be a non-negative integer", k)
+			}
+			switch k {
+			case "max-var-len":
+				c.maxVarLen = int(n)
+			case "max-line-len":
+				c.maxLineLen = int(n)
+			default:
+				c.truncateLen = int(n)
+			}
+		case "name-chars":
+			s, ok := v.(string)
+			if !ok {
+				return nil, errorf("name-chars must be a string")
+			}
+			if strings.ContainsAny(s, " \t:+-<>=") {
+				return nil, errorf("name-chars cannot contain spaces or any of :+-<>=, which separate names")
+			}
+			c.nameChars = s
+		case "max-coef-range":
+			c.maxCoefRange = number(v)
+			if c.maxCoefRange < 1 {
+				return nil, errorf("max-coef-range must be a number of at least 1")
+			}
+		case "feastol", "inttol":
+			tol := number(v)
+			if tol <= 0 {
+				return nil, errorf("%s must be a positive number", k)
+			}
+			if k == "feastol" {
+				c.feasTol = tol
+			} else {
+				c.intTol = tol
+			}
+		case "severity":
+			sevs, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, errorf("severity must be a table")
+			}
+			c.severity = make(map[string]lp.Severity)
+			for name, sv := range sevs {
+				check, err := lp.LookupCheck(name)
+				if err != nil {
+					return nil, errorf("%v", err)
+				}
+				s, _ := sv.(string)
+				sev, err := parseSeverity(s)
+				if err != nil {
+					return nil, errorf("%s: %v", name, err)
+				}
+				c.severity[check.ID] = sev
+			}
+		default:
+			fl := flag.Lookup(k)
+			if fl == nil || k == "config" {
+				return nil, errorf("unknown setting %s", k)
+			}
+			if onCmdLine[k] {
+				continue
+			}
+			var s string
+			switch v := v.(type) {
+			case string:
+				s = v
+			case bool:
+				s = strconv.FormatBool(v)
+			case int64:
+				s = strconv.FormatInt(v, 10)
+			default:
+				return nil, errorf("invalid value for %s", k)
+			}
+			// flag.Set also makes it count as explicitly set,
+			// so a configured dialect is not overridden by the solver's.
+			if err := flag.Set(k, s); err != nil {
+				return nil, errorf("%s: %v", k, err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func parseSeverity(s string) (lp.Severity, error) {
+	switch s {
+	case "error":
+		return lp.Error, nil
+	case "warning":
+		return lp.Warning, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q (want error or warning)", s)
+}
+
+// checkSet returns the IDs of the named checks.
+// The name "all" stands for all checks.
+func checkSet(names []string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, name := range names {
+		if name == "all" {
+			for _, c := range lp.Checks() {
+				set[c.ID] = true
+			}
+			continue
+		}
+		c, err := lp.LookupCheck(name)
+		if err != nil {
+			return nil, err
+		}
+		set[c.ID] = true
+	}
+	return set, nil
+}
+
+// ignored reports whether file matches one of the ignore patterns.
+// Patterns without a slash match the file's base name;
+// others match its path relative to the configuration file,
+// with "**" matching any number of directories.
+func (c *config) ignored(file string) bool {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(c.dir, abs)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pat := range c.ignore {
+		name := rel
+		if !strings.Contains(pat, "/") {
+			name = filepath.Base(abs)
+		}
+		if globMatch(pat, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// convertCmd implements "lpvet convert", which writes models in another format.
+func convertCmd(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := fs.String("to", "", "format to write: lp, mps (fixed-format), or freemps")
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet convert -to lp|mps|freemps [-o out] in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 || *to == "" {
+		fs.Usage()
+	}
+	format, err := lp.ParseFormatName(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if format != lp.FormatLP && format != lp.FormatMPS && format != lp.FormatFreeMPS {
+		log.Fatalf("cannot convert to %v", format)
+	}
+
+	in := ins[0]
+	m := mustReadModel(in)
+	name := "model"
+	if in != "-" {
+		name = strings.TrimSuffix(filepath.Base(in), filepath.Ext(in))
+	}
+	var b bytes.Buffer
+	if format == lp.FormatLP {
+		err = lp.WriteLP(&b, m)
+	} else {
+		err = lp.WriteMPS(&b, m, name, format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*out, b.Bytes())
+}
+
+// parseInterspersed parses args with fs and returns the other arguments.
+// Unlike fs.Parse, it also accepts flags after them,
+// as in "convert -to mps in.lp -o out.mps".
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	fs.Parse(args)
+	var rest []string
+	for fs.NArg() > 0 {
+		rest = append(rest, fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+	return rest
+}
+
+// mustReadModel parses the model at p, or stdin if p is "-",
+// and exits if it cannot, after printing any syntax errors.
+func mustReadModel(p string) *lp.LP {
+	m, err := readModel(p)
+	if errs, ok := err.(lp.ErrorList); ok {
+		for _, e := range errs {
+			log.Print(e)
+		}
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return m
+}
+
+// writeOutput writes b to the file out, or stdout if out is empty.
+func writeOutput(out string, b []byte) {
+	if out == "" {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := os.WriteFile(out, b, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readModel parses the model at p, or stdin if p is "-".
+func readModel(p string) (*lp.LP, error) {
+	name := p
+	var r io.Reader = os.Stdin
+	if p == "-" {
+		name = *cmdStdinName
+	} else {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return parseModel(name, r)
+}
+
+// parseModel parses the model called name from r,
+// ignoring the errors that only matter to other readers of the file.
+func parseModel(name string, r io.Reader) (*lp.LP, error) {
+	m, err := lp.ParseFormatLimits(name, r, formatOf(name), limits())
+	if errs, ok := err.(lp.ErrorList); ok {
+		// Long lines are only a problem for readers of LP files.
+		var rest lp.ErrorList
+		for _, e := range errs {
+			if e.Check != "LP010" {
+				rest = append(rest, e)
+			}
+		}
+		if err = rest; len(rest) == 0 {
+			err = nil
+		}
+	}
+	return m, err
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// exportCmd implements "lpvet export", which writes the data of models
+// in formats meant for other tools rather than solvers.
+func exportCmd(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "format to write: json, mm (Matrix Market), osil, or proto")
+	out := fs.String("o", "", "write to this `path` instead of stdout; for mm, the prefix of the files (default: the input's name without extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet export -format json|mm|osil|proto [-o path] in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 || *format == "" {
+		fs.Usage()
+	}
+	in := ins[0]
+	m := mustReadModel(in)
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(jsonModelOf(m), "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeOutput(*out, append(data, '\n'))
+	case "mm":
+		prefix := *out
+		if prefix == "" {
+			prefix = "model"
+			if in != "-" {
+				prefix = strings.TrimSuffix(in, filepath.Ext(in))
+			}
+		}
+		exportMatrixMarket(prefix, m)
+	case "osil":
+		name := "model"
+		if in != "-" {
+			name = strings.TrimSuffix(filepath.Base(in), filepath.Ext(in))
+		}
+		var b bytes.Buffer
+		if err := lp.WriteOSiL(&b, m, name); err != nil {
+			log.Fatal(err)
+		}
+		writeOutput(*out, b.Bytes())
+	case "proto":
+		var b bytes.Buffer
+		if err := lp.WriteProto(&b, m); err != nil {
+			log.Fatal(err)
+		}
+		writeOutput(*out, b.Bytes())
+	default:
+		log.Fatalf("unknown export format %q", *format)
+	}
+}
+
+// exportMatrixMarket writes the constraint matrix of m and its vectors
+// to Matrix Market files starting with prefix,
+// and the names of the rows and columns to text files, one per line.
+func exportMatrixMarket(prefix string, m *lp.LP) {
+	if st := lp.ComputeStats(m); st.QuadraticConstraints > 0 || st.QuadraticObjectiveTerms > 0 {
+		log.Print("warning: quadratic terms are not exported")
+	}
+	sf := lp.ToStandardForm(m)
+	nrows, ncols := len(sf.Rows), len(sf.Cols)
+
+	var a bytes.Buffer
+	a.WriteString("%%MatrixMarket matrix coordinate real general\n")
+	a.WriteString("% constraint matrix: rows are constraints, columns are variables\n")
+	fmt.Fprintf(&a, "%d %d %d\n", nrows, ncols, len(sf.Entries))
+	for _, e := range sf.Entries {
+		fmt.Fprintf(&a, "%d %d %s\n", e.Row+1, e.Col+1, mmNum(e.Value))
+	}
+	writeOutput(prefix+".A.mtx", a.Bytes())
+
+	writeOutput(prefix+".b.mtx", mmArray("constraint limits: lower in column 1, upper in column 2", sf.RowLo, sf.RowHi))
+	c := mmArray(fmt.Sprintf("objective coefficients (%s, constant %s)", sf.Sense, mmNum(sf.ObjConstant)), sf.Obj)
+	writeOutput(prefix+".c.mtx", c)
+	writeOutput(prefix+".bounds.mtx", mmArray("variable bounds: lower in column 1, upper in column 2", sf.ColLo, sf.ColHi))
+
+	var ints bytes.Buffer
+	ints.WriteString("%%MatrixMarket matrix array integer general\n")
+	ints.WriteString("% integrality: 1 for integer variables, 0 for continuous ones\n")
+	fmt.Fprintf(&ints, "%d 1\n", ncols)
+	for _, isInt := range sf.Integer {
+		if isInt {
+			ints.WriteString("1\n")
+		} else {
+			ints.WriteString("0\n")
+		}
+	}
+	writeOutput(prefix+".integrality.mtx", ints.Bytes())
+
+	writeOutput(prefix+".rows.txt", nameLines(sf.Rows))
+	writeOutput(prefix+".cols.txt", nameLines(sf.Cols))
+}
+
+func nameLines(names []string) []byte {
+	var b bytes.Buffer
+	for _, name := range names {
+		b.WriteString(name + "\n")
+	}
+	return b.Bytes()
+}
+
+// mmArray returns a dense Matrix Market matrix with the given columns,
+// which have the same length, described by comment.
+func mmArray(comment string, cols ...[]float64) []byte {
+	var b bytes.Buffer
+	b.WriteString("%%MatrixMarket matrix array real general\n")
+	fmt.Fprintf(&b, "%% %s\n", comment)
+	fmt.Fprintf(&b, "%d %d\n", len(cols[0]), len(cols))
+	// Entries are listed in column-major order.
+	for _, col := range cols {
+		for _, v := range col {
+			fmt.Fprintf(&b, "%s\n", mmNum(v))
+		}
+	}
+	return b.Bytes()
+}
+
+// mmNum formats v, writing infinities as inf and -inf,
+// which NumPy, SciPy, and Julia read as such.
+func mmNum(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// A jsonModel is the representation of a model written by
+// "lpvet export -format json". Its schema is described in the README;
+// fields are only ever added, and Version changes if any is removed
+// or changes meaning.
+//
+// Limits are null where they are infinite, since JSON has no infinity.
+type jsonModel struct {
+	Version     int                 `json:"version"`
+	Objectives  []jsonObjective     `json:"objectives"`
+	Constraints []jsonConstraint    `json:"constraints"`
+	Variables   []jsonVariable      `json:"variables"`
+	SOS         []jsonSOS           `json:"sos"`
+	GenCons     []jsonGenConstraint `json:"generalConstraints"`
+	PWLObjs     []jsonPWLObj        `json:"pwlObjectives"`
+}
+
+const jsonModelVersion = 1
+
+type jsonPos struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+type jsonTerm struct {
+	Var  string  `json:"var"`
+	Coef float64 `json:"coef"`
+}
+
+type jsonQuadTerm struct {
+	Var1 string  `json:"var1"`
+	Var2 string  `json:"var2"`
+	Coef float64 `json:"coef"`
+}
+
+type jsonObjective struct {
+	Name      string             `json:"name,omitempty"`
+	Sense     string             `json:"sense"` // minimize or maximize
+	Terms     []jsonTerm         `json:"terms"`
+	QuadTerms []jsonQuadTerm     `json:"quadTerms,omitempty"` // with the "/ 2" applied
+	Constant  float64            `json:"constant"`
+	Params    map[string]float64 `json:"params,omitempty"` // of Gurobi multi-objectives
+	Pos       jsonPos            `json:"pos"`
+}
+
+type jsonConstraint struct {
+	Name      string         `json:"name"` // R1, R2, ... by position if unnamed
+	Terms     []jsonTerm     `json:"terms"`
+	QuadTerms []jsonQuadTerm `json:"quadTerms,omitempty"`
+	Lo        *float64       `json:"lo"`
+	Hi        *float64       `json:"hi"`
+	Indicator *jsonIndicator `json:"indicator,omitempty"`
+	Pos       jsonPos        `json:"pos"`
+}
+
+type jsonIndicator struct {
+	Var   string `json:"var"`
+	Value int    `json:"value"`
+	Equiv bool   `json:"equiv,omitempty"`
+}
+
+type jsonVariable struct {
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+	Lo   *float64 `json:"lo"`
+	Hi   *float64 `json:"hi"`
+	Pos  jsonPos  `json:"pos"` // of its first appearance
+}
+
+type jsonSOS struct {
+	Name    string          `json:"name,omitempty"`
+	Type    int             `json:"type"`
+	Members []jsonSOSMember `json:"members"`
+	Pos     jsonPos         `json:"pos"`
+}
+
+type jsonSOSMember struct {
+	Var    string  `json:"var"`
+	Weight float64 `json:"weight"`
+}
+
+type jsonGenConstraint struct {
+	Name      string    `json:"name,omitempty"`
+	Result    string    `json:"result"`
+	Func      string    `json:"func"`
+	Args      []string  `json:"args"`
+	Constants []float64 `json:"constants,omitempty"`
+	Pos       jsonPos   `json:"pos"`
+}
+
+type jsonPWLObj struct {
+	Var    string       `json:"var"`
+	Points [][2]float64 `json:"points"`
+	Pos    jsonPos      `json:"pos"`
+}
+
+func jsonModelOf(m *lp.LP) jsonModel {
+	jm := jsonModel{
+		Version:     jsonModelVersion,
+		Objectives:  []jsonObjective{},
+		Constraints: []jsonConstraint{},
+		Variables:   []jsonVariable{},
+		SOS:         []jsonSOS{},
+		GenCons:     []jsonGenConstraint{},
+		PWLObjs:     []jsonPWLObj{},
+	}
+	pos := func(p lp.Pos) jsonPos { return jsonPos{p.File, int(p.Line), int(p.Col)} }
+	limit := func(v float64) *float64 {
+		if math.IsInf(v, 0) {
+			return nil
+		}
+		return &v
+	}
+	terms := func(e lp.Expr) ([]jsonTerm, []jsonQuadTerm) {
+		ts := []jsonTerm{}
+		for _, t := range e.Terms {
+			ts = append(ts, jsonTerm{t.Var.Value, t.Coef})
+		}
+		var qs []jsonQuadTerm
+		for _, q := range e.Quad {
+			qs = append(qs, jsonQuadTerm{q.Var1.Value, q.Var2.Value, q.Coef})
+		}
+		return ts, qs
+	}
+
+	objs := m.MultiObj
+	if len(objs) == 0 && m.Obj != nil {
+		objs = []*lp.Objective{m.Obj}
+	}
+	for _, o := range objs {
+		e := o.Expr.Combined()
+		jo := jsonObjective{Name: o.Name, Sense: o.Sense.String(), Constant: e.Constant, Params: o.Params, Pos: pos(o.Pos)}
+		jo.Terms, jo.QuadTerms = terms(e)
+		jm.Objectives = append(jm.Objectives, jo)
+	}
+
+	names := lp.ConstraintMatrix(m).Rows
+	for i, c := range m.Rows {
+		e, lo, hi := c.Limits()
+		jc := jsonConstraint{Name: names[i], Lo: limit(lo), Hi: limit(hi), Pos: pos(c.Pos)}
+		jc.Terms, jc.QuadTerms = terms(e)
+		if ind := c.Indicator; ind != nil {
+			jc.Indicator = &jsonIndicator{ind.Var.Value, ind.Value, ind.Equiv}
+		}
+		jm.Constraints = append(jm.Constraints, jc)
+	}
+
+	for _, v := range lp.Variables(m) {
+		jm.Variables = append(jm.Variables, jsonVariable{v.Name, v.Type, limit(v.Lo), limit(v.Hi), pos(v.Pos)})
+	}
+
+	for _, s := range m.SOS {
+		js := jsonSOS{Name: s.Name, Type: s.Type, Members: []jsonSOSMember{}, Pos: pos(s.Pos)}
+		for _, mem := range s.Members {
+			js.Members = append(js.Members, jsonSOSMember{mem.Var.Value, mem.Weight})
+		}
+		jm.SOS = append(jm.SOS, js)
+	}
+	for _, g := range m.GenCons {
+		jg := jsonGenConstraint{Name: g.Name, Result: g.Result.Value, Func: g.Func, Args: []string{}, Constants: g.Constants, Pos: pos(g.Pos)}
+		for _, a := range g.Args {
+			jg.Args = append(jg.Args, a.Value)
+		}
+		jm.GenCons = append(jm.GenCons, jg)
+	}
+	for _, pw := range m.PWLObjs {
+		jm.PWLObjs = append(jm.PWLObjs, jsonPWLObj{pw.Var.Value, pw.Points, pos(pw.Pos)})
+	}
+	return jm
+}
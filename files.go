@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// isModelFile reports whether name looks like a model that lpvet reads.
+func isModelFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".lp", ".mps":
+		return true
+	}
+	return false
+}
+
+// expandArgs turns the command-line arguments into a list of files.
+//
+// An argument ending in "/..." stands for all models under that directory,
+// as does a directory if recursive is set.
+// Arguments with glob metacharacters are expanded, where "**"
+// matches any number of directories.
+// It also reports whether any argument was expanded.
+func expandArgs(args []string, recursive bool) (files []string, expanded bool, err error) {
+	for _, arg := range args {
+		switch {
+		case arg == "..." || strings.HasSuffix(arg, "/..."):
+			found, err := walkModels(filepath.Clean(strings.TrimSuffix(arg, "...")), nil)
+			if err != nil {
+				return nil, false, err
+			}
+			files = append(files, found...)
+			expanded = true
+		case strings.ContainsAny(arg, "*?["):
+			found, err := expandGlob(arg)
+			if err != nil {
+				return nil, false, err
+			}
+			if len(found) == 0 {
+				return nil, false, fmt.Errorf("%s: no files match", arg)
+			}
+			files = append(files, found...)
+			expanded = true
+		default:
+			fi, err := os.Stat(arg)
+			if err == nil && fi.IsDir() {
+				if !recursive {
+					return nil, false, fmt.Errorf("%s is a directory (use -r or %s/...)", arg, arg)
+				}
+				found, err := walkModels(arg, nil)
+				if err != nil {
+					return nil, false, err
+				}
+				files = append(files, found...)
+				expanded = true
+				continue
+			}
+			// Missing files are reported when they are read.
+			files = append(files, arg)
+		}
+	}
+	return files, expanded, nil
+}
+
+// readList returns the non-blank lines of the file at p,
+// or of stdin if p is "-", with surrounding spaces removed.
+func readList(p string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if p != "-" {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var list []string
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			list = append(list, line)
+		}
+	}
+	return list, s.Err()
+}
+
+// expandResponseFiles replaces each argument of the form @file
+// with the lines of file, one argument per line.
+// Response files may not refer to others.
+func expandResponseFiles(args []string) ([]string, error) {
+	var out []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(out, args[i:]...), nil
+		}
+		if !strings.HasPrefix(arg, "@") || arg == "@" {
+			out = append(out, arg)
+			continue
+		}
+		list, err := readList(arg[1:])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, list...)
+	}
+	return out, nil
+}
+
+// walkModels returns the model files under dir whose paths
+// satisfy match, or all of them if match is nil.
+// Hidden directories, such as .git, are skipped.
+func walkModels(dir string, match func(string) bool) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if p != dir && strings.HasPrefix(fi.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match != nil {
+			if match(p) {
+				files = append(files, p)
+			}
+		} else if isModelFile(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// expandGlob returns the files matching pattern.
+func expandGlob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	if !strings.Contains(pattern, "**") {
+		files, err := filepath.Glob(filepath.FromSlash(pattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+	// Walk from the longest prefix without metacharacters.
+	segs := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segs)-1 && !strings.ContainsAny(segs[i], "*?[") {
+		i++
+	}
+	root := strings.Join(segs[:i], "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+	return walkModels(filepath.FromSlash(root), func(p string) bool {
+		return globMatch(pattern, filepath.ToSlash(p))
+	})
+}
+
+// globMatch reports whether name matches pattern, where both use slashes.
+// Each segment of pattern matches one segment of name as in filepath.Match,
+// except "**", which matches any number of segments.
+func globMatch(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	name = strings.TrimPrefix(name, "./")
+	return matchSegs(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegs(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegs(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// dirSummaries counts the files and problems in each directory.
+type dirSummaries map[string]*dirSummary
+
+type dirSummary struct {
+	files, errors, warnings int
+}
+
+func (s dirSummaries) add(file string, diags []lp.Diagnostic) {
+	dir := filepath.Dir(file)
+	sum := s[dir]
+	if sum == nil {
+		sum = new(dirSummary)
+		s[dir] = sum
+	}
+	sum.files++
+	for _, d := range diags {
+		if d.Severity == lp.Error {
+			sum.errors++
+		} else {
+			sum.warnings++
+		}
+	}
+}
+
+// print logs a line for each directory, in order.
+func (s dirSummaries) print() {
+	dirs := make([]string, 0, len(s))
+	for dir := range s {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		sum := s[dir]
+		log.Printf("%s: %s, %s, %s", dir,
+			plural(sum.files, "file"), plural(sum.errors, "error"), plural(sum.warnings, "warning"))
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A fix is a change to the text of a model that resolves a problem.
+type fix struct {
+	title string
+	edits []edit
+}
+
+// An edit replaces the bytes of a line between pos.Col and pos.EndCol,
+// which are equal for insertions.
+type edit struct {
+	pos  lp.Pos
+	text string
+}
+
+// fixesFor returns the fixes for the problem d in a model
+// in format f with the given lines, parsed as m.
+func fixesFor(d lp.Diagnostic, lines []string, m *lp.LP, f lp.Format) []fix {
+	if d.Pos.Line < 1 || int(d.Pos.Line) > len(lines) {
+		return nil
+	}
+	line := lines[d.Pos.Line-1]
+	switch d.Check {
+	case "LP012": // invalid-name
+		return fixInvalidName(d.Pos, line, m, f)
+	case "LP001": // undeclared-var
+		return fixUndeclared(d.Symbol, lines, m, f)
+	case "LP010": // line-too-long
+		return fixLongLine(d.Pos, line, f)
+	case "LP009": // name-too-long
+		return fixLongName(d.Pos, line, m, f)
+	case "LP021": // exponent-name
+		return fixExponentName(d.Pos, line, m, f)
+	case "LP032": // trivial-constraint
+		return fixTrivialRow(d, line, m, f)
+	case "LP013": // missing-end
+		return fixMissingEnd(lines, f)
+	case "LP014": // misspelled-section
+		if kw, ok := lp.SuggestHeader(line[d.Pos.Col-1 : d.Pos.EndCol-1]); ok {
+			return []fix{{fmt.Sprintf("Change to %s", kw), []edit{{d.Pos, kw}}}}
+		}
+	}
+	return nil
+}
+
+// used reports whether name is a variable or row in m.
+func used(m *lp.LP, name string) bool {
+	if m == nil {
+		return false
+	}
+	sym := lp.Symbol{Value: name}
+	for _, sec := range append(append(useSections(m), declSections(m)...), &m.RowNames) {
+		if sec.HasSym(sym) {
+			return true
+		}
+	}
+	return false
+}
+
+// fixInvalidName replaces the characters of the name at pos,
+// and its other occurrences in m, that f does not allow with underscores,
+// unless that makes it the same as another name in m.
+func fixInvalidName(pos lp.Pos, line string, m *lp.LP, f lp.Format) []fix {
+	start, end := int(pos.Col)-1, int(pos.EndCol)-1
+	if start < 0 || end > len(line) || start >= end {
+		return nil
+	}
+	old := line[start:end]
+	name := strings.Map(func(r rune) rune {
+		// Check the character after a letter, since some can't start names.
+		if lp.CheckName(f, "a"+string(r)) != nil && !strings.ContainsRune(limits().NameChars, r) {
+			return '_'
+		}
+		return r
+	}, old)
+	// CheckName does not know the characters the configuration allows.
+	if name == old || limits().NameChars == "" && lp.CheckName(f, name) != nil || used(m, name) {
+		return nil
+	}
+	return []fix{{fmt.Sprintf("Rename %s to %s", old, name), renameAll(pos, m, old, name)}}
+}
+
+// fixUndeclared declares name as continuous in the CONTINUOUS section,
+// which is added before End if there is none.
+func fixUndeclared(name string, lines []string, m *lp.LP, f lp.Format) []fix {
+	if name == "" || (f != lp.FormatLP && f != lp.FormatGLPK) {
+		return nil
+	}
+	// insertAt returns an edit that inserts text as a line before line n,
+	// or after the last line if n is past it.
+	insertAt := func(n int32, text string) edit {
+		if int(n) > len(lines) {
+			last := lines[len(lines)-1]
+			return edit{lp.Pos{Line: int32(len(lines)), Col: int32(len(last)) + 1, EndCol: int32(len(last)) + 1}, "\n" + text}
+		}
+		return edit{lp.Pos{Line: n, Col: 1, EndCol: 1}, text + "\n"}
+	}
+	title := fmt.Sprintf("Declare %s as continuous", name)
+	for i, h := range m.Headers {
+		if !strings.EqualFold(h.Value, "CONTINUOUS") {
+			continue
+		}
+		next := int32(len(lines)) + 1
+		if i+1 < len(m.Headers) {
+			next = m.Headers[i+1].Pos.Line
+		}
+		decl := " " + name
+		if strings.HasPrefix(strings.TrimSpace(lines[h.Pos.Line-1]), "\\lpvet:") {
+			decl = "\\lpvet: " + name
+		}
+		// Insert after the last line of the section that is not blank.
+		for next-1 > h.Pos.Line && strings.TrimSpace(lines[next-2]) == "" {
+			next--
+		}
+		return []fix{{title, []edit{insertAt(next, decl)}}}
+	}
+	at := int32(len(lines)) + 1
+	for _, h := range m.Headers {
+		if strings.EqualFold(h.Value, "END") {
+			at = h.Pos.Line
+		}
+	}
+	return []fix{{title, []edit{insertAt(at, "\\lpvet:CONTINUOUS\n\\lpvet: "+name)}}}
+}
+
+// fixLongLine breaks the line at pos into lines that are short enough,
+// at term boundaries: before the signs between terms and before relations.
+// Continuation lines then start with an operator, so none can be
+// read as a section header or a constraint name.
+func fixLongLine(pos lp.Pos, line string, f lp.Format) []fix {
+	if f == lp.FormatMPS || f == lp.FormatFreeMPS {
+		return nil // MPS has no continuation lines
+	}
+	var breaks []int // byte offsets that a continuation line can start at
+	for _, t := range lp.Tokens([]string{line}) {
+		if at := int(t.Pos.Col) - 1; t.Kind == lp.TokenOperator && at > 0 && strings.IndexByte("+-<>=", line[at]) >= 0 {
+			breaks = append(breaks, at)
+		}
+	}
+	max := limits().MaxLineLen
+	if max <= 0 {
+		return nil
+	}
+	var edits []edit
+	start := 0 // of the current line
+	indent := 0
+	for len(line)-start+indent > max {
+		brk := -1
+		for _, at := range breaks {
+			if at > start && at-start+indent <= max {
+				brk = at
+			}
+		}
+		if brk < 0 {
+			// No term boundary is close enough, or only a comment is left.
+			return nil
+		}
+		// Replace the space before the break, if any.
+		sp := brk
+		for sp > start && line[sp-1] == ' ' {
+			sp--
+		}
+		p := pos
+		p.Col, p.EndCol = int32(sp)+1, int32(brk)+1
+		edits = append(edits, edit{p, "\n "})
+		start, indent = brk, 1
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return []fix{{title: "Break the line between terms", edits: edits}}
+}
+
+// nameLimit returns the longest name allowed by the configuration.
+func nameLimit() int {
+	if n := limits().MaxVarLen; n > 0 {
+		return n
+	}
+	return lp.MaxVarLen
+}
+
+// fixLongName shortens the name at pos, and its other occurrences in m,
+// keeping a hash of the full name so that names with the same start
+// stay distinct.
+func fixLongName(pos lp.Pos, line string, m *lp.LP, f lp.Format) []fix {
+	start, end := int(pos.Col)-1, int(pos.EndCol)-1
+	limit := nameLimit()
+	if start < 0 || end > len(line) || end-start <= limit {
+		return nil
+	}
+	old := line[start:end]
+	name := old[:limit]
+	if limit >= 16 {
+		// Other occurrences may already have been shortened to the same name.
+		h := fnv.New32a()
+		h.Write([]byte(old))
+		name = fmt.Sprintf("%s_%08x", old[:limit-9], h.Sum32())
+	} else if used(m, name) {
+		return nil
+	}
+	if lp.CheckName(f, name) != nil {
+		return nil
+	}
+	return []fix{{fmt.Sprintf("Shorten %.20s... to %s", old, name), renameAll(pos, m, old, name)}}
+}
+
+// renameAll returns the edits that rename the name at pos to name,
+// along with the other occurrences of old in m.
+func renameAll(pos lp.Pos, m *lp.LP, old, name string) []edit {
+	edits := []edit{{pos, name}}
+	if m != nil {
+		for _, sec := range append(append(useSections(m), declSections(m)...), &m.RowNames) {
+			for _, sym := range sec.Syms() {
+				if sym.Value == old && sym.Pos.Col > 0 && sym.Pos != pos {
+					edits = append(edits, edit{sym.Pos, name})
+				}
+			}
+		}
+	}
+	return edits
+}
+
+// fixExponentName prefixes the name at pos, and its other occurrences in m,
+// with an underscore, so that it cannot be read as an exponent.
+// Names in MPS files are left alone, since longer names would move
+// the fields of fixed-format files.
+func fixExponentName(pos lp.Pos, line string, m *lp.LP, f lp.Format) []fix {
+	start, end := int(pos.Col)-1, int(pos.EndCol)-1
+	if f == lp.FormatMPS || f == lp.FormatFreeMPS || start < 0 || end > len(line) || start >= end {
+		return nil
+	}
+	old := line[start:end]
+	name := "_" + old
+	if used(m, name) || lp.CheckName(f, name) != nil {
+		return nil
+	}
+	return []fix{{fmt.Sprintf("Rename %s to %s", old, name), renameAll(pos, m, old, name)}}
+}
+
+// fixTrivialRow removes the constraint that d reports as comparing
+// two constants that always hold, such as "c1: 0 <= 5".
+// Such a constraint ends its line, since a number before a later line
+// is read as its right side; the line is blanked if nothing precedes it.
+func fixTrivialRow(d lp.Diagnostic, line string, m *lp.LP, f lp.Format) []fix {
+	if (f != lp.FormatLP && f != lp.FormatGLPK) || m == nil {
+		return nil
+	}
+	for _, c := range m.Rows {
+		if c.Pos.Line != d.Pos.Line || c.Pos.Col != d.Pos.Col || c.Pos.File != d.Pos.File {
+			continue
+		}
+		if c.Ranged || len(c.LHS.Terms)+len(c.LHS.Quad)+len(c.RHS.Terms)+len(c.RHS.Quad) > 0 {
+			return nil
+		}
+		l, r := c.LHS.Constant, c.RHS.Constant
+		if holds := c.Rel == lp.RelLE && l <= r || c.Rel == lp.RelGE && l >= r || c.Rel == lp.RelEQ && l == r; !holds {
+			return nil
+		}
+		start := d.Pos.Col
+		if strings.TrimSpace(line[:start-1]) == "" {
+			start = 1
+		}
+		title := "Remove the constraint"
+		if c.Name != "" {
+			title = fmt.Sprintf("Remove constraint %s", c.Name)
+		}
+		end := int32(len(line)) + 1
+		return []fix{{title, []edit{{lp.Pos{Line: d.Pos.Line, Col: start, EndCol: end}, ""}}}}
+	}
+	return nil
+}
+
+// fixMissingEnd adds END, or ENDATA in MPS files,
+// after the last line that is not blank.
+func fixMissingEnd(lines []string, f lp.Format) []fix {
+	end := "End"
+	if f == lp.FormatMPS || f == lp.FormatFreeMPS {
+		end = "ENDATA"
+	}
+	n := len(lines)
+	for n > 1 && strings.TrimSpace(lines[n-1]) == "" {
+		n--
+	}
+	last := int32(len(lines[n-1])) + 1
+	return []fix{{fmt.Sprintf("Add %s", end), []edit{{lp.Pos{Line: int32(n), Col: last, EndCol: last}, "\n" + end}}}}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// autoFixable holds the checks whose fixes -fix applies:
+// those that can be fixed mechanically without changing the model.
+var autoFixable = map[string]bool{
+	"LP009": true, // name-too-long
+	"LP010": true, // line-too-long
+	"LP012": true, // invalid-name
+	"LP013": true, // missing-end
+	"LP014": true, // misspelled-section
+	"LP021": true, // exponent-name
+}
+
+// maxFixRounds limits how often a file is fixed and analyzed again,
+// since fixing some problems, such as syntax errors, uncovers others.
+const maxFixRounds = 5
+
+// fixFile applies the fixes for the problems in the model at p.
+// With dryRun set, it prints a diff of the changes instead of writing them.
+func fixFile(p string, dryRun bool) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	eol := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		eol = "\r\n"
+	}
+	orig := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	lines := orig
+	format := formatOf(p)
+	fixed := 0
+	for round := 0; round < maxFixRounds; round++ {
+		m, diags, err := analyze(p, strings.NewReader(strings.Join(lines, "\n")), format)
+		if err != nil {
+			return err
+		}
+		var edits []edit
+		n := 0
+		for _, d := range applyPolicy(diags, true) {
+			if !autoFixable[d.Check] {
+				continue
+			}
+			if fixes := fixesFor(d, lines, m, format); len(fixes) > 0 {
+				edits = append(edits, fixes[0].edits...)
+				n++
+			}
+		}
+		if n == 0 {
+			break
+		}
+		lines = applyEdits(lines, edits)
+		fixed += n
+	}
+	if fixed == 0 {
+		return nil
+	}
+	if dryRun {
+		os.Stdout.WriteString(unifiedDiff(p, orig, lines))
+		return nil
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, []byte(strings.Join(lines, eol)), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	log.Printf("%s: fixed %s", p, plural(fixed, "problem"))
+	return nil
+}
+
+// applyEdits returns lines with edits applied.
+// Edits are made right to left so that their columns stay valid;
+// an edit that overlaps one already made is dropped.
+func applyEdits(lines []string, edits []edit) []string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i].pos, edits[j].pos
+		if a.Line != b.Line {
+			return a.Line > b.Line
+		}
+		return a.Col > b.Col
+	})
+	out := append([]string(nil), lines...)
+	var prev lp.Pos
+	for i, e := range edits {
+		p := e.pos
+		if p.Line < 1 || int(p.Line) > len(out) {
+			continue
+		}
+		line := out[p.Line-1]
+		start, end := int(p.Col)-1, int(p.EndCol)-1
+		if start < 0 || end < start || end > len(line) {
+			continue
+		}
+		if i > 0 && p.Line == prev.Line && (end > int(prev.Col)-1 || p == prev) {
+			continue
+		}
+		out[p.Line-1] = line[:start] + e.text + line[end:]
+		prev = p
+	}
+	// Edits may insert line breaks.
+	return strings.Split(strings.Join(out, "\n"), "\n")
+}
+
+// A diffOp is a line of a diff: kept (' '), deleted ('-'), or inserted ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// lineDiff returns the shortest edit script from a to b,
+// using Myers' algorithm.
+func lineDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	// trace[d] holds, for each diagonal k in [-d, d] at index k+d,
+	// the furthest x reached with d-1 edits.
+	var trace [][]int
+	v := map[int]int{1: 0}
+	for d := 0; d <= n+m; d++ {
+		snap := make([]int, 2*d+1)
+		for k := -d; k <= d; k++ {
+			snap[k+d] = v[k]
+		}
+		trace = append(trace, snap)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1] < v[k+1]) {
+				x = v[k+1]
+			} else {
+				x = v[k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[y-1]})
+			y--
+		} else {
+			ops = append(ops, diffOp{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffOp{' ', a[x-1]})
+		x, y = x-1, y-1
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff returns the changes from a to b, the lines of the file at p,
+// as a unified diff with three lines of context.
+func unifiedDiff(p string, a, b []string) string {
+	const context = 3
+	ops := lineDiff(a, b)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", p, p)
+	// ai and bi count the lines of a and b before ops[i].
+	ai, bi := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		ai[i+1], bi[i+1] = ai[i], bi[i]
+		if op.kind != '+' {
+			ai[i+1]++
+		}
+		if op.kind != '-' {
+			bi[i+1]++
+		}
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while changes are close enough to share context.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end += context
+		if end > len(ops) {
+			end = len(ops)
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", ai[start]+1, ai[end]-ai[start], bi[start]+1, bi[end]-bi[start])
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+		i = end
+	}
+	return out.String()
+}
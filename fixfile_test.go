@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+func TestFixFile(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	for _, tt := range []struct {
+		name  string
+		file  string
+		model string
+		want  string
+	}{
+		{
+			"missing END",
+			"m.lp",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\n",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n",
+		},
+		{
+			"missing END after blank lines",
+			"m.lp",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\n\n\n",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n\n\n",
+		},
+		{
+			"missing ENDATA",
+			"m.mps",
+			"NAME T\nROWS\n N COST\nCOLUMNS\n x COST 1\n",
+			"NAME T\nROWS\n N COST\nCOLUMNS\n x COST 1\nENDATA\n",
+		},
+		{
+			"exponent-like name",
+			"m.lp",
+			"Minimize\n obj: x + e1\nSubject To\n c1: x + e1 >= 1\nEnd\n",
+			"Minimize\n obj: x + _e1\nSubject To\n c1: x + _e1 >= 1\nEnd\n",
+		},
+		{
+			"misspelled section",
+			"m.lp",
+			"Minimize\n obj: x\nSubjet To\n c1: x >= 1\nEnd\n",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n",
+		},
+		{
+			"CRLF line endings",
+			"m.lp",
+			"Minimize\r\n obj: x\r\nSubject To\r\n c1: x >= 1\r\n",
+			"Minimize\r\n obj: x\r\nSubject To\r\n c1: x >= 1\r\nEnd\r\n",
+		},
+		{
+			"nothing to fix",
+			"m.lp",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n",
+		},
+	} {
+		p := filepath.Join(t.TempDir(), tt.file)
+		if err := os.WriteFile(p, []byte(tt.model), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if err := fixFile(p, false); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	lines := []string{"abc def", "ghi"}
+	for _, tt := range []struct {
+		name  string
+		edits []edit
+		want  string
+	}{
+		{"replace", []edit{{editPos(1, 5, 8), "xyz"}}, "abc xyz\nghi"},
+		{"insert", []edit{{editPos(2, 4, 4), "\nEnd"}}, "abc def\nghi\nEnd"},
+		{"several on a line", []edit{{editPos(1, 1, 1), "_"}, {editPos(1, 5, 5), "_"}}, "_abc _def\nghi"},
+		{"duplicate", []edit{{editPos(1, 1, 4), "x"}, {editPos(1, 1, 4), "x"}}, "x def\nghi"},
+		{"overlapping", []edit{{editPos(1, 1, 6), "x"}, {editPos(1, 5, 8), "y"}}, "abc y\nghi"},
+		{"out of range", []edit{{editPos(3, 1, 1), "x"}, {editPos(2, 1, 9), "y"}}, "abc def\nghi"},
+	} {
+		if got := strings.Join(applyEdits(lines, tt.edits), "\n"); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func editPos(line, col, endCol int32) lp.Pos {
+	return lp.Pos{Line: line, Col: col, EndCol: endCol}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			"no changes",
+			"a\nb", "a\nb",
+			"--- m.lp\n+++ m.lp\n",
+		},
+		{
+			"append",
+			"a\nb\nc", "a\nb\nc\nEnd",
+			"--- m.lp\n+++ m.lp\n@@ -1,3 +1,4 @@\n a\n b\n c\n+End\n",
+		},
+		{
+			"replace",
+			"1\n2\n3\n4\n5\n6\n7\n8", "1\n2\n3\n4\nfive\n6\n7\n8",
+			"--- m.lp\n+++ m.lp\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"separate hunks",
+			"a\n1\n2\n3\n4\n5\n6\n7\nb", "A\n1\n2\n3\n4\n5\n6\n7\nB",
+			"--- m.lp\n+++ m.lp\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+	} {
+		got := unifiedDiff("m.lp", strings.Split(tt.a, "\n"), strings.Split(tt.b, "\n"))
+		if got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// genCmd implements "lpvet gen", which writes random models
+// for fuzzing tools that read them and for benchmarks.
+func genCmd(args []string) {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	nvars := fs.Int("vars", 100, "number of variables")
+	ncons := fs.Int("cons", 100, "number of constraints")
+	density := fs.Float64("density", 0.1, "fraction of the variables in each constraint")
+	seed := fs.Int64("seed", 1, "seed of the random numbers")
+	intFrac := fs.Float64("int", 0, "fraction of the variables that are general integers")
+	binFrac := fs.Float64("binary", 0, "fraction of the variables that are binary")
+	eqFrac := fs.Float64("eq", 0, "fraction of the constraints that are equalities")
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet gen [-vars n] [-cons m] [-density d] [-seed s] [-int f] [-binary f] [-eq f] [-o out]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	if rest := parseInterspersed(fs, args); len(rest) != 0 {
+		fs.Usage()
+	}
+	if *nvars < 1 || *ncons < 0 {
+		log.Fatal("-vars must be positive and -cons not negative")
+	}
+	if *density <= 0 || *density > 1 {
+		log.Fatal("-density must be in (0, 1]")
+	}
+	for _, f := range []float64{*intFrac, *binFrac, *eqFrac} {
+		if f < 0 || f > 1 {
+			log.Fatal("fractions must be in [0, 1]")
+		}
+	}
+	if *intFrac+*binFrac > 1 {
+		log.Fatal("-int and -binary add up to more than 1")
+	}
+	m := genModel(rand.New(rand.NewSource(*seed)), genOptions{
+		vars: *nvars, cons: *ncons, density: *density,
+		intFrac: *intFrac, binFrac: *binFrac, eqFrac: *eqFrac,
+	})
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "\\ lpvet gen -vars %d -cons %d -density %g -seed %d -int %g -binary %g -eq %g\n",
+		*nvars, *ncons, *density, *seed, *intFrac, *binFrac, *eqFrac)
+	if err := lp.WriteLP(&b, m); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*out, b.Bytes())
+}
+
+type genOptions struct {
+	vars, cons               int
+	density                  float64
+	intFrac, binFrac, eqFrac float64
+}
+
+// genModel returns a random model.
+// Its constraints are built around a random point within the bounds,
+// so the model is feasible, and every variable is in the objective,
+// so none are unused.
+func genModel(rng *rand.Rand, o genOptions) *lp.LP {
+	m := new(lp.LP)
+	vars := make([]lp.Symbol, o.vars)
+	point := make([]float64, o.vars)
+	nint := int(math.Round(o.intFrac * float64(o.vars)))
+	nbin := int(math.Round(o.binFrac * float64(o.vars)))
+	for i := range vars {
+		vars[i] = lp.Symbol{Value: "x" + strconv.Itoa(i+1)}
+		switch {
+		case i < nbin:
+			m.BinaryVars.AddSym(vars[i])
+			point[i] = float64(rng.Intn(2))
+		case i < nbin+nint:
+			m.GeneralVars.AddSym(vars[i])
+			hi := float64(1 + rng.Intn(100))
+			m.Bounds.AddSym(vars[i])
+			m.VarBounds = append(m.VarBounds, &lp.Bound{Var: vars[i], Upper: hi, HasUpper: true})
+			point[i] = float64(rng.Intn(int(hi) + 1))
+		default:
+			hi := float64(1 + rng.Intn(100))
+			m.Bounds.AddSym(vars[i])
+			m.VarBounds = append(m.VarBounds, &lp.Bound{Var: vars[i], Upper: hi, HasUpper: true})
+			point[i] = hi * rng.Float64()
+		}
+	}
+	coef := func() float64 {
+		c := float64(1 + rng.Intn(20))
+		if rng.Intn(2) == 0 {
+			c = -c
+		}
+		return c
+	}
+
+	m.Obj = &lp.Objective{Name: "obj", Sense: lp.Minimize}
+	for _, v := range vars {
+		m.Obj.Expr.Terms = append(m.Obj.Expr.Terms, lp.Term{Coef: coef(), Var: v})
+		m.Objective.AddSym(v)
+	}
+
+	for r := 0; r < o.cons; r++ {
+		c := &lp.Constraint{Name: "c" + strconv.Itoa(r+1)}
+		activity := 0.0
+		add := func(i int) {
+			t := lp.Term{Coef: coef(), Var: vars[i]}
+			c.LHS.Terms = append(c.LHS.Terms, t)
+			m.Constraints.AddSym(vars[i])
+			activity += t.Coef * point[i]
+		}
+		// Skip ahead geometrically rather than drawing for each variable,
+		// so that sparse models with many variables are quick to make.
+		for i := genSkip(rng, o.density); i < o.vars; i += 1 + genSkip(rng, o.density) {
+			add(i)
+		}
+		if len(c.LHS.Terms) == 0 {
+			add(rng.Intn(o.vars))
+		}
+		switch x := rng.Float64(); {
+		case x < o.eqFrac:
+			c.Rel = lp.RelEQ
+			c.RHS.Constant = activity
+		case x < (1+o.eqFrac)/2:
+			c.Rel = lp.RelLE
+			c.RHS.Constant = math.Ceil(activity + 10*rng.Float64())
+		default:
+			c.Rel = lp.RelGE
+			c.RHS.Constant = math.Floor(activity - 10*rng.Float64())
+		}
+		m.Rows = append(m.Rows, c)
+	}
+	return m
+}
+
+// genSkip returns the number of variables to skip before the next one
+// that is in a constraint, each being in it with probability density.
+func genSkip(rng *rand.Rand, density float64) int {
+	if density >= 1 {
+		return 0
+	}
+	return int(math.Log(1-rng.Float64()) / math.Log(1-density))
+}
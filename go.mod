@@ -1 +1,3 @@
 module github.com/uluyol/lpvet
+
+go 1.27.1
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// graphCmd implements "lpvet graph", which writes the bipartite graph
+// of variables and the constraints they appear in as GraphViz DOT.
+func graphCmd(args []string) {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	around := fs.String("var", "", "only include the neighborhood of the variable `name`")
+	depth := fs.Int("depth", 2, "with -var, include nodes up to `n` edges away")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet graph [-var name [-depth n]] [-o out.dot] in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 || *depth < 0 {
+		fs.Usage()
+	}
+	m := mustReadModel(ins[0])
+	mat := lp.ConstraintMatrix(m)
+	vars := lp.Variables(m)
+
+	keepVar := make([]bool, len(mat.Cols))
+	keepRow := make([]bool, len(mat.Rows))
+	if *around == "" {
+		for i := range keepVar {
+			keepVar[i] = true
+		}
+		for i := range keepRow {
+			keepRow[i] = true
+		}
+	} else {
+		start := -1
+		for i, name := range mat.Cols {
+			if name == *around {
+				start = i
+			}
+		}
+		if start < 0 {
+			log.Fatalf("%s: no variable %s", ins[0], *around)
+		}
+		neighborhood(mat, start, *depth, keepVar, keepRow)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("graph model {\n")
+	b.WriteString("\tnode [fontname=\"Helvetica\"];\n")
+	for i, v := range vars {
+		if !keepVar[i] {
+			continue
+		}
+		attrs := "shape=ellipse"
+		switch v.Type {
+		case "binary", "general", "semi-integer":
+			attrs += ", style=filled, fillcolor=\"#dde8f8\""
+		}
+		if v.Name == *around {
+			attrs += ", penwidth=3"
+		}
+		fmt.Fprintf(&b, "\t%s [label=%s, tooltip=%s, %s];\n",
+			dotID("v", v.Name), strconv.Quote(v.Name), strconv.Quote(v.Type), attrs)
+	}
+	for i, name := range mat.Rows {
+		if keepRow[i] {
+			fmt.Fprintf(&b, "\t%s [label=%s, shape=box];\n", dotID("c", name), strconv.Quote(name))
+		}
+	}
+	for _, e := range mat.Entries {
+		if keepRow[e.Row] && keepVar[e.Col] {
+			fmt.Fprintf(&b, "\t%s -- %s [tooltip=\"%g\"];\n",
+				dotID("c", mat.Rows[e.Row]), dotID("v", mat.Cols[e.Col]), e.Value)
+		}
+	}
+	b.WriteString("}\n")
+	writeOutput(*out, b.Bytes())
+}
+
+// neighborhood marks the variables and rows of m that are at most depth
+// edges away from the variable in column start.
+func neighborhood(m *lp.Matrix, start, depth int, keepVar, keepRow []bool) {
+	rowsOf := make([][]int, len(m.Cols))
+	colsOf := make([][]int, len(m.Rows))
+	for _, e := range m.Entries {
+		rowsOf[e.Col] = append(rowsOf[e.Col], e.Row)
+		colsOf[e.Row] = append(colsOf[e.Row], e.Col)
+	}
+	keepVar[start] = true
+	vars := []int{start}
+	for d := 0; d < depth; d += 2 {
+		var rows []int
+		for _, v := range vars {
+			for _, r := range rowsOf[v] {
+				if !keepRow[r] {
+					keepRow[r] = true
+					rows = append(rows, r)
+				}
+			}
+		}
+		if d+1 == depth {
+			break
+		}
+		vars = nil
+		for _, r := range rows {
+			for _, v := range colsOf[r] {
+				if !keepVar[v] {
+					keepVar[v] = true
+					vars = append(vars, v)
+				}
+			}
+		}
+	}
+}
+
+// dotID returns the quoted DOT ID of a node, whose kind, v or c,
+// keeps variables and constraints of the same name apart.
+func dotID(kind, name string) string { return strconv.Quote(kind + ":" + name) }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// htmlReporter writes a self-contained HTML report to stdout:
+// the problems grouped by check, and for each file its statistics,
+// an index of its variables and constraints, and its source
+// with the lines that have problems highlighted.
+type htmlReporter struct {
+	files []*htmlFile
+	diags []lp.Diagnostic
+}
+
+type htmlFile struct {
+	Name   string
+	ID     string // prefix of the anchors of its lines
+	Stats  [][2]string
+	Index  []htmlSymbol
+	Source []htmlLine
+}
+
+type htmlSymbol struct {
+	Name, Kind, Detail string
+	Anchor             string // of the line that defines it, or ""
+}
+
+type htmlLine struct {
+	N        int
+	Anchor   string
+	Text     string
+	Severity string // of the worst problem on the line, or ""
+	Messages []string
+}
+
+type htmlCheck struct {
+	Check    *lp.Check
+	Summary  string // first line of the documentation
+	Findings []htmlFinding
+}
+
+type htmlFinding struct {
+	Pos      string
+	Anchor   string // of the source line, or "" if it is not shown
+	Severity string
+	Message  string
+}
+
+func (r *htmlReporter) report(file string, diags []lp.Diagnostic) {
+	f := &htmlFile{Name: file, ID: fmt.Sprintf("f%d", len(r.files)+1)}
+	r.files = append(r.files, f)
+	r.diags = append(r.diags, diags...)
+
+	lines := linesOf(file)
+	onLine := make(map[int][]lp.Diagnostic)
+	for _, d := range diags {
+		if d.Pos.File == file {
+			onLine[int(d.Pos.Line)] = append(onLine[int(d.Pos.Line)], d)
+		}
+	}
+	for i, text := range lines {
+		l := htmlLine{N: i + 1, Anchor: f.anchor(i + 1), Text: strings.TrimRight(text, "\r")}
+		for _, d := range onLine[i+1] {
+			if l.Severity == "" || d.Severity == lp.Error {
+				l.Severity = d.Severity.String()
+			}
+			l.Messages = append(l.Messages, fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Check))
+		}
+		f.Source = append(f.Source, l)
+	}
+
+	// Syntax errors are reported above, but leave no model to describe.
+	// Other errors from the parser, such as a missing END, do.
+	m, err := lp.ParseFormatLimits(file, strings.NewReader(strings.Join(lines, "\n")), formatOf(file), limits())
+	if errs, ok := err.(lp.ErrorList); ok {
+		for _, e := range errs {
+			if e.Check == "LP011" {
+				return
+			}
+		}
+	} else if err != nil {
+		return
+	}
+	f.Stats = statsRows(lp.ComputeStats(m))
+	anchor := func(pos lp.Pos) string {
+		if pos.File != file || pos.Line == 0 {
+			return ""
+		}
+		return f.anchor(int(pos.Line))
+	}
+	for _, v := range lp.Variables(m) {
+		f.Index = append(f.Index, htmlSymbol{v.Name, "variable", v.Type, anchor(v.Pos)})
+	}
+	rowNames := lp.ConstraintMatrix(m).Rows
+	for i, c := range m.Rows {
+		detail := c.Rel.String()
+		if c.Ranged {
+			detail = "ranged"
+		}
+		if c.Indicator != nil {
+			detail += ", indicator"
+		}
+		f.Index = append(f.Index, htmlSymbol{rowNames[i], "constraint", detail, anchor(c.Pos)})
+	}
+}
+
+func (f *htmlFile) anchor(line int) string { return fmt.Sprintf("%s-L%d", f.ID, line) }
+
+func (r *htmlReporter) close() error {
+	anchors := make(map[string]*htmlFile)
+	for _, f := range r.files {
+		anchors[f.Name] = f
+	}
+	byCheck := make(map[string]*htmlCheck)
+	errors, warnings := 0, 0
+	for _, d := range r.diags {
+		c := byCheck[d.Check]
+		if c == nil {
+			check, err := lp.LookupCheck(d.Check)
+			if err != nil {
+				check = &lp.Check{ID: d.Check}
+			}
+			c = &htmlCheck{Check: check, Summary: strings.SplitN(check.Doc, "\n", 2)[0]}
+			byCheck[d.Check] = c
+		}
+		fd := htmlFinding{Pos: d.Pos.String(), Severity: d.Severity.String(), Message: d.Message}
+		if f := anchors[d.Pos.File]; f != nil && int(d.Pos.Line) >= 1 && int(d.Pos.Line) <= len(f.Source) {
+			fd.Anchor = f.anchor(int(d.Pos.Line))
+		}
+		c.Findings = append(c.Findings, fd)
+		if d.Severity == lp.Error {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	var checks []*htmlCheck
+	for _, c := range byCheck {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Check.ID < checks[j].Check.ID })
+
+	return htmlTemplate.Execute(os.Stdout, struct {
+		Summary string
+		Checks  []*htmlCheck
+		Files   []*htmlFile
+	}{
+		Summary: fmt.Sprintf("%s and %s in %s.", plural(errors, "error"), plural(warnings, "warning"), plural(len(r.files), "file")),
+		Checks:  checks,
+		Files:   r.files,
+	})
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>lpvet report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+a { color: #0645ad; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.error { color: #b00; }
+.warning { color: #a60; }
+summary { cursor: pointer; font-weight: bold; margin: 0.5em 0; }
+.doc { color: #555; margin: 0.2em 0 0.5em 1.2em; }
+table { border-collapse: collapse; }
+td, th { padding: 0.1em 0.8em 0.1em 0; text-align: left; vertical-align: top; }
+.index { max-height: 20em; overflow-y: auto; }
+pre { font-size: 0.9em; line-height: 1.35; }
+.line { display: block; }
+.line .n { display: inline-block; width: 5em; color: #999; text-align: right; padding-right: 1em; user-select: none; }
+.line.error { background: #fdd; color: inherit; }
+.line.warning { background: #ffd; color: inherit; }
+.line:target { background: #cdf; }
+.msg { display: block; margin-left: 6em; font-family: sans-serif; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>lpvet report</h1>
+<p>{{.Summary}}</p>
+
+<h2>Findings</h2>
+{{range .Checks}}<details open>
+<summary>{{.Check.ID}} {{.Check.Name}} ({{len .Findings}})</summary>
+{{if .Summary}}<p class="doc">{{.Summary}}</p>{{end}}
+<ul>
+{{range .Findings}}<li>{{if .Anchor}}<a href="#{{.Anchor}}">{{.Pos}}</a>{{else}}{{.Pos}}{{end}}: <span class="{{.Severity}}">{{.Severity}}</span>: {{.Message}}</li>
+{{end}}</ul>
+</details>
+{{else}}<p>No problems found.</p>
+{{end}}
+<h2>Files</h2>
+<p><input id="search" type="search" placeholder="Search variables and constraints" size="40"></p>
+{{range .Files}}<section id="{{.ID}}">
+<h3>{{.Name}}</h3>
+{{if .Stats}}<table>
+{{range .Stats}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Index}}<h4>Variables and constraints</h4>
+<div class="index"><table>
+{{range .Index}}<tr class="sym" data-name="{{.Name}}"><td>{{if .Anchor}}<a href="#{{.Anchor}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td>{{.Kind}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table></div>
+{{end}}<details>
+<summary>Source</summary>
+<pre>{{range .Source}}<span id="{{.Anchor}}" class="line{{if .Severity}} {{.Severity}}{{end}}"><a class="n" href="#{{.Anchor}}">{{.N}}</a>{{.Text}}{{range .Messages}}<span class="msg">{{.}}</span>{{end}}</span>{{end}}</pre>
+</details>
+</section>
+{{end}}
+<script>
+// Open the source when following a link to one of its lines.
+function reveal() {
+	var target = location.hash && document.getElementById(location.hash.slice(1));
+	if (!target) return;
+	for (var el = target; el; el = el.parentElement) {
+		if (el.tagName === "DETAILS") el.open = true;
+	}
+	target.scrollIntoView();
+}
+window.addEventListener("hashchange", reveal);
+reveal();
+document.getElementById("search").addEventListener("input", function() {
+	var q = this.value.toLowerCase();
+	document.querySelectorAll("tr.sym").forEach(function(tr) {
+		tr.style.display = tr.dataset.name.toLowerCase().indexOf(q) >= 0 ? "" : "none";
+	});
+});
+</script>
+</body>
+</html>
+`))
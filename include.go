@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// includeRE matches \include comments, which name a file to read
+// as part of the model, relative to the directory of the including file.
+var includeRE = regexp.MustCompile(`^\s*\\include\s+(.+?)\s*$`)
+
+// A modelFile is one of the files of a model.
+type modelFile struct {
+	name string // as shown in positions
+	data []byte
+}
+
+// canInclude reports whether files in format can have \include comments,
+// which must be comments in the format.
+func canInclude(format lp.Format) bool {
+	return format == lp.FormatLP || format == lp.FormatGLPK
+}
+
+// readIncludes returns the files that the model called name,
+// at path p with contents data, includes, directly or through the files
+// it includes, in the order they are included.
+// Each file is read once, even if it is included several times.
+// Files that cannot be read and cycles of includes are returned
+// as syntax errors at their \include comments.
+func readIncludes(name, p string, data []byte) ([]modelFile, lp.ErrorList) {
+	var (
+		files  []modelFile
+		errs   lp.ErrorList
+		seen   = map[string]bool{filepath.Clean(p): true}
+		active = map[string]bool{filepath.Clean(p): true} // being read
+	)
+	var walk func(name, p string, data []byte)
+	walk = func(name, p string, data []byte) {
+		for i, line := range strings.Split(string(data), "\n") {
+			m := includeRE.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			inc := strings.Trim(m[1], `"`)
+			if !filepath.IsAbs(inc) {
+				// Stdin's includes are relative to the working directory.
+				inc = filepath.Join(filepath.Dir(p), inc)
+			}
+			pos := lp.Pos{File: name, Line: int32(i + 1)}
+			if active[inc] {
+				errs = append(errs, &lp.SyntaxError{Pos: pos, Check: "LP011", Msg: fmt.Sprintf("%s includes itself", inc)})
+				continue
+			}
+			if seen[inc] {
+				continue
+			}
+			incData, err := os.ReadFile(inc)
+			if err != nil {
+				errs = append(errs, &lp.SyntaxError{Pos: pos, Check: "LP011", Msg: fmt.Sprintf("cannot include %s: %v", inc, unwrapPathError(err))})
+				continue
+			}
+			seen[inc], active[inc] = true, true
+			files = append(files, modelFile{inc, incData})
+			walk(inc, inc, incData)
+			active[inc] = false
+		}
+	}
+	walk(name, p, data)
+	return files, errs
+}
+
+// unwrapPathError returns the reason for err without the path,
+// which the message already names.
+func unwrapPathError(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
+
+// parseFiles parses files and merges their models,
+// adding the syntax errors in them to errs.
+func parseFiles(files []modelFile, errs lp.ErrorList) (*lp.LP, error) {
+	var models []*lp.LP
+	for _, f := range files {
+		m, err := lp.ParseFormatLimits(f.name, bytes.NewReader(f.data), formatOf(f.name), limits())
+		if el, ok := err.(lp.ErrorList); ok {
+			errs = append(errs, el...)
+		} else if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	m := lp.Merge(models...)
+	if len(errs) > 0 {
+		return m, errs
+	}
+	return m, nil
+}
+
+// vetMerged returns the problems in the model made of all of files,
+// as for -merge, and of the files they include.
+func vetMerged(files []string, issueWarnings bool) ([]lp.Diagnostic, error) {
+	var (
+		all  []modelFile
+		errs lp.ErrorList
+		read = make(map[string]bool)
+	)
+	add := func(f modelFile) {
+		if key := filepath.Clean(f.name); !read[key] {
+			read[key] = true
+			all = append(all, f)
+		}
+	}
+	for _, p := range files {
+		name := p
+		var data []byte
+		var err error
+		if p == "-" {
+			name = *cmdStdinName
+			if data, err = io.ReadAll(os.Stdin); err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			cacheSource(name, data)
+		} else if data, err = os.ReadFile(p); err != nil {
+			return nil, err
+		}
+		add(modelFile{name, data})
+		if canInclude(formatOf(name)) {
+			inc, incErrs := readIncludes(name, p, data)
+			for _, f := range inc {
+				add(f)
+			}
+			errs = append(errs, incErrs...)
+		}
+	}
+	_, diags, err := analyzeModel(parseFiles(all, errs))
+	if err != nil {
+		return nil, err
+	}
+	return applyPolicy(diags, issueWarnings), nil
+}
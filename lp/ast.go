@@ -0,0 +1,242 @@
+package lp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rel is the relation of a constraint or bound.
+type Rel int
+
+const (
+	RelLE Rel = iota
+	RelGE
+	RelEQ
+)
+
+func (r Rel) String() string {
+	switch r {
+	case RelLE:
+		return "<="
+	case RelGE:
+		return ">="
+	case RelEQ:
+		return "="
+	}
+	return "?"
+}
+
+// Flip returns the relation obtained by swapping both sides.
+func (r Rel) Flip() Rel {
+	switch r {
+	case RelLE:
+		return RelGE
+	case RelGE:
+		return RelLE
+	}
+	return r
+}
+
+type Sense int
+
+const (
+	Minimize Sense = iota
+	Maximize
+)
+
+func (s Sense) String() string {
+	if s == Maximize {
+		return "maximize"
+	}
+	return "minimize"
+}
+
+// A Term is a variable multiplied by a coefficient.
+type Term struct {
+	Coef float64
+	Var  Symbol
+}
+
+// A QuadTerm is a quadratic term Coef*Var1*Var2.
+// For squares, Var1 and Var2 are the same.
+type QuadTerm struct {
+	Coef float64
+	Var1 Symbol
+	Var2 Symbol
+}
+
+// An Expr is a sum of terms plus a constant.
+// Quad holds any quadratic terms, with the objective's "/ 2" applied.
+type Expr struct {
+	Terms    []Term
+	Quad     []QuadTerm
+	Constant float64
+}
+
+func (e Expr) String() string {
+	var b strings.Builder
+	for i, t := range e.Terms {
+		c := t.Coef
+		switch {
+		case i > 0 && c < 0:
+			b.WriteString(" - ")
+			c = -c
+		case i > 0:
+			b.WriteString(" + ")
+		case c < 0:
+			b.WriteString("- ")
+			c = -c
+		}
+		if c != 1 {
+			b.WriteString(formatNum(c))
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.Var.Value)
+	}
+	if len(e.Quad) > 0 {
+		if len(e.Terms) > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString("[ ")
+		for i, q := range e.Quad {
+			c := q.Coef
+			switch {
+			case i > 0 && c < 0:
+				b.WriteString(" - ")
+				c = -c
+			case i > 0:
+				b.WriteString(" + ")
+			case c < 0:
+				b.WriteString("- ")
+				c = -c
+			}
+			if c != 1 {
+				b.WriteString(formatNum(c))
+				b.WriteByte(' ')
+			}
+			if q.Var1.Value == q.Var2.Value {
+				b.WriteString(q.Var1.Value + " ^ 2")
+			} else {
+				b.WriteString(q.Var1.Value + " * " + q.Var2.Value)
+			}
+		}
+		b.WriteString(" ]")
+	}
+	nonConst := len(e.Terms) > 0 || len(e.Quad) > 0
+	if e.Constant != 0 || !nonConst {
+		c := e.Constant
+		switch {
+		case nonConst && c < 0:
+			b.WriteString(" - ")
+			c = -c
+		case nonConst:
+			b.WriteString(" + ")
+		}
+		b.WriteString(formatNum(c))
+	}
+	return b.String()
+}
+
+// Combined returns e with the terms of each variable,
+// and of each pair of variables, combined into one.
+func (e Expr) Combined() Expr {
+	c := combine(e, Expr{})
+	c.Constant = e.Constant
+	return c
+}
+
+func formatNum(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type Objective struct {
+	Name  string
+	Sense Sense
+	Expr  Expr
+	Pos   Pos
+
+	// Params holds the settings of a Gurobi multi-objective,
+	// such as Priority and Weight.
+	Params map[string]float64
+}
+
+// A Constraint is a row of the form LHS Rel RHS.
+type Constraint struct {
+	Name string
+	LHS  Expr
+	Rel  Rel
+	RHS  Expr
+	Pos  Pos
+
+	Indicator *Indicator // nil unless this is an indicator constraint
+
+	// Ranged rows also have a lower limit.
+	// Their Rel is RelLE, so they read RangeLo <= LHS <= RHS.
+	Ranged  bool
+	RangeLo float64
+}
+
+// Limits returns the expression of c with all variables on one side,
+// combining the terms of each variable, and the limits lo <= e <= hi
+// that c imposes, which are infinite if absent.
+func (c *Constraint) Limits() (e Expr, lo, hi float64) { return rowForm(c) }
+
+// An Indicator makes a constraint apply only when
+// a binary variable takes the given value, as in "b = 1 -> x <= 5".
+type Indicator struct {
+	Var   Symbol
+	Value int  // 0 or 1
+	Equiv bool // written with "<->", so the converse also holds
+}
+
+// A Bound restricts the range of a single variable.
+// Unset sides are reported by HasLower and HasUpper.
+// Free bounds ("x free") have infinite limits on both sides.
+type Bound struct {
+	Var      Symbol
+	Lower    float64
+	Upper    float64
+	HasLower bool
+	HasUpper bool
+	Free     bool
+	Pos      Pos
+}
+
+// A GenConstraint is a Gurobi general constraint
+// such as "r = MAX ( x , y , 3 )".
+type GenConstraint struct {
+	Name      string
+	Result    Symbol
+	Func      string // MAX, MIN, ABS, AND, or OR
+	Args      []Symbol
+	Constants []float64 // constant arguments to MAX and MIN
+	Pos       Pos
+}
+
+// A PWLObj is a Gurobi piecewise-linear objective term
+// for a single variable, given as (x, y) breakpoints.
+type PWLObj struct {
+	Var    Symbol
+	Points [][2]float64
+	Pos    Pos
+}
+
+// An SOS is a special ordered set of type 1 or 2.
+type SOS struct {
+	Name    string
+	Type    int
+	Members []SOSMember
+	Pos     Pos
+}
+
+type SOSMember struct {
+	Var    Symbol
+	Weight float64
+}
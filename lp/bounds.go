@@ -0,0 +1,310 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A boundSite is a limit of a variable with the bound that set it,
+// or a nil bound for the default.
+type boundSite struct {
+	val float64
+	b   *Bound
+}
+
+// later reports whether a is after b in the same file.
+func later(a, b Pos) bool {
+	return a.File == b.File && (a.Line > b.Line || a.Line == b.Line && a.Col > b.Col)
+}
+
+// boundSites returns the limits of each variable with bound statements,
+// as varBounds does, along with the statements that set them.
+func (lp *LP) boundSites(kinds map[string]varKind) map[string][2]boundSite {
+	sites := make(map[string][2]boundSite)
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		r, ok := sites[name]
+		if !ok {
+			r = [2]boundSite{{0, nil}, {math.Inf(1), nil}}
+			if kinds[name] == kindBinary {
+				r[1].val = 1
+			}
+		}
+		if b.Free {
+			r = [2]boundSite{{math.Inf(-1), b}, {math.Inf(1), b}}
+		}
+		if b.HasLower {
+			r[0] = boundSite{b.Lower, b}
+		}
+		if b.HasUpper {
+			r[1] = boundSite{b.Upper, b}
+		}
+		sites[name] = r
+	}
+	return sites
+}
+
+// emptyBounds reports the variables whose lower bound is above their
+// upper bound once all their bounds are applied, which no value can meet,
+// at the later of the two bounds.
+// Only bounds that are both given are compared: CPLEX makes the lower bound
+// -inf for a negative upper bound given alone.
+// Semi-continuous variables are left out, since they can still be 0.
+func emptyBounds(lp *LP) []Diagnostic {
+	_, kinds := lp.columns()
+	sites := lp.boundSites(kinds)
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		if seen[name] || kinds[name] == kindSemiCont || kinds[name] == kindSemiInt {
+			continue
+		}
+		seen[name] = true
+		lo, hi := sites[name][0], sites[name][1]
+		if lo.b == nil || hi.b == nil || lo.val <= hi.val {
+			continue
+		}
+		pos := lo.b.Pos
+		if later(hi.b.Pos, pos) {
+			pos = hi.b.Pos
+		}
+		msg := fmt.Sprintf("%s has lower bound %s above its upper bound %s", name, formatNum(lo.val), formatNum(hi.val))
+		if lo.b != hi.b {
+			msg = fmt.Sprintf("%s has lower bound %s at %s above its upper bound %s at %s",
+				name, formatNum(lo.val), lo.b.Pos, formatNum(hi.val), hi.b.Pos)
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      pos,
+			Severity: Error,
+			Check:    checkEmptyBounds,
+			Symbol:   name,
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// maxListed limits the positions listed in a message.
+const maxListed = 10
+
+// How a bound compares with the earlier bounds of its variable.
+const (
+	boundNew     = iota // sets no limit that was set before
+	boundRepeat         // sets limits to the values they had
+	boundTighten        // narrows limits
+	boundLoosen         // widens a limit
+)
+
+// repeatedBounds reports the variables with a lower or upper bound
+// that is given more than once, at the first bound that sets one again,
+// saying how many of the bounds repeat, tighten, or loosen earlier ones.
+// Bounds that leave no values are reported by emptyBounds instead.
+func repeatedBounds(lp *LP) []Diagnostic {
+	type limits struct {
+		lo, hi       float64
+		hasLo, hasHi bool
+		bounds       []*Bound
+		first        *Bound // the first bound that sets a limit again
+		count        [4]int // by how the bound compares
+	}
+	vars := make(map[string]*limits)
+	var order []string
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		l := vars[name]
+		if l == nil {
+			l = new(limits)
+			vars[name] = l
+			order = append(order, name)
+		}
+		l.bounds = append(l.bounds, b)
+		lo, hi, hasLo, hasHi := b.Lower, b.Upper, b.HasLower, b.HasUpper
+		if b.Free {
+			lo, hi, hasLo, hasHi = math.Inf(-1), math.Inf(1), true, true
+		}
+		how := boundNew
+		// compare updates how for a limit that was old and is now v,
+		// which is tighter if tighter(v, old).
+		compare := func(had bool, old, v float64, tighter func(a, b float64) bool) {
+			switch {
+			case !had:
+			case v == old:
+				if how == boundNew {
+					how = boundRepeat
+				}
+			case tighter(v, old):
+				if how != boundLoosen {
+					how = boundTighten
+				}
+			default:
+				how = boundLoosen
+			}
+		}
+		if hasLo {
+			compare(l.hasLo, l.lo, lo, func(a, b float64) bool { return a > b })
+			l.lo, l.hasLo = lo, true
+		}
+		if hasHi {
+			compare(l.hasHi, l.hi, hi, func(a, b float64) bool { return a < b })
+			l.hi, l.hasHi = hi, true
+		}
+		if how != boundNew && l.first == nil {
+			l.first = b
+		}
+		l.count[how]++
+	}
+
+	empty := make(map[string]bool)
+	for _, d := range emptyBounds(lp) {
+		empty[d.Symbol] = true
+	}
+	var diags []Diagnostic
+	for _, name := range order {
+		l := vars[name]
+		if l.first == nil || empty[name] {
+			continue
+		}
+		var at []string
+		for i, b := range l.bounds {
+			if i == maxListed {
+				at = append(at, fmt.Sprintf("%d more", len(l.bounds)-i))
+				break
+			}
+			if b.Pos.File == l.bounds[0].Pos.File {
+				at = append(at, strconv.Itoa(int(b.Pos.Line)))
+			} else {
+				at = append(at, b.Pos.String())
+			}
+		}
+		var hows []string
+		for how, verb := range []string{boundRepeat: "repeating", boundTighten: "tightening", boundLoosen: "loosening"} {
+			if n := l.count[how]; n > 0 && verb != "" {
+				hows = append(hows, fmt.Sprintf("%d %s", n, verb))
+			}
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      l.first.Pos,
+			Severity: Warning,
+			Check:    checkRepeatedBounds,
+			Symbol:   name,
+			Message: fmt.Sprintf("%s is bounded %d times, at lines %s, with %s earlier bounds",
+				name, len(l.bounds), strings.Join(at, ", "), strings.Join(hows, " and ")),
+		})
+	}
+	return diags
+}
+
+// binaryBounds reports the binary variables with a bound other than 0 or 1,
+// at the first such bound.
+func binaryBounds(lp *LP) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	ok := func(v float64) bool { return v == 0 || v == 1 }
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		if seen[name] || !lp.BinaryVars.HasSym(b.Var) {
+			continue
+		}
+		var what string
+		switch {
+		case b.Free:
+			what = "free"
+		case b.HasLower && !ok(b.Lower):
+			what = "bounded below by " + formatNum(b.Lower)
+		case b.HasUpper && !ok(b.Upper):
+			what = "bounded above by " + formatNum(b.Upper)
+		default:
+			continue
+		}
+		seen[name] = true
+		diags = append(diags, Diagnostic{
+			Pos:      b.Pos,
+			Severity: Warning,
+			Check:    checkBinaryBounds,
+			Symbol:   name,
+			Message:  fmt.Sprintf("binary var %s is %s", name, what),
+		})
+	}
+	return diags
+}
+
+// unboundedSemiCont reports the semi-continuous and semi-integer variables
+// without a finite upper bound, which CPLEX requires,
+// at their declarations.
+func unboundedSemiCont(lp *LP) []Diagnostic {
+	_, kinds := lp.columns()
+	sites := lp.boundSites(kinds)
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for _, sec := range []*Section{&lp.SemiContVars, &lp.SemiIntVars} {
+		for _, sym := range sec.Syms() {
+			if seen[sym.Value] {
+				continue
+			}
+			seen[sym.Value] = true
+			if hi, ok := sites[sym.Value]; ok && hi[1].b != nil && !math.IsInf(hi[1].val, 1) {
+				continue
+			}
+			diags = append(diags, Diagnostic{
+				Pos:      sym.Pos,
+				Severity: Error,
+				Check:    checkSemiContBound,
+				Symbol:   sym.Value,
+				Message:  fmt.Sprintf("%s var %s has no finite upper bound", kinds[sym.Value], sym.Value),
+			})
+		}
+	}
+	return diags
+}
+
+// fractionalBounds reports the general and semi-integer variables whose
+// lower or upper bound is not an integer, at the first such bound,
+// along with the bounds that the variable effectively has.
+func fractionalBounds(lp *LP) []Diagnostic {
+	_, kinds := lp.columns()
+	sites := lp.boundSites(kinds)
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	frac := func(s boundSite) bool { return s.b != nil && !math.IsInf(s.val, 0) && s.val != math.Trunc(s.val) }
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		kind := kinds[name]
+		if seen[name] || kind != kindGeneral && kind != kindSemiInt {
+			continue
+		}
+		seen[name] = true
+		lo, hi := sites[name][0], sites[name][1]
+		var msg string
+		var at *Bound
+		switch {
+		case frac(lo) && frac(hi):
+			msg = fmt.Sprintf("%s var %s has fractional bounds %s and %s, which are effectively [%s, %s]",
+				kind, name, formatNum(lo.val), formatNum(hi.val), formatNum(math.Ceil(lo.val)), formatNum(math.Floor(hi.val)))
+			at = lo.b
+			if later(lo.b.Pos, hi.b.Pos) {
+				at = hi.b
+			}
+		case frac(lo):
+			msg = fmt.Sprintf("%s var %s has fractional lower bound %s, which is effectively %s",
+				kind, name, formatNum(lo.val), formatNum(math.Ceil(lo.val)))
+			at = lo.b
+		case frac(hi):
+			msg = fmt.Sprintf("%s var %s has fractional upper bound %s, which is effectively %s",
+				kind, name, formatNum(hi.val), formatNum(math.Floor(hi.val)))
+			at = hi.b
+		default:
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      at.Pos,
+			Severity: Warning,
+			Check:    checkFractionalBounds,
+			Symbol:   name,
+			Message:  msg,
+		})
+	}
+	return diags
+}
@@ -0,0 +1,27 @@
+package lp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFractionalBoundsTwoSided(t *testing.T) {
+	for _, bounds := range []string{
+		" 2.5 <= x <= 7.3\n",
+		" x >= 2.5\n x <= 7.3\n",
+	} {
+		model := "Minimize\n obj: x\nSubject To\n c1: x >= 1\nBounds\n" + bounds + "General\n x\nEnd\n"
+		lp, err := ParseReader("m.lp", strings.NewReader(model))
+		if err != nil {
+			t.Fatal(err)
+		}
+		diags := fractionalBounds(lp)
+		if len(diags) != 1 {
+			t.Fatalf("bounds %q: got %d diagnostics, want 1: %v", bounds, len(diags), diags)
+		}
+		const want = "general var x has fractional bounds 2.5 and 7.3, which are effectively [3, 7]"
+		if d := diags[0]; d.Message != want || d.Pos.Line != 6 {
+			t.Errorf("bounds %q: got %q at line %d, want %q at line 6", bounds, d.Message, d.Pos.Line, want)
+		}
+	}
+}
@@ -0,0 +1,711 @@
+package lp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Check is a kind of problem that lpvet reports.
+// Its ID never changes once assigned, so it can be used
+// to refer to the check from scripts and configuration.
+type Check struct {
+	ID   string // such as "LP001"
+	Name string // such as "undeclared-var"
+	Doc  string // description, examples, and how to fix it
+}
+
+func (c *Check) String() string { return c.ID + " " + c.Name }
+
+// IDs of the checks.
+const (
+	checkUndeclared       = "LP001"
+	checkUnusedVar        = "LP002"
+	checkUnusedBound      = "LP003"
+	checkIndicator        = "LP004"
+	checkEmptyRange       = "LP005"
+	checkSOSWeights       = "LP006"
+	checkFreeBounded      = "LP007"
+	checkUnsupported      = "LP008"
+	checkNameTooLong      = "LP009"
+	checkLineTooLong      = "LP010"
+	checkSyntax           = "LP011"
+	checkInvalidName      = "LP012"
+	checkMissingEnd       = "LP013"
+	checkMisspelled       = "LP014"
+	checkCoefRange        = "LP015"
+	checkSolverError      = "LP016" // reported by lpvet -probe-solver, not Vet
+	checkAcrossFiles      = "LP017"
+	checkConflictingDecls = "LP018"
+	checkDuplicateRow     = "LP019"
+	checkKeywordName      = "LP020"
+	checkExponentName     = "LP021"
+	checkCaseDuplicate    = "LP022"
+	checkTruncation       = "LP023"
+	checkEmptyBounds      = "LP024"
+	checkRepeatedBounds   = "LP025"
+	checkBinaryBounds     = "LP026"
+	checkSemiContBound    = "LP027"
+	checkFractionalBounds = "LP028"
+	checkPropagatedBounds = "LP029"
+	checkInfeasibleRow    = "LP030"
+	checkUnboundedObj     = "LP031"
+	checkTrivialRow       = "LP032"
+	checkConstantObj      = "LP033"
+	checkMissingObj       = "LP034"
+	checkRepeatedObj      = "LP035"
+)
+
+var checks = map[string]*Check{
+	checkUndeclared: {
+		ID:   checkUndeclared,
+		Name: "undeclared-var",
+		Doc: `A variable is used but not declared in any section.
+
+Only reported with -strict-decls, which requires every variable,
+including continuous ones, to be declared.
+
+Example:
+
+	Minimize
+	 obj: x + y
+	Subject To
+	 c1: x + y >= 1
+	General
+	 x
+	End
+
+Here y is not declared.
+
+To fix it, declare the variable in GENERAL, BINARY, SEMI-CONTINUOUS,
+SEMI-INTEGER, or in an lpvet CONTINUOUS section:
+
+	\lpvet:CONTINUOUS
+	\lpvet: y
+
+or drop -strict-decls to treat undeclared variables as continuous.`,
+	},
+	checkUnusedVar: {
+		ID:   checkUnusedVar,
+		Name: "unused-var",
+		Doc: `A variable is declared but appears in neither the objective nor any constraint.
+This is a warning, shown with -warn.
+
+Example:
+
+	Minimize
+	 obj: x
+	Subject To
+	 c1: x >= 1
+	Binary
+	 b
+	End
+
+Here b is declared binary but never used,
+which often means that it was misspelled where it should have been used.
+
+To fix it, use the variable or remove its declaration.`,
+	},
+	checkUnusedBound: {
+		ID:   checkUnusedBound,
+		Name: "unused-bound",
+		Doc: `A variable has a bound but appears nowhere else.
+This is a warning, shown with -warn.
+
+Example:
+
+	Minimize
+	 obj: x
+	Subject To
+	 c1: x >= 1
+	Bounds
+	 y <= 10
+	End
+
+Here y is bounded but not used, which usually means
+that the bound was meant for another variable.
+
+To fix it, correct the name in the bound or remove the bound.`,
+	},
+	checkIndicator: {
+		ID:   checkIndicator,
+		Name: "indicator-not-binary",
+		Doc: `The variable controlling an indicator constraint is not binary.
+Solvers reject indicator constraints on non-binary variables.
+
+Example:
+
+	Subject To
+	 c1: b = 1 -> x + y <= 4
+	General
+	 b
+	End
+
+To fix it, declare the variable in the BINARY section.`,
+	},
+	checkEmptyRange: {
+		ID:   checkEmptyRange,
+		Name: "empty-range",
+		Doc: `A ranged constraint has a lower limit above its upper limit,
+so no point satisfies it.
+
+Example:
+
+	Subject To
+	 r1: 5 <= x + y <= 2
+	End
+
+To fix it, swap the limits or correct the one that is wrong.`,
+	},
+	checkSOSWeights: {
+		ID:   checkSOSWeights,
+		Name: "sos-weights",
+		Doc: `The weights of an SOS set are not strictly increasing.
+Weights order the members of the set, so they must be distinct,
+and solvers expect them in increasing order.
+
+Example:
+
+	SOS
+	 s1: S1:: x:1 y:3 z:2
+	End
+
+To fix it, list the members in order of increasing, distinct weights.`,
+	},
+	checkFreeBounded: {
+		ID:   checkFreeBounded,
+		Name: "free-bounded",
+		Doc: `A variable is declared free but also has a bound.
+This is a warning, shown with -warn.
+Which one takes effect depends on their order,
+so the bound or the free declaration is likely a mistake.
+
+Example:
+
+	Bounds
+	 x free
+	 x <= 4
+	End
+
+To fix it, remove whichever of the two is wrong.`,
+	},
+	checkUnsupported: {
+		ID:   checkUnsupported,
+		Name: "unsupported-feature",
+		Doc: `The model uses a construct that the solver chosen with -solver does not accept,
+such as general constraints with -solver=cplex
+or quadratic constraints with -solver=glpk.
+Each unsupported construct is reported once, at its first use.
+
+To fix it, reformulate the model without the construct,
+or check it against the solver that will be used.`,
+	},
+	checkNameTooLong: {
+		ID:   checkNameTooLong,
+		Name: "name-too-long",
+		Doc: `A variable or constraint name is longer than allowed.
+CPLEX LP files allow names of up to 255 characters,
+-solver checks the limit of that solver,
+and max-var-len in the configuration file overrides it.
+
+To fix it, shorten the name.`,
+	},
+	checkLineTooLong: {
+		ID:   checkLineTooLong,
+		Name: "line-too-long",
+		Doc: `A line is longer than the solver reads:
+510 characters for CPLEX and 255 for GLPK, while Gurobi has no limit.
+-solver selects the limit, and max-line-len in the configuration file overrides it.
+Longer lines are silently truncated by some solvers.
+
+To fix it, split the line; expressions may continue on the next line:
+
+	 c1: x1 + x2 + x3
+	   + x4 + x5 >= 1
+
+lpvet -fix splits long lines this way, between terms.`,
+	},
+	checkSyntax: {
+		ID:   checkSyntax,
+		Name: "syntax",
+		Doc: `The file could not be parsed, for example because a constraint lacks
+a relation, a number is malformed, or text appears outside of any section.
+
+lpvet skips to the next line (or statement in lp_solve files)
+and reports every syntax error in the file.
+The other checks only run once the file parses.
+
+To fix it, correct the syntax at the reported position;
+-show-source shows the offending line.`,
+	},
+	checkInvalidName: {
+		ID:   checkInvalidName,
+		Name: "invalid-name",
+		Doc: `A variable or constraint name contains characters that are not allowed,
+starts with a digit or period, which begin numbers,
+or a constraint name is a keyword, such as "st", "bounds", or "free".
+
+CPLEX LP names may contain letters, digits, and the characters
+!"#$%&(),.;?@_` + "`" + `'{}|~. lp_solve allows a different set of characters,
+and name-chars in the configuration file allows more.
+The message names the first character that is not allowed.
+The same rules apply to the names of constraints and objectives,
+and the message suggests a name with the other characters replaced by underscores.
+A number directly followed by a name, as in 3x, is read as a coefficient and a variable
+where a term can start, but as a name starting with a digit where only a name can be,
+such as after another coefficient, in a declaration, or before a colon.
+
+To fix it, rename the variable or constraint.`,
+	},
+	checkMissingEnd: {
+		ID:   checkMissingEnd,
+		Name: "missing-end",
+		Doc: `The model does not end with END (ENDATA in MPS files).
+CPLEX and Gurobi read such files, but without END a truncated file
+cannot be told apart from a complete one.
+
+To fix it, add END on a line of its own after the last section.`,
+	},
+	checkMisspelled: {
+		ID:   checkMisspelled,
+		Name: "misspelled-section",
+		Doc: `A line that stands alone looks like a misspelled section header,
+such as "Subjet To" or "Binaries:".
+Solvers read it as part of the previous section, usually as a syntax error.
+lpvet reads it as the header it resembles so that it can check the rest of the file.
+
+Example:
+
+	Minimize
+	 obj: x + y
+	Subjet To
+	 c1: x + y >= 1
+	End
+
+To fix it, correct the spelling of the header.`,
+	},
+	checkCoefRange: {
+		ID:   checkCoefRange,
+		Name: "coef-range",
+		Doc: `The coefficients of a constraint span too many orders of magnitude:
+the largest is more than 1e9 times the smallest, or the limit set
+by max-coef-range in the configuration file.
+Solvers work in floating point, and badly scaled rows lead to
+numerical trouble such as slow progress, wrong feasibility verdicts,
+and solutions that violate the constraints.
+
+Example:
+
+	Subject To
+	 c1: 1e-6 x + 5000 y <= 1
+
+To fix it, change the units of the variables or the constraint,
+or drop coefficients that are negligible.
+lpvet stats -coef lists the range of every constraint.`,
+	},
+	checkSolverError: {
+		ID:   checkSolverError,
+		Name: "solver-error",
+		Doc: `The solver named by -probe-solver failed to read the model.
+With -probe-solver, lpvet runs the solver's command-line program on each model,
+reading it without solving it, and reports the errors the solver prints,
+at the line they name if they name one.
+
+Such errors usually come with one from another check, which explains
+the problem better; the solver's own message confirms that it matters
+to the solver, or finds problems that lpvet does not check for.
+
+To fix it, follow the solver's message.`,
+	},
+	checkAcrossFiles: {
+		ID:   checkAcrossFiles,
+		Name: "duplicate-across-files",
+		Doc: `A model split across files, with \include comments or -merge,
+uses the same constraint name in more than one file, or declares
+the same variable in more than one file.
+
+Solvers reject models with two rows of the same name, or keep only one,
+so a reused constraint name is an error. A variable declared in two files
+is only redundant, and is a warning, but the declarations can drift apart
+as the files are edited separately.
+
+Example, with -merge:
+
+	\ a.lp
+	Subject To
+	 cap: x + y <= 4
+	General
+	 x
+
+	\ b.lp
+	Subject To
+	 cap: y + z <= 2
+	General
+	 x
+
+To fix it, rename one of the constraints, such as by prefixing the names
+in each file, and declare each variable in one file.`,
+	},
+	checkConflictingDecls: {
+		ID:   checkConflictingDecls,
+		Name: "conflicting-types",
+		Doc: `A variable is declared with types that contradict each other,
+such as in both the General and Binary sections, or is declared free
+in the Bounds section but also binary, semi-continuous, or semi-integer.
+Solvers accept such models, but settle the conflict differently:
+one may keep the first declaration and another the last,
+so the model means different things to different solvers.
+The message lists every declaration of the variable.
+
+Example:
+
+	General
+	 x
+	Binary
+	 x
+
+Declaring a variable both general and semi-continuous is not a conflict:
+as in CPLEX, it makes the variable semi-integer.
+
+To fix it, keep the declaration of the type that is meant.`,
+	},
+	checkDuplicateRow: {
+		ID:   checkDuplicateRow,
+		Name: "duplicate-constraint",
+		Doc: `Two constraints, or a constraint and the objective, have the same name.
+Solvers either reject the file or keep only one of the rows,
+silently dropping the other from the model.
+Generators that build names from indexes produce duplicates
+when the indexes of two families of constraints overlap.
+
+Example:
+
+	Subject To
+	 cap: x + y <= 4
+	 cap: y + z <= 2
+
+To fix it, rename one of the constraints, or leave it unnamed.
+Across the files of a model split with \include or -merge,
+reused names are reported by LP017 instead.`,
+	},
+	checkKeywordName: {
+		ID:   checkKeywordName,
+		Name: "keyword-name",
+		Doc: `A variable or constraint is named like a keyword of LP files,
+such as st, bounds, end, min, or free.
+Where a line breaks before the name, solvers read it as a section header
+and may silently stop reading the model there,
+and free and inf change the meaning of bounds.
+Constraint labels that are keywords are syntax errors in LP files;
+this check also reports names in MPS files, which cannot be written as LP files as they are.
+
+Example:
+
+	Subject To
+	 c1: x + y
+	   + end >= 1
+
+To fix it, rename the variable, such as to end_.`,
+	},
+	checkExponentName: {
+		ID:   checkExponentName,
+		Name: "exponent-name",
+		Doc: `A variable or constraint is named e or E, alone or followed by digits.
+CPLEX reserves such names for the exponents of numbers,
+so a coefficient followed by the name, as in 2 e3, may be read as the number 2000.
+
+Example:
+
+	Subject To
+	 c1: 2 e3 + x >= 1
+
+To fix it, rename the variable, such as to _e3; -fix does so everywhere in the file.`,
+	},
+	checkCaseDuplicate: {
+		ID:   checkCaseDuplicate,
+		Name: "case-duplicate",
+		Doc: `Two variables have names that differ only in case, such as x_Total and x_total.
+Names are case-sensitive, so they are different variables,
+but such pairs are almost always a bug in the program that wrote the model,
+which meant to use one variable in both places.
+
+Example:
+
+	Minimize
+	 obj: x_Total
+	Subject To
+	 c1: x_total >= 1
+
+To fix it, use the same name for both, or rename one of them.`,
+	},
+	checkTruncation: {
+		ID:   checkTruncation,
+		Name: "truncation-collision",
+		Doc: `Two variable names are the same in their first characters,
+up to the length that names are truncated to.
+Some solvers and tools truncate long names instead of rejecting them,
+which silently merges the two variables into one column.
+The length is truncate-len in the configuration file, such as 8
+for tools that read old fixed-format MPS files, or else the limit on names.
+
+Example, with truncate-len = 8:
+
+	Subject To
+	 c1: flow_north_1 + flow_north_2 >= 1
+
+To fix it, rename the variables so that they differ within the length.`,
+	},
+	checkEmptyBounds: {
+		ID:   checkEmptyBounds,
+		Name: "empty-bounds",
+		Doc: `A variable's lower bound is above its upper bound,
+once all of its bounds are applied in order,
+so no value of the variable is allowed and the model is infeasible.
+The bounds are often on different lines, far apart.
+
+Example:
+
+	Bounds
+	 x >= 5
+	 y <= 3
+	 x <= 2
+
+To fix it, correct or remove one of the bounds.`,
+	},
+	checkRepeatedBounds: {
+		ID:   checkRepeatedBounds,
+		Name: "repeated-bounds",
+		Doc: `A variable's lower or upper bound is given more than once.
+Later bounds replace earlier ones, so only the last one counts.
+The message lists every bound of the variable and says how many of them
+repeat an earlier bound exactly, tighten it, or loosen it;
+a loosened bound usually means that one of the two is a mistake,
+and generators that emit the same bound many times make files needlessly large.
+
+Example:
+
+	Bounds
+	 x <= 10
+	 x >= 1
+	 x <= 4
+
+To fix it, keep only the bound that is meant.`,
+	},
+	checkBinaryBounds: {
+		ID:   checkBinaryBounds,
+		Name: "binary-bounds",
+		Doc: `A binary variable has a bound other than 0 or 1, or is declared free.
+Solvers resolve this differently: some keep the variable binary,
+some make it a general integer with the given bounds,
+and some reject the file, so it is unclear what the model means.
+Bounds of 0 or 1, which fix the variable, are fine.
+
+Example:
+
+	Bounds
+	 y <= 5
+	Binary
+	 y
+
+To fix it, remove the bound, or declare the variable general
+if it is meant to take other values.`,
+	},
+	checkSemiContBound: {
+		ID:   checkSemiContBound,
+		Name: "semi-continuous-unbounded",
+		Doc: `A semi-continuous or semi-integer variable has no finite upper bound.
+Such a variable is either 0 or between its bounds,
+and CPLEX requires the upper bound to be given and finite.
+
+Example:
+
+	Bounds
+	 s >= 2
+	Semi-continuous
+	 s
+
+To fix it, add an upper bound for the variable in the BOUNDS section,
+such as 2 <= s <= 100.`,
+	},
+	checkFractionalBounds: {
+		ID:   checkFractionalBounds,
+		Name: "fractional-bounds",
+		Doc: `A general or semi-integer variable has a bound that is not an integer.
+The variable can only take integer values, so its effective bounds
+are the bound rounded inward, which differs from what is written.
+Often the variable was meant to be continuous and is declared general by mistake.
+
+Example:
+
+	Bounds
+	 2.5 <= x <= 7.3
+	General
+	 x
+
+To fix it, round the bounds, or declare the variable continuous
+if it is meant to take fractional values.`,
+	},
+	checkPropagatedBounds: {
+		ID:   checkPropagatedBounds,
+		Name: "propagated-bounds",
+		Doc: `A variable's bounds together with constraints on it alone
+leave it no values, which is an error, or a single value, which is a warning.
+Constraints with one variable, such as 2 x >= 10, bound that variable,
+and constraints whose other variables are fixed this way bound the last one,
+so the limits that follow are checked before a solver is run.
+Integer variables have their limits rounded inward.
+Variables with no values make the model infeasible;
+variables fixed by constraints are often a sign of a wrong sign or right-hand side.
+
+Example:
+
+	Subject To
+	 c1: 2 x >= 10
+	 c2: x + y <= 3
+	 c3: y = 1
+	Bounds
+	 x <= 8
+
+To fix it, correct the constraints or bounds named in the message,
+or replace a constraint that fixes a variable on purpose with a bound.`,
+	},
+	checkInfeasibleRow: {
+		ID:   checkInfeasibleRow,
+		Name: "infeasible-constraint",
+		Doc: `A constraint cannot be satisfied by any values of its variables within their bounds,
+so the model is infeasible.
+The bounds include those that constraints with a single variable impose (see LP029),
+so this finds constraints whose variables are all fixed to values that do not meet it,
+as well as those whose left side cannot reach the right side at all.
+
+Example:
+
+	Subject To
+	 c1: x + y >= 10
+	Bounds
+	 x <= 3
+	 y <= 4
+
+To fix it, correct the constraint or the bounds of its variables.`,
+	},
+	checkUnboundedObj: {
+		ID:   checkUnboundedObj,
+		Name: "unbounded-objective",
+		Doc: `A variable in the objective can improve it without limit:
+its coefficient favors a direction in which the variable has no bound,
+such as a maximized variable with a positive coefficient and no upper bound,
+and it is in no constraint with other variables that could limit it.
+The model is then unbounded, which usually means that a bound
+or the constraints on the variable are missing.
+
+Example:
+
+	Maximize
+	 obj: 3 x + 2 y
+	Subject To
+	 c1: y <= 4
+
+To fix it, add the missing bound or constraints.`,
+	},
+	checkTrivialRow: {
+		ID:   checkTrivialRow,
+		Name: "trivial-constraint",
+		Doc: `A constraint has no variables, or none once its terms are combined,
+so it only compares constants.
+One that never holds, such as 0 x >= 3, makes the model infeasible and is an error;
+one that always holds, such as x - x <= 5, does nothing and is a warning.
+Either usually means that the program that wrote the model dropped
+the variables, or that lpvet read them as numbers.
+So does a constraint that only compares two numbers, such as 4 >= 7;
+one that holds, such as 3 <= 5, can simply be removed.
+
+Example:
+
+	Subject To
+	 c1: 0 x >= 3
+	 c2: 4 >= 7
+
+To fix it, restore the variables or remove the constraint.`,
+	},
+	checkConstantObj: {
+		ID:   checkConstantObj,
+		Name: "constant-objective",
+		Doc: `The objective has no variables, or none once its terms are combined,
+so every feasible solution is optimal.
+This usually means that the program that wrote the model dropped the costs.
+A model that only needs a feasible solution should say so
+by suppressing this check on the objective with an lpvet:ignore comment.
+
+Example:
+
+	Minimize
+	 cost:
+	Subject To
+	 c1: x + y >= 2
+
+To fix it, restore the costs, or add "\ lpvet:ignore LP033" after the objective.`,
+	},
+	checkMissingObj: {
+		ID:   checkMissingObj,
+		Name: "missing-objective",
+		Doc: `An LP file has constraints but no Minimize or Maximize section.
+Most solvers, including CPLEX, Gurobi, and GLPK, reject such files;
+with -solver highs, which reads them as minimizing 0, this is a warning instead.
+A file that starts with its constraints and no header at all
+is reported as a syntax error.
+
+Example:
+
+	Subject To
+	 c1: x + y >= 2
+	End
+
+To fix it, add an objective section before the constraints
+(see LP033 for a model that only needs a feasible solution).`,
+	},
+	checkRepeatedObj: {
+		ID:   checkRepeatedObj,
+		Name: "repeated-objective",
+		Doc: `An LP file has more than one Minimize or Maximize section,
+which often happens when files are made by joining generated fragments.
+Solvers reject such files or keep only one of the objectives.
+Both sections are named in the message.
+
+Example:
+
+	Minimize
+	 cost: x + y
+	Subject To
+	 c1: x + y >= 2
+	Maximize
+	 profit: 3 x
+
+To fix it, remove all but one objective section.
+CPLEX and Gurobi read several objectives from a single section
+that starts with "Minimize multi-objectives".`,
+	},
+}
+
+// LookupCheck returns the check with the given ID or name,
+// ignoring case.
+func LookupCheck(s string) (*Check, error) {
+	if c, ok := checks[strings.ToUpper(s)]; ok {
+		return c, nil
+	}
+	for _, c := range checks {
+		if strings.EqualFold(c.Name, s) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown check %q", s)
+}
+
+// Checks returns all checks, ordered by ID.
+func Checks() []*Check {
+	var all []*Check
+	for _, c := range checks {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
@@ -0,0 +1,47 @@
+package lp
+
+import "math"
+
+// DefaultMaxCoefRange is the ratio between the largest and smallest
+// coefficient of a constraint above which Vet warns,
+// unless Options.MaxCoefRange says otherwise.
+const DefaultMaxCoefRange = 1e9
+
+// A CoefRange gives the smallest and largest absolute value
+// of the nonzero coefficients of a constraint.
+type CoefRange struct {
+	Name string  `json:"name"`
+	Pos  Pos     `json:"-"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+// Ratio returns Max/Min, or 0 if there are no coefficients.
+func (r CoefRange) Ratio() float64 {
+	if r.Min == 0 {
+		return 0
+	}
+	return r.Max / r.Min
+}
+
+// CoefficientRanges returns the coefficient range of each constraint of lp
+// that has nonzero linear coefficients, in order.
+// Unnamed constraints are named R1, R2, ... as in WriteMPS.
+func CoefficientRanges(lp *LP) []CoefRange {
+	_, names := lp.rowNames()
+	var rs []CoefRange
+	for i, c := range lp.Rows {
+		e, _, _ := rowForm(c)
+		r := CoefRange{Name: names[i], Pos: c.Pos, Min: math.Inf(1)}
+		for _, t := range e.Terms {
+			if a := math.Abs(t.Coef); a != 0 {
+				r.Min = math.Min(r.Min, a)
+				r.Max = math.Max(r.Max, a)
+			}
+		}
+		if r.Max > 0 {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
@@ -0,0 +1,332 @@
+package lp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A ChangeKind says how a part of a model changed.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return "changed"
+}
+
+// A Change is a difference between two models, as found by Compare.
+type Change struct {
+	Kind ChangeKind
+	What string // variable, objective, constraint, sos, general constraint, or pwlobj
+	Name string
+
+	// Detail says what changed, such as "coefficient of x" or "bounds".
+	// For added and removed parts, it is empty.
+	Detail string
+
+	// Old and New describe the part, or its detail, before and after.
+	Old, New string
+
+	Pos Pos // in the new model, or in the old one for removed parts
+}
+
+func (c Change) String() string {
+	var s string
+	switch c.Kind {
+	case Added:
+		s = fmt.Sprintf("+ %s %s", c.What, c.Name)
+		if c.New != "" {
+			s += ": " + c.New
+		}
+	case Removed:
+		s = fmt.Sprintf("- %s %s", c.What, c.Name)
+		if c.Old != "" {
+			s += ": " + c.Old
+		}
+	default:
+		s = fmt.Sprintf("~ %s %s: %s changed from %s to %s", c.What, c.Name, c.Detail, c.Old, c.New)
+	}
+	if c.Pos.Line > 0 {
+		s = c.Pos.String() + ": " + s
+	}
+	return s
+}
+
+// Compare returns the differences between the models a and b,
+// ignoring formatting and the order of statements and terms.
+//
+// Variables are matched by name and compared by type and bounds,
+// where the bounds are the limits that all of a variable's
+// bound statements give it. Constraints are matched by name,
+// with unnamed ones matched by position, and compared by coefficient
+// and limits after moving variables to the left and constants
+// to the right. A single objective in each model is compared
+// regardless of its name; multiple objectives are matched by name.
+func Compare(a, b *LP) []Change {
+	var changes []Change
+	add := func(c Change) { changes = append(changes, c) }
+
+	aCols, aKinds := a.columns()
+	bCols, bKinds := b.columns()
+	aBounds, bBounds := a.varBounds(aKinds), b.varBounds(bKinds)
+	inB := make(map[string]bool)
+	for _, sym := range bCols {
+		inB[sym.Value] = true
+	}
+	inA := make(map[string]Symbol)
+	for _, sym := range aCols {
+		inA[sym.Value] = sym
+		if !inB[sym.Value] {
+			add(Change{Kind: Removed, What: "variable", Name: sym.Value, Old: aKinds[sym.Value].String(), Pos: sym.Pos})
+		}
+	}
+	for _, sym := range bCols {
+		name := sym.Value
+		if _, ok := inA[name]; !ok {
+			add(Change{Kind: Added, What: "variable", Name: name, New: bKinds[name].String(), Pos: sym.Pos})
+			continue
+		}
+		if aKinds[name] != bKinds[name] {
+			add(Change{Kind: Changed, What: "variable", Name: name, Detail: "type",
+				Old: aKinds[name].String(), New: bKinds[name].String(), Pos: sym.Pos})
+		}
+		alo, ahi := boundsOf(name, aBounds, aKinds)
+		blo, bhi := boundsOf(name, bBounds, bKinds)
+		if alo != blo || ahi != bhi {
+			add(Change{Kind: Changed, What: "variable", Name: name, Detail: "bounds",
+				Old: rangeText(alo, ahi), New: rangeText(blo, bhi), Pos: sym.Pos})
+		}
+	}
+
+	aObjs, bObjs := a.objectives(), b.objectives()
+	if len(aObjs) <= 1 && len(bObjs) <= 1 {
+		switch {
+		case len(aObjs) == 1 && len(bObjs) == 1:
+			changes = append(changes, compareObjectives(aObjs[0], bObjs[0])...)
+		case len(aObjs) == 1:
+			o := aObjs[0]
+			add(Change{Kind: Removed, What: "objective", Name: o.Name, Old: o.Sense.String() + " " + exprText(o.Expr, true), Pos: o.Pos})
+		case len(bObjs) == 1:
+			o := bObjs[0]
+			add(Change{Kind: Added, What: "objective", Name: o.Name, New: o.Sense.String() + " " + exprText(o.Expr, true), Pos: o.Pos})
+		}
+	} else {
+		var as, bs []named
+		for _, o := range aObjs {
+			as = append(as, named{o.Name, o.Pos, o})
+		}
+		for _, o := range bObjs {
+			bs = append(bs, named{o.Name, o.Pos, o})
+		}
+		matchNamed(as, bs, func(x, y *named) {
+			switch {
+			case y == nil:
+				o := x.v.(*Objective)
+				add(Change{Kind: Removed, What: "objective", Name: x.name, Old: exprText(o.Expr, true), Pos: x.pos})
+			case x == nil:
+				o := y.v.(*Objective)
+				add(Change{Kind: Added, What: "objective", Name: y.name, New: exprText(o.Expr, true), Pos: y.pos})
+			default:
+				changes = append(changes, compareObjectives(x.v.(*Objective), y.v.(*Objective))...)
+			}
+		})
+	}
+
+	rowsOf := func(m *LP) []named {
+		_, names := m.rowNames()
+		var rows []named
+		for i, c := range m.Rows {
+			rows = append(rows, named{names[i], c.Pos, c})
+		}
+		return rows
+	}
+	matchNamed(rowsOf(a), rowsOf(b), func(x, y *named) {
+		switch {
+		case y == nil:
+			c := x.v.(*Constraint)
+			e, lo, hi := rowForm(c)
+			add(Change{Kind: Removed, What: "constraint", Name: x.name, Old: rowText(c, e, lo, hi), Pos: x.pos})
+		case x == nil:
+			c := y.v.(*Constraint)
+			e, lo, hi := rowForm(c)
+			add(Change{Kind: Added, What: "constraint", Name: y.name, New: rowText(c, e, lo, hi), Pos: y.pos})
+		default:
+			changes = append(changes, compareRows(y.name, x.v.(*Constraint), y.v.(*Constraint))...)
+		}
+	})
+
+	// The other statements are compared as a whole.
+	stmts := func(what string, as, bs []named, text func(interface{}) string) {
+		matchNamed(as, bs, func(x, y *named) {
+			switch {
+			case y == nil:
+				add(Change{Kind: Removed, What: what, Name: x.name, Old: text(x.v), Pos: x.pos})
+			case x == nil:
+				add(Change{Kind: Added, What: what, Name: y.name, New: text(y.v), Pos: y.pos})
+			default:
+				if old, new := text(x.v), text(y.v); old != new {
+					add(Change{Kind: Changed, What: what, Name: y.name, Detail: "definition", Old: old, New: new, Pos: y.pos})
+				}
+			}
+		})
+	}
+	sosOf := func(m *LP) []named {
+		var ss []named
+		for i, s := range m.SOS {
+			name := s.Name
+			if name == "" {
+				name = "#" + strconv.Itoa(i+1)
+			}
+			ss = append(ss, named{name, s.Pos, s})
+		}
+		return ss
+	}
+	stmts("sos", sosOf(a), sosOf(b), func(v interface{}) string { return sosText(v.(*SOS)) })
+	genConsOf := func(m *LP) []named {
+		var gs []named
+		for i, g := range m.GenCons {
+			name := g.Name
+			if name == "" {
+				name = "#" + strconv.Itoa(i+1)
+			}
+			gs = append(gs, named{name, g.Pos, g})
+		}
+		return gs
+	}
+	stmts("general constraint", genConsOf(a), genConsOf(b), func(v interface{}) string { return genConText(v.(*GenConstraint)) })
+	pwlsOf := func(m *LP) []named {
+		var ps []named
+		for _, pw := range m.PWLObjs {
+			ps = append(ps, named{pw.Var.Value, pw.Pos, pw})
+		}
+		return ps
+	}
+	stmts("pwlobj", pwlsOf(a), pwlsOf(b), func(v interface{}) string { return pwlText(v.(*PWLObj)) })
+	return changes
+}
+
+// objectives returns the objectives of lp.
+func (lp *LP) objectives() []*Objective {
+	if len(lp.MultiObj) > 0 {
+		return lp.MultiObj
+	}
+	if lp.Obj != nil {
+		return []*Objective{lp.Obj}
+	}
+	return nil
+}
+
+// A named is a part of a model with its name, for matchNamed.
+type named struct {
+	name string
+	pos  Pos
+	v    interface{}
+}
+
+// matchNamed calls f with a nil y for each part only in as, and then,
+// in the order of bs, with the part of as of the same name, or nil,
+// for each part of bs.
+func matchNamed(as, bs []named, f func(x, y *named)) {
+	byName := make(map[string]*named)
+	for i := range as {
+		byName[as[i].name] = &as[i]
+	}
+	inB := make(map[string]bool)
+	for i := range bs {
+		inB[bs[i].name] = true
+	}
+	for i := range as {
+		if !inB[as[i].name] {
+			f(&as[i], nil)
+		}
+	}
+	for i := range bs {
+		f(byName[bs[i].name], &bs[i])
+	}
+}
+
+func compareObjectives(a, b *Objective) []Change {
+	name := b.Name
+	if name == "" {
+		name = a.Name
+	}
+	var changes []Change
+	if a.Sense != b.Sense {
+		changes = append(changes, Change{Kind: Changed, What: "objective", Name: name, Detail: "sense",
+			Old: a.Sense.String(), New: b.Sense.String(), Pos: b.Pos})
+	}
+	changes = append(changes, compareExprs("objective", name, b.Pos,
+		combine(a.Expr, Expr{}), combine(b.Expr, Expr{}))...)
+	if a.Expr.Constant != b.Expr.Constant {
+		changes = append(changes, Change{Kind: Changed, What: "objective", Name: name, Detail: "constant",
+			Old: formatNum(a.Expr.Constant), New: formatNum(b.Expr.Constant), Pos: b.Pos})
+	}
+	return changes
+}
+
+func compareRows(name string, a, b *Constraint) []Change {
+	ae, alo, ahi := rowForm(a)
+	be, blo, bhi := rowForm(b)
+	changes := compareExprs("constraint", name, b.Pos, ae, be)
+	if alo != blo || ahi != bhi || a.Ranged != b.Ranged {
+		changes = append(changes, Change{Kind: Changed, What: "constraint", Name: name, Detail: "limits",
+			Old: limitsText(alo, ahi, a.Ranged), New: limitsText(blo, bhi, b.Ranged), Pos: b.Pos})
+	}
+	ai, bi := "none", "none"
+	if a.Indicator != nil {
+		ai = indicatorText(a.Indicator)
+	}
+	if b.Indicator != nil {
+		bi = indicatorText(b.Indicator)
+	}
+	if ai != bi {
+		changes = append(changes, Change{Kind: Changed, What: "constraint", Name: name, Detail: "indicator",
+			Old: ai, New: bi, Pos: b.Pos})
+	}
+	return changes
+}
+
+// compareExprs reports the coefficients that differ between a and b,
+// which have their terms combined.
+// Missing terms have a coefficient of 0.
+func compareExprs(what, name string, pos Pos, a, b Expr) []Change {
+	var changes []Change
+	coefs := func(e Expr) (map[string]float64, []string) {
+		m := make(map[string]float64)
+		var keys []string
+		for _, t := range e.Terms {
+			m[t.Var.Value] = t.Coef
+			keys = append(keys, t.Var.Value)
+		}
+		for _, q := range e.Quad {
+			k := q.Var1.Value + " * " + q.Var2.Value
+			m[k] = q.Coef
+			keys = append(keys, k)
+		}
+		return m, keys
+	}
+	am, akeys := coefs(a)
+	bm, bkeys := coefs(b)
+	seen := make(map[string]bool)
+	for _, k := range append(akeys, bkeys...) {
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if am[k] != bm[k] {
+			changes = append(changes, Change{Kind: Changed, What: what, Name: name, Detail: "coefficient of " + k,
+				Old: formatNum(am[k]), New: formatNum(bm[k]), Pos: pos})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,326 @@
+package lp
+
+import (
+	"strings"
+)
+
+// A Document is the text of a model that is edited in place,
+// as in an editor, along with the model it parses to.
+//
+// LP files are parsed one section at a time, and after an edit only
+// the sections that it touched are parsed again; the others are reused,
+// with their positions moved if the edit added or removed lines.
+// Other formats are parsed again as a whole.
+type Document struct {
+	name   string
+	format Format
+	limits Limits
+	lines  []string
+
+	chunks []*chunk // LP files only
+	model  *LP      // cached result of Model
+	err    error
+}
+
+// A chunk is a section of an LP file, starting at its header,
+// parsed with positions relative to its first line.
+type chunk struct {
+	start, end int // lines of the document, 0-based and exclusive
+	lp         *LP
+	errs       ErrorList
+}
+
+// NewDocument returns a document holding text,
+// a model in format f called name.
+func NewDocument(name string, f Format, text string) *Document {
+	d := &Document{name: name, format: f, limits: DefaultLimits}
+	d.SetText(text)
+	return d
+}
+
+// SetLimits sets the limits that d's text is parsed with,
+// which are DefaultLimits unless set.
+func (d *Document) SetLimits(lim Limits) {
+	d.limits = lim
+	d.chunks = nil
+	d.model, d.err = nil, nil
+}
+
+// Text returns the current text of d.
+func (d *Document) Text() string { return strings.Join(d.lines, "\n") }
+
+// Lines returns the lines of d without their line endings.
+// The caller must not modify the slice.
+func (d *Document) Lines() []string { return d.lines }
+
+// SetText replaces the text of d.
+func (d *Document) SetText(text string) {
+	d.lines = splitLines(text)
+	d.chunks = nil
+	d.model, d.err = nil, nil
+}
+
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines
+}
+
+// Edit replaces the text between start and end with text.
+// Only the Line and Col fields of start and end are used;
+// like all positions, they are 1-based, and Col counts bytes.
+func (d *Document) Edit(start, end Pos, text string) {
+	clampLine := func(n int32) int {
+		if n < 1 {
+			return 0
+		}
+		if int(n) > len(d.lines) {
+			return len(d.lines) - 1
+		}
+		return int(n) - 1
+	}
+	clampCol := func(line int, c int32) int {
+		if c < 1 {
+			return 0
+		}
+		if int(c)-1 > len(d.lines[line]) {
+			return len(d.lines[line])
+		}
+		return int(c) - 1
+	}
+	sl, el := clampLine(start.Line), clampLine(end.Line)
+	if el < sl {
+		sl, el = el, sl
+	}
+	sc, ec := clampCol(sl, start.Col), clampCol(el, end.Col)
+	if sl == el && ec < sc {
+		sc, ec = ec, sc
+	}
+	repl := splitLines(d.lines[sl][:sc] + text + d.lines[el][ec:])
+
+	lines := make([]string, 0, len(d.lines)-(el-sl+1)+len(repl))
+	lines = append(lines, d.lines[:sl]...)
+	lines = append(lines, repl...)
+	lines = append(lines, d.lines[el+1:]...)
+	d.lines = lines
+	d.model, d.err = nil, nil
+
+	// Keep the chunks that are entirely before or after the edit,
+	// which replaced lines sl through el with len(repl) lines.
+	delta := len(repl) - (el - sl + 1)
+	var kept []*chunk
+	for _, c := range d.chunks {
+		switch {
+		case c.end <= sl:
+			kept = append(kept, c)
+		case c.start > el:
+			c.start += delta
+			c.end += delta
+			kept = append(kept, c)
+		}
+	}
+	d.chunks = kept
+}
+
+// Model returns the model in d, parsing the parts that changed
+// since the last call. As with ParseFormat, syntax errors are returned
+// in an ErrorList along with the parts of the model that could be parsed.
+func (d *Document) Model() (*LP, error) {
+	if d.model != nil {
+		return d.model, d.err
+	}
+	if d.format != FormatLP && d.format != FormatGLPK {
+		d.model, d.err = ParseFormatLimits(d.name, strings.NewReader(d.Text()), d.format, d.limits)
+		return d.model, d.err
+	}
+
+	// Reuse the chunks whose lines are still a section.
+	old := make(map[[2]int]*chunk)
+	for _, c := range d.chunks {
+		old[[2]int{c.start, c.end}] = c
+	}
+	d.chunks = d.chunks[:0]
+	bounds := sectionStarts(d.lines)
+	for i, start := range bounds {
+		end := len(d.lines)
+		if i+1 < len(bounds) {
+			end = bounds[i+1]
+		}
+		c := old[[2]int{start, end}]
+		if c == nil {
+			c = &chunk{start: start, end: end}
+			text := strings.Join(d.lines[start:end], "\n")
+			// A chunk can't fail to read.
+			m, err := parseLP(d.name, strings.NewReader(text), false, d.limits)
+			c.lp = m
+			if errs, ok := err.(ErrorList); ok {
+				c.errs = errs
+			}
+		}
+		d.chunks = append(d.chunks, c)
+	}
+
+	lp := new(LP)
+	var errs ErrorList
+	for _, c := range d.chunks {
+		delta := int32(c.start)
+		lp.appendShifted(c.lp, delta)
+		for _, e := range c.errs {
+			e2 := *e
+			e2.Pos = e.Pos.shift(delta)
+			errs = append(errs, &e2)
+		}
+	}
+	if d.format == FormatGLPK || d.isGLPK() {
+		declareImplicit(lp)
+	}
+	d.model, d.err = lp, errs.err()
+	return d.model, d.err
+}
+
+// isGLPK reports whether the first line that is not blank
+// is GLPK's header comment.
+func (d *Document) isGLPK() bool {
+	for _, l := range d.lines {
+		if t := strings.TrimSpace(l); t != "" {
+			return isGLPKHeader(t)
+		}
+	}
+	return false
+}
+
+// sectionStarts returns the indexes of the lines that start sections
+// as parseLP sees them, preceded by 0 for any lines before the first.
+func sectionStarts(lines []string) []int {
+	starts := []int{0}
+	kind := secNone
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "\\lpvet:") {
+			t = strings.TrimSpace(strings.TrimPrefix(t, "\\lpvet:"))
+		}
+		h, ok := sectionHeader(t)
+		if kw, near := misspelledHeader(t, kind); !ok && near {
+			h, ok = sectionHeader(kw)
+		}
+		if !ok {
+			continue
+		}
+		kind = h.kind
+		if i > 0 {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func (p Pos) shift(delta int32) Pos {
+	if p.Line > 0 {
+		p.Line += delta
+	}
+	return p
+}
+
+func (s Symbol) shift(delta int32) Symbol {
+	s.Pos = s.Pos.shift(delta)
+	return s
+}
+
+func (e Expr) shift(delta int32) Expr {
+	e2 := Expr{Constant: e.Constant}
+	for _, t := range e.Terms {
+		e2.Terms = append(e2.Terms, Term{Coef: t.Coef, Var: t.Var.shift(delta)})
+	}
+	for _, q := range e.Quad {
+		e2.Quad = append(e2.Quad, QuadTerm{Coef: q.Coef, Var1: q.Var1.shift(delta), Var2: q.Var2.shift(delta)})
+	}
+	return e2
+}
+
+func (o *Objective) shift(delta int32) *Objective {
+	o2 := *o
+	o2.Pos = o.Pos.shift(delta)
+	o2.Expr = o.Expr.shift(delta)
+	return &o2
+}
+
+// appendShifted adds the contents of src to lp,
+// with their lines moved down by delta.
+func (lp *LP) appendShifted(src *LP, delta int32) {
+	dsts, srcs := lp.sections(), src.sections()
+	dsts = append(dsts, &lp.RowNames)
+	srcs = append(srcs, &src.RowNames)
+	for i, sec := range srcs {
+		for _, sym := range sec.Syms() {
+			dsts[i].AddSym(sym.shift(delta))
+		}
+	}
+	for _, h := range src.Headers {
+		lp.Headers = append(lp.Headers, h.shift(delta))
+	}
+
+	var first *Objective // copy of src.Obj
+	for _, o := range src.MultiObj {
+		o2 := o.shift(delta)
+		if o == src.Obj {
+			first = o2
+		}
+		lp.MultiObj = append(lp.MultiObj, o2)
+	}
+	if src.Obj != nil && first == nil {
+		first = src.Obj.shift(delta)
+	}
+	if lp.Obj == nil {
+		lp.Obj = first
+	}
+
+	for _, c := range src.Rows {
+		c2 := *c
+		c2.Pos = c.Pos.shift(delta)
+		c2.LHS = c.LHS.shift(delta)
+		c2.RHS = c.RHS.shift(delta)
+		if c.Indicator != nil {
+			ind := *c.Indicator
+			ind.Var = ind.Var.shift(delta)
+			c2.Indicator = &ind
+		}
+		lp.Rows = append(lp.Rows, &c2)
+	}
+	for _, b := range src.VarBounds {
+		b2 := *b
+		b2.Var = b.Var.shift(delta)
+		b2.Pos = b.Pos.shift(delta)
+		lp.VarBounds = append(lp.VarBounds, &b2)
+	}
+	for _, s := range src.SOS {
+		s2 := *s
+		s2.Pos = s.Pos.shift(delta)
+		s2.Members = nil
+		for _, m := range s.Members {
+			s2.Members = append(s2.Members, SOSMember{Var: m.Var.shift(delta), Weight: m.Weight})
+		}
+		lp.SOS = append(lp.SOS, &s2)
+	}
+	for _, g := range src.GenCons {
+		g2 := *g
+		g2.Pos = g.Pos.shift(delta)
+		g2.Result = g.Result.shift(delta)
+		g2.Args = nil
+		for _, a := range g.Args {
+			g2.Args = append(g2.Args, a.shift(delta))
+		}
+		lp.GenCons = append(lp.GenCons, &g2)
+	}
+	for _, pw := range src.PWLObjs {
+		pw2 := *pw
+		pw2.Pos = pw.Pos.shift(delta)
+		pw2.Var = pw.Var.shift(delta)
+		lp.PWLObjs = append(lp.PWLObjs, &pw2)
+	}
+	for _, ign := range src.Ignores {
+		ign.Pos = ign.Pos.shift(delta)
+		lp.Ignores = append(lp.Ignores, ign)
+	}
+}
@@ -0,0 +1,64 @@
+package lp
+
+import "fmt"
+
+// A SyntaxError is a syntax error in a model file.
+type SyntaxError struct {
+	Pos   Pos
+	Check string // ID of the check, see LookupCheck
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s [%s]", e.Pos, e.Msg, e.Check)
+}
+
+// Diagnostic returns e as an error diagnostic.
+func (e *SyntaxError) Diagnostic() Diagnostic {
+	return Diagnostic{Pos: e.Pos, Severity: Error, Check: e.Check, Message: e.Msg}
+}
+
+func errorAt(pos Pos, format string, args ...interface{}) error {
+	return errorFor(checkSyntax, pos, format, args...)
+}
+
+func errorFor(check string, pos Pos, format string, args ...interface{}) error {
+	return &SyntaxError{Pos: pos, Check: check, Msg: fmt.Sprintf(format, args...)}
+}
+
+// An ErrorList is a list of syntax errors in the order they were found.
+//
+// The parsers recover from syntax errors by skipping the offending
+// line or token, so a file with errors yields an ErrorList holding
+// all of them along with the parts of the model that could be parsed.
+type ErrorList []*SyntaxError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// add appends err to l.
+func (l *ErrorList) add(err error) {
+	switch err := err.(type) {
+	case *SyntaxError:
+		*l = append(*l, err)
+	case ErrorList:
+		*l = append(*l, err...)
+	default:
+		*l = append(*l, &SyntaxError{Check: checkSyntax, Msg: err.Error()})
+	}
+}
+
+// err returns l, or nil if l is empty.
+func (l ErrorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,329 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Default tolerances of CheckSolution.
+const (
+	DefaultFeasTol = 1e-6 // of constraints and bounds
+	DefaultObjTol  = 1e-6 // of the objective value, relative to it
+	DefaultIntTol  = 1e-5 // of the values of integer variables
+)
+
+// CheckOptions configures CheckSolution.
+type CheckOptions struct {
+	FeasTol float64 // absolute tolerance of constraints and bounds; 0 means DefaultFeasTol
+	ObjTol  float64 // relative tolerance of the objective value; 0 means DefaultObjTol
+	IntTol  float64 // absolute tolerance of integrality; 0 means DefaultIntTol
+}
+
+// A Violation is a part of a model that a solution does not satisfy.
+type Violation struct {
+	Kind   string // constraint, bound, semi-continuity, integrality, indicator, sos, general, or objective
+	Name   string // of the constraint or variable
+	Pos    Pos
+	Value  float64 // of the constraint's expression or the variable
+	Limit  float64 // the limit that Value exceeds
+	Amount float64 // how far the solution is from satisfying it
+	Detail string  // a description of the violation
+}
+
+// A Slack is how far a constraint is from its nearer limit in a solution.
+type Slack struct {
+	Name  string
+	Pos   Pos
+	Value float64 // of the constraint's expression
+	Limit float64 // the nearer limit
+	Slack float64 // the distance to Limit, negative if it is violated
+}
+
+// A SolutionCheck is the result of CheckSolution.
+type SolutionCheck struct {
+	Violations []Violation // in the order of the model
+	Missing    []string    // variables without a value in a solution that is not sparse, which are taken to be 0
+	Unknown    []string    // variables of the solution that are not in the model, sorted
+	Slacks     []Slack     // of the constraints that apply, in order
+
+	// Objective is the value of the first objective of the model
+	// computed from the solution, if HasObjective is set.
+	Objective    float64
+	HasObjective bool
+}
+
+// CheckSolution reports the constraints, bounds, special ordered sets,
+// and general constraints of lp that sol violates by more than the tolerance,
+// and the integer variables whose values are not integers.
+// Indicator constraints only apply when their variable rounds to its value.
+// It also computes the objective value, and reports it as violated
+// if it differs from the one the solution gives.
+func CheckSolution(lp *LP, sol *Solution, opts CheckOptions) *SolutionCheck {
+	tol := opts.FeasTol
+	if tol == 0 {
+		tol = DefaultFeasTol
+	}
+	objTol := opts.ObjTol
+	if objTol == 0 {
+		objTol = DefaultObjTol
+	}
+	intTol := opts.IntTol
+	if intTol == 0 {
+		intTol = DefaultIntTol
+	}
+	val := func(name string) float64 { return sol.Values[name] }
+	eval := func(e Expr) float64 {
+		v := e.Constant
+		for _, t := range e.Terms {
+			v += t.Coef * val(t.Var.Value)
+		}
+		for _, q := range e.Quad {
+			v += q.Coef * val(q.Var1.Value) * val(q.Var2.Value)
+		}
+		return v
+	}
+	chk := new(SolutionCheck)
+	add := func(v Violation) {
+		if v.Amount > tol {
+			chk.Violations = append(chk.Violations, v)
+		}
+	}
+	// outside returns the violation of lo <= x <= hi, if any.
+	outside := func(x, lo, hi float64) (limit, amount float64, op string) {
+		switch {
+		case x < lo:
+			return lo, lo - x, "<"
+		case x > hi:
+			return hi, x - hi, ">"
+		}
+		return 0, 0, ""
+	}
+
+	objName, rowNames := lp.rowNames()
+	if objs := lp.objectives(); len(objs) > 0 {
+		// Multi-objective solvers report the value of the first objective.
+		o := objs[0]
+		chk.Objective, chk.HasObjective = eval(o.Expr), true
+		for _, pw := range lp.PWLObjs {
+			chk.Objective += pwlValue(pw.Points, val(pw.Var.Value))
+		}
+		if diff := math.Abs(chk.Objective - sol.Objective); sol.HasObjective && diff > objTol*math.Max(1, math.Abs(sol.Objective)) {
+			chk.Violations = append(chk.Violations, Violation{
+				Kind: "objective", Name: objName, Pos: o.Pos, Value: chk.Objective, Limit: sol.Objective, Amount: diff,
+				Detail: fmt.Sprintf("the solution's values give %s, but its objective value is %s", formatNum(chk.Objective), formatNum(sol.Objective)),
+			})
+		}
+	}
+
+	for i, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		x := eval(e)
+		active := true
+		if ind := c.Indicator; ind != nil {
+			active = math.Round(val(ind.Var.Value)) == float64(ind.Value)
+			if !active && ind.Equiv {
+				// The converse: if the constraint holds, so must the indicator.
+				if _, amount, _ := outside(x, lo, hi); amount <= tol {
+					b := val(ind.Var.Value)
+					add(Violation{
+						Kind: "indicator", Name: rowNames[i], Pos: c.Pos, Value: b, Limit: float64(ind.Value),
+						Amount: math.Abs(b - float64(ind.Value)),
+						Detail: fmt.Sprintf("%s = %s, but the constraint holds", ind.Var.Value, formatNum(b)),
+					})
+				}
+			}
+		}
+		if !active {
+			continue
+		}
+		sl := Slack{Name: rowNames[i], Pos: c.Pos, Value: x, Limit: lo, Slack: x - lo}
+		if hi-x < sl.Slack {
+			sl.Limit, sl.Slack = hi, hi-x
+		}
+		chk.Slacks = append(chk.Slacks, sl)
+		if limit, amount, op := outside(x, lo, hi); op != "" {
+			add(Violation{
+				Kind: "constraint", Name: rowNames[i], Pos: c.Pos, Value: x, Limit: limit, Amount: amount,
+				Detail: fmt.Sprintf("%s %s %s", formatNum(x), op, formatNum(limit)),
+			})
+		}
+	}
+
+	boundPos := make(map[string]Pos)
+	for _, b := range lp.VarBounds {
+		boundPos[b.Var.Value] = b.Pos
+	}
+	declPos := make(map[string]Pos)
+	for _, sec := range []*Section{&lp.GeneralVars, &lp.BinaryVars, &lp.SemiContVars, &lp.SemiIntVars} {
+		for _, sym := range sec.Syms() {
+			declPos[sym.Value] = sym.Pos
+		}
+	}
+	inModel := make(map[string]bool)
+	for _, v := range Variables(lp) {
+		inModel[v.Name] = true
+		x, ok := sol.Values[v.Name]
+		if !ok && !sol.Sparse {
+			chk.Missing = append(chk.Missing, v.Name)
+		}
+		semi := v.Type == "semi-continuous" || v.Type == "semi-integer"
+		if semi && math.Abs(x) <= tol {
+			continue
+		}
+		if limit, amount, op := outside(x, v.Lo, v.Hi); op != "" {
+			pos, ok := boundPos[v.Name]
+			if !ok {
+				pos = v.Pos
+			}
+			viol := Violation{
+				Kind: "bound", Name: v.Name, Pos: pos, Value: x, Limit: limit, Amount: amount,
+				Detail: fmt.Sprintf("%s = %s %s %s", v.Name, formatNum(x), op, formatNum(limit)),
+			}
+			if semi {
+				viol.Kind, viol.Pos = "semi-continuity", declPos[v.Name]
+				viol.Amount = math.Min(amount, math.Abs(x))
+				viol.Detail = fmt.Sprintf("%s = %s is neither 0 nor in %s", v.Name, formatNum(x), rangeText(v.Lo, v.Hi))
+			}
+			add(viol)
+		}
+		if v.Type != "continuous" && v.Type != "semi-continuous" {
+			if frac := math.Abs(x - math.Round(x)); frac > intTol {
+				chk.Violations = append(chk.Violations, Violation{
+					Kind: "integrality", Name: v.Name, Pos: declPos[v.Name], Value: x, Limit: math.Round(x), Amount: frac,
+					Detail: fmt.Sprintf("%s %s = %s is not an integer", v.Type, v.Name, formatNum(x)),
+				})
+			}
+		}
+	}
+
+	for i, s := range lp.SOS {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("sos%d", i+1)
+		}
+		members := append([]SOSMember(nil), s.Members...)
+		sort.SliceStable(members, func(i, j int) bool { return members[i].Weight < members[j].Weight })
+		// The members outside the best window of Type consecutive
+		// members must be 0.
+		total, best, nonzero := 0.0, 0.0, 0
+		for i := range members {
+			x := math.Abs(val(members[i].Var.Value))
+			total += x
+			if x > tol {
+				nonzero++
+			}
+			window := 0.0
+			for j := i; j < i+s.Type && j < len(members); j++ {
+				window += math.Abs(val(members[j].Var.Value))
+			}
+			best = math.Max(best, window)
+		}
+		var names []string
+		for _, m := range members {
+			if math.Abs(val(m.Var.Value)) > tol {
+				names = append(names, m.Var.Value)
+			}
+		}
+		detail := fmt.Sprintf("%d nonzero members (%s), but at most %d may be", nonzero, strings.Join(names, ", "), s.Type)
+		if nonzero <= s.Type {
+			detail = fmt.Sprintf("nonzero members %s are not adjacent", strings.Join(names, " and "))
+		}
+		add(Violation{
+			Kind: "sos", Name: name, Pos: s.Pos, Value: float64(nonzero), Limit: float64(s.Type),
+			Amount: total - best, Detail: detail,
+		})
+	}
+
+	for i, g := range lp.GenCons {
+		name := g.Name
+		if name == "" {
+			name = fmt.Sprintf("gc%d", i+1)
+		}
+		r := val(g.Result.Value)
+		want, ok := genValue(g, val)
+		if !ok {
+			continue
+		}
+		add(Violation{
+			Kind: "general", Name: name, Pos: g.Pos, Value: r, Limit: want, Amount: math.Abs(r - want),
+			Detail: fmt.Sprintf("%s = %s, but should be %s by %s", g.Result.Value, formatNum(r), formatNum(want), genConText(g)),
+		})
+	}
+
+	for name := range sol.Values {
+		if !inModel[name] {
+			chk.Unknown = append(chk.Unknown, name)
+		}
+	}
+	sort.Strings(chk.Unknown)
+	return chk
+}
+
+// genValue returns the value the result of g must take
+// given the values of its arguments.
+func genValue(g *GenConstraint, val func(string) float64) (float64, bool) {
+	switch g.Func {
+	case "MAX", "MIN":
+		var xs []float64
+		for _, a := range g.Args {
+			xs = append(xs, val(a.Value))
+		}
+		xs = append(xs, g.Constants...)
+		if len(xs) == 0 {
+			return 0, false
+		}
+		v := xs[0]
+		for _, x := range xs[1:] {
+			if g.Func == "MAX" {
+				v = math.Max(v, x)
+			} else {
+				v = math.Min(v, x)
+			}
+		}
+		return v, true
+	case "ABS":
+		if len(g.Args) != 1 {
+			return 0, false
+		}
+		return math.Abs(val(g.Args[0].Value)), true
+	case "AND", "OR":
+		and := g.Func == "AND"
+		v := and
+		for _, a := range g.Args {
+			one := math.Round(val(a.Value)) == 1
+			if and {
+				v = v && one
+			} else {
+				v = v || one
+			}
+		}
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// pwlValue returns the value at x of the piecewise-linear function
+// through points, which are sorted by x,
+// extending its first and last segments beyond them.
+func pwlValue(points [][2]float64, x float64) float64 {
+	switch len(points) {
+	case 0:
+		return 0
+	case 1:
+		return points[0][1]
+	}
+	i := sort.Search(len(points)-1, func(i int) bool { return points[i+1][0] >= x })
+	if i == len(points)-1 {
+		i--
+	}
+	x0, y0, x1, y1 := points[i][0], points[i][1], points[i+1][0], points[i+1][1]
+	if x1 == x0 {
+		return y1
+	}
+	return y0 + (x-x0)*(y1-y0)/(x1-x0)
+}
@@ -0,0 +1,150 @@
+package lp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const feasibleModel = `Minimize
+ obj: x + 2 y + z
+Subject To
+ c1: x + y >= 2
+ c2: x - y <= 1
+ c3: -1 <= y - z <= 5
+Bounds
+ x <= 3
+General
+ y
+SOS
+ s1: S1:: x:1 z:2
+End
+`
+
+func TestCheckSolution(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		sol  string
+		want []string // violations as kind name: detail
+	}{
+		{"feasible", "# Objective value = 3\nx 1\ny 1\nz 0\n", nil},
+		{"constraint", "x 0\ny 1\nz 0\n", []string{"constraint c1: 1 < 2"}},
+		{"range", "x 2\ny 1\nz 3\n", []string{"constraint c3: -2 < -1", "sos s1: 2 nonzero members (x, z), but at most 1 may be"}},
+		{"bound", "x 4\ny 3\nz 0\n", []string{"bound x: x = 4 > 3"}},
+		{"integrality", "x 1\ny 1.5\nz 0\n", []string{"integrality y: general y = 1.5 is not an integer"}},
+		{"within tolerance", "x 1\ny 1.0000001\nz 0\n", nil},
+		{"objective", "# Objective value = 7\nx 1\ny 1\nz 0\n", []string{"objective obj: the solution's values give 3, but its objective value is 7"}},
+	} {
+		m, err := ParseReader("m.lp", strings.NewReader(feasibleModel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sol, err := ReadSolution("m.sol", strings.NewReader(tt.sol))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, v := range CheckSolution(m, sol, CheckOptions{}).Violations {
+			got = append(got, fmt.Sprintf("%s %s: %s", v.Kind, v.Name, v.Detail))
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got violations\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestCheckSolutionNames(t *testing.T) {
+	m, err := ParseReader("m.lp", strings.NewReader(feasibleModel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol, err := ReadSolution("m.sol", strings.NewReader("x 1\ny 1\nw 2\nv 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chk := CheckSolution(m, sol, CheckOptions{})
+	if got := strings.Join(chk.Missing, " "); got != "z" {
+		t.Errorf("got missing %q, want %q", got, "z")
+	}
+	if got := strings.Join(chk.Unknown, " "); got != "v w" {
+		t.Errorf("got unknown %q, want %q", got, "v w")
+	}
+	if !chk.HasObjective || chk.Objective != 3 {
+		t.Errorf("got objective %v (%v), want 3", chk.Objective, chk.HasObjective)
+	}
+}
+
+func TestReadCPLEXSolution(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		sol  string
+		want string // values as name=value, sorted
+		err  string
+	}{
+		{
+			"solution",
+			`<?xml version = "1.0" encoding="UTF-8" standalone="yes"?>
+<CPLEXSolution version="1.2">
+ <header problemName="m.lp" objectiveValue="3" solutionStatusString="integer optimal solution"/>
+ <variables>
+  <variable name="x" index="0" value="1"/>
+  <variable name="y" index="1" value="1"/>
+  <variable name="z" index="2" value="0"/>
+ </variables>
+</CPLEXSolution>
+`,
+			"obj=3 x=1 y=1 z=0",
+			"",
+		},
+		{
+			"first of several",
+			`<CPLEXSolutions><CPLEXSolution><header objectiveValue="1"/><variables><variable name="x" value="1"/></variables></CPLEXSolution>
+<CPLEXSolution><header objectiveValue="2"/><variables><variable name="x" value="2"/></variables></CPLEXSolution></CPLEXSolutions>`,
+			"obj=1 x=1",
+			"",
+		},
+		{
+			"bad value",
+			`<CPLEXSolution><variables><variable name="x" value="one"/></variables></CPLEXSolution>`,
+			"",
+			`m.sol: value "one" of x is not a number`,
+		},
+		{
+			"no solution",
+			`<other/>`,
+			"",
+			"m.sol: no CPLEXSolution element",
+		},
+		{
+			"empty",
+			" \n",
+			"",
+			"m.sol: empty solution",
+		},
+	} {
+		sol, err := ReadSolution("m.sol", strings.NewReader(tt.sol))
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		if sol.HasObjective {
+			got = append(got, "obj="+formatNum(sol.Objective))
+		}
+		for _, name := range []string{"x", "y", "z"} {
+			if v, ok := sol.Values[name]; ok {
+				got = append(got, name+"="+formatNum(v))
+			}
+		}
+		if s := strings.Join(got, " "); s != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, s, tt.want)
+		}
+	}
+}
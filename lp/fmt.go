@@ -0,0 +1,373 @@
+package lp
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// maxLabelAlign is the longest label that statements are aligned to.
+// Longer labels are followed by a single space instead.
+const maxLabelAlign = 30
+
+// FormatSource formats src, an LP file called name, in a standard style:
+// section headers are spelled the same way, statements are indented
+// by a space, with their expressions aligned after any labels,
+// operators are surrounded by single spaces, and runs of blank lines
+// are collapsed. Lines longer than MaxLineLen are broken between terms.
+// Comments are kept, and statements keep their line breaks.
+//
+// Files with syntax errors are not formatted;
+// the errors are returned in an ErrorList.
+func FormatSource(name string, src []byte) ([]byte, error) {
+	m, err := ParseReader(name, bytes.NewReader(src))
+	if errs, ok := err.(ErrorList); ok {
+		// Formatting fixes long lines.
+		var rest ErrorList
+		for _, e := range errs {
+			if e.Check != checkLineTooLong {
+				rest = append(rest, e)
+			}
+		}
+		err = rest.err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formatter{starts: stmtStarts(m)}
+	f.scan(splitLines(string(src)))
+	var b bytes.Buffer
+	for _, l := range f.print() {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// stmtStarts returns the lines on which the statements of m start.
+func stmtStarts(m *LP) map[int32]bool {
+	starts := make(map[int32]bool)
+	if m.Obj != nil {
+		starts[m.Obj.Pos.Line] = true
+	}
+	for _, o := range m.MultiObj {
+		starts[o.Pos.Line] = true
+	}
+	for _, c := range m.Rows {
+		starts[c.Pos.Line] = true
+	}
+	for _, b := range m.VarBounds {
+		starts[b.Pos.Line] = true
+	}
+	for _, s := range m.SOS {
+		starts[s.Pos.Line] = true
+	}
+	for _, g := range m.GenCons {
+		starts[g.Pos.Line] = true
+	}
+	for _, pw := range m.PWLObjs {
+		starts[pw.Pos.Line] = true
+	}
+	return starts
+}
+
+type lineKind int
+
+const (
+	lineBlank lineKind = iota
+	lineComment
+	lineHeader
+	lineStmt // starts a statement
+	lineCont // continues a statement
+	lineDecl // names in a declaration section
+)
+
+// A fmtLine is a line of an LP file, split up for formatting.
+type fmtLine struct {
+	kind    lineKind
+	sec     int // index of the section, for alignment
+	prefix  bool
+	text    string // header or comment line
+	label   string
+	toks    []token
+	sk      secKind
+	comment string // trailing comment
+}
+
+type formatter struct {
+	starts map[int32]bool
+	lines  []fmtLine
+	labels []int // longest label in each section
+}
+
+// scan splits the lines of the file, which has no syntax errors.
+func (f *formatter) scan(lines []string) {
+	kind := secNone
+	f.labels = []int{0}
+	for i, raw := range lines {
+		n := int32(i + 1)
+		t := strings.TrimSpace(raw)
+		if t == "" {
+			f.add(fmtLine{kind: lineBlank})
+			continue
+		}
+		prefix := false
+		if strings.HasPrefix(t, "\\") {
+			if _, ok, _ := ignoreDirective(t[1:], Pos{}); ok || !strings.HasPrefix(t, "\\lpvet:") {
+				f.add(fmtLine{kind: lineComment, text: t})
+				continue
+			}
+			prefix = true
+			t = strings.TrimSpace(t[len("\\lpvet:"):])
+		}
+		if h, ok := sectionHeader(t); ok {
+			kind = h.kind
+			f.labels = append(f.labels, 0)
+			f.add(fmtLine{kind: lineHeader, prefix: prefix, text: headerText(h)})
+			if t = h.rest; t == "" {
+				continue
+			}
+		}
+		l := fmtLine{prefix: prefix, sk: kind}
+		if j := strings.IndexByte(t, '\\'); j >= 0 {
+			l.comment = strings.TrimSpace(t[j:])
+			t = t[:j]
+		}
+		punct := ""
+		switch kind {
+		case secObjective, secConstraints:
+			punct = "[]^*/"
+		case secGenCons, secPWLObj:
+			punct = "(),"
+		}
+		// The file parsed, so its lines lex.
+		l.toks, _ = lex(t, Pos{}, punct)
+		switch {
+		case len(l.toks) == 0:
+			l.kind = lineComment
+			l.text = l.comment
+			l.comment = ""
+		case kind == secGeneral || kind == secBinary || kind == secSemiCont || kind == secSemiInt || kind == secCustomCont:
+			l.kind = lineDecl
+		case f.starts[n]:
+			l.kind = lineStmt
+			if hasLabel(l.toks, kind) {
+				l.label, l.toks = l.toks[0].text, l.toks[2:]
+				if len(l.label) <= maxLabelAlign && len(l.label) > f.labels[len(f.labels)-1] {
+					f.labels[len(f.labels)-1] = len(l.label)
+				}
+			}
+		default:
+			l.kind = lineCont
+		}
+		f.add(l)
+	}
+}
+
+func (f *formatter) add(l fmtLine) {
+	l.sec = len(f.labels) - 1
+	f.lines = append(f.lines, l)
+}
+
+// hasLabel reports whether toks start with a "name:" label,
+// as the parser would read them in a section of the given kind.
+func hasLabel(toks []token, kind secKind) bool {
+	if len(toks) < 2 || toks[0].kind != tokIdent || toks[1].kind != tokColon {
+		return false
+	}
+	// In SOS sections, "x:1" and "x:-1" are members and "S1::" the type.
+	if kind != secSOS || len(toks) < 3 {
+		return true
+	}
+	switch toks[2].kind {
+	case tokColon, tokNum, tokPlus, tokMinus:
+		return false
+	}
+	return true
+}
+
+func headerText(h header) string {
+	switch h.kind {
+	case secObjective:
+		s := "Minimize"
+		if h.sense == Maximize {
+			s = "Maximize"
+		}
+		if h.multi {
+			s += " multi-objectives"
+		}
+		return s
+	case secConstraints:
+		return "Subject To"
+	case secBounds:
+		return "Bounds"
+	case secGeneral:
+		return "General"
+	case secBinary:
+		return "Binary"
+	case secSemiCont:
+		return "Semi-Continuous"
+	case secSemiInt:
+		return "Semi-Integer"
+	case secCustomCont:
+		return "Continuous"
+	case secGenCons:
+		return "General Constraints"
+	case secPWLObj:
+		return "PWLObj"
+	case secSOS:
+		switch h.sos {
+		case 1:
+			return "SOS1"
+		case 2:
+			return "SOS2"
+		}
+		return "SOS"
+	}
+	return "End"
+}
+
+func (f *formatter) print() []string {
+	var out []string
+	blank := false
+	for _, l := range f.lines {
+		if l.kind == lineBlank {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		width := f.labels[l.sec]
+		exprCol := 1 // where expressions start, after the indent and labels
+		if width > 0 {
+			exprCol += width + 2
+		}
+		var lead, body string
+		switch l.kind {
+		case lineComment:
+			out = append(out, l.text)
+			continue
+		case lineHeader:
+			if l.prefix {
+				out = append(out, "\\lpvet:"+l.text)
+			} else {
+				out = append(out, l.text)
+			}
+			continue
+		case lineDecl:
+			lead = " "
+		case lineStmt:
+			lead = strings.Repeat(" ", exprCol)
+			if l.label != "" {
+				lead = " " + l.label + ": "
+				if len(lead) < exprCol {
+					lead += strings.Repeat(" ", exprCol-len(lead))
+				}
+			}
+		case lineCont:
+			lead = strings.Repeat(" ", exprCol+2)
+		}
+		body = joinTokens(l.toks, l.sk, l.kind == lineStmt)
+		if l.prefix {
+			// The prefix takes the place of the indent.
+			lead = "\\lpvet: " + strings.TrimLeftFunc(lead, unicode.IsSpace)
+		}
+		cont := strings.Repeat(" ", exprCol+2)
+		if l.kind == lineDecl {
+			cont = " "
+		}
+		if l.prefix {
+			cont = "\\lpvet: " + strings.TrimLeftFunc(cont, unicode.IsSpace)
+		}
+		wrapped := wrapLine(strings.TrimRight(lead+body, " "), cont, MaxLineLen)
+		if l.comment != "" {
+			last := &wrapped[len(wrapped)-1]
+			if len(*last)+1+len(l.comment) <= MaxLineLen {
+				*last += " " + l.comment
+			} else {
+				wrapped = append([]string{l.comment}, wrapped...)
+			}
+		}
+		out = append(out, wrapped...)
+	}
+	return out
+}
+
+// joinTokens joins the tokens of a line, from a section of the given kind,
+// with single spaces around operators.
+// Signs at the start of an expression are joined to what follows,
+// as in "-3 x" and "-inf".
+func joinTokens(toks []token, kind secKind, start bool) string {
+	var b strings.Builder
+	unary := false // the last token was a sign joined to the next
+	for i, t := range toks {
+		text := t.text
+		switch {
+		case t.kind == tokRel:
+			text = t.rel.String()
+		case t.kind == tokIdent && kind == secBounds && isInf(text):
+			text = "inf"
+		case t.kind == tokIdent && kind == secBounds && strings.EqualFold(text, "free"):
+			text = "free"
+		}
+		space := i > 0 && !unary && t.kind != tokColon
+		if i > 0 && toks[i-1].kind == tokColon && kind == secSOS {
+			// "S1:: x:1 y:2"
+			space = i > 1 && toks[i-2].kind == tokColon
+		}
+		if space {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+
+		unary = false
+		if t.kind == tokPlus || t.kind == tokMinus {
+			var prev tokKind = -1
+			if i > 0 {
+				prev = toks[i-1].kind
+			}
+			switch prev {
+			case -1:
+				unary = start
+			case tokRel, tokColon, tokLBrack, tokLParen, tokComma, tokArrow:
+				unary = true
+			}
+			// A sign before a bracket reads better spaced.
+			if i+1 < len(toks) && (toks[i+1].kind == tokLBrack || toks[i+1].kind == tokLParen) {
+				unary = false
+			}
+		}
+	}
+	return b.String()
+}
+
+// wrapLine breaks line into lines no longer than max, between tokens,
+// preferably before a sign so that continuation lines read as terms.
+// Continuation lines start with indent.
+func wrapLine(line, indent string, max int) []string {
+	var out []string
+	for len(line) > max {
+		limit := max
+		brk := -1
+		for i := limit; i > len(indent); i-- {
+			if i < len(line) && line[i] == ' ' && i+1 < len(line) && line[i+1] != ' ' {
+				if (line[i+1] == '+' || line[i+1] == '-') && i > limit-max/2 {
+					brk = i
+					break
+				}
+				if brk < 0 {
+					brk = i
+				}
+			}
+		}
+		if brk < 0 {
+			break
+		}
+		out = append(out, line[:brk])
+		line = indent + line[brk+1:]
+	}
+	return append(out, line)
+}
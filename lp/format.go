@@ -0,0 +1,133 @@
+package lp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the file format of a model.
+type Format int
+
+const (
+	FormatLP      Format = iota // CPLEX LP
+	FormatMPS                   // fixed-format MPS
+	FormatFreeMPS               // free-format MPS
+	FormatLPSolve               // lp_solve LP
+	FormatGLPK                  // CPLEX LP as written by GLPK
+	FormatProto                 // protocol buffers, as in lpvet.proto
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatLP:
+		return "lp"
+	case FormatMPS:
+		return "mps"
+	case FormatFreeMPS:
+		return "freemps"
+	case FormatLPSolve:
+		return "lpsolve"
+	case FormatGLPK:
+		return "glpk"
+	case FormatProto:
+		return "proto"
+	}
+	return "unknown"
+}
+
+// ParseFormatName returns the Format named s.
+func ParseFormatName(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "lp":
+		return FormatLP, nil
+	case "mps":
+		return FormatMPS, nil
+	case "freemps":
+		return FormatFreeMPS, nil
+	case "lpsolve":
+		return FormatLPSolve, nil
+	case "glpk":
+		return FormatGLPK, nil
+	case "proto":
+		return FormatProto, nil
+	}
+	return 0, fmt.Errorf("unknown format %q", s)
+}
+
+// FormatFromPath guesses the format of a file from its extension.
+// Files that are not recognized are assumed to be LP files.
+//
+// MPS files are read as free-format since that also accepts
+// fixed-format files as long as names contain no spaces.
+func FormatFromPath(p string) Format {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".mps":
+		return FormatFreeMPS
+	case ".pb":
+		return FormatProto
+	}
+	return FormatLP
+}
+
+// Parse reads and parses the model at path p.
+// The format is chosen by FormatFromPath.
+func Parse(p string) (*LP, error) {
+	return ParseFile(p, FormatFromPath(p))
+}
+
+// ParseFile reads and parses the model at path p in format f.
+func ParseFile(p string, f Format) (*LP, error) {
+	r, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ParseFormat(p, r, f)
+}
+
+// ParseFormat parses a model in format f from r.
+// name is used in positions.
+//
+// If the model has syntax errors, ParseFormat returns
+// the parts that could be parsed along with an ErrorList.
+func ParseFormat(name string, r io.Reader, f Format) (*LP, error) {
+	return ParseFormatLimits(name, r, f, DefaultLimits)
+}
+
+// Limits are the longest lines and variable names
+// that the parsers of LP files accept, or 0 for no limit.
+type Limits struct {
+	MaxLineLen int
+	MaxVarLen  int
+
+	// NameChars are characters that names may contain
+	// in addition to those the dialect allows.
+	NameChars string
+}
+
+// DefaultLimits are CPLEX's limits, which ParseFormat applies.
+var DefaultLimits = Limits{MaxLineLen: MaxLineLen, MaxVarLen: MaxVarLen}
+
+// ParseFormatLimits is like ParseFormat, but reports the lines
+// and names in LP files that are longer than lim allows,
+// such as to apply the limits of a solver other than CPLEX.
+func ParseFormatLimits(name string, r io.Reader, f Format, lim Limits) (*LP, error) {
+	switch f {
+	case FormatLP:
+		return parseLP(name, r, false, lim)
+	case FormatMPS:
+		return ParseMPS(name, r)
+	case FormatFreeMPS:
+		return ParseFreeMPS(name, r)
+	case FormatLPSolve:
+		return parseLPSolve(name, r, lim)
+	case FormatGLPK:
+		return parseLP(name, r, true, lim)
+	case FormatProto:
+		return ReadProto(name, r)
+	}
+	return nil, fmt.Errorf("%s: unsupported format %v", name, f)
+}
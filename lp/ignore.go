@@ -0,0 +1,75 @@
+package lp
+
+import "strings"
+
+// An Ignore is an "lpvet:ignore" comment.
+// It suppresses diagnostics on its own line and, if it is
+// on a line by itself, the next, so it can either trail
+// the offending line or precede it.
+type Ignore struct {
+	Pos      Pos
+	Checks   []string // IDs of the suppressed checks, or nil for all
+	Trailing bool     // follows other text on its line
+}
+
+// ignoreDirective parses comment, the text of a comment
+// without its delimiters, as an lpvet:ignore directive.
+// The directive lists checks by ID or name,
+// separated by spaces or commas.
+func ignoreDirective(comment string, pos Pos) (ign Ignore, ok bool, err error) {
+	const prefix = "lpvet:ignore"
+	comment = strings.TrimSpace(comment)
+	if !strings.HasPrefix(comment, prefix) {
+		return Ignore{}, false, nil
+	}
+	rest := comment[len(prefix):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != ',' {
+		return Ignore{}, false, nil
+	}
+	ign.Pos = pos
+	for _, name := range strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		c, err := LookupCheck(name)
+		if err != nil {
+			return Ignore{}, false, errorAt(pos, "%v in lpvet:ignore", err)
+		}
+		ign.Checks = append(ign.Checks, c.ID)
+	}
+	return ign, true, nil
+}
+
+// addIgnore records comment if it is an lpvet:ignore directive
+// and reports whether it was one.
+// A trailing comment follows other text on its line.
+func (lp *LP) addIgnore(comment string, pos Pos, trailing bool, errs *ErrorList) bool {
+	ign, ok, err := ignoreDirective(comment, pos)
+	ign.Trailing = trailing
+	if err != nil {
+		errs.add(err)
+		return true
+	}
+	if ok {
+		lp.Ignores = append(lp.Ignores, ign)
+	}
+	return ok
+}
+
+// Ignored reports whether d is suppressed by an lpvet:ignore comment.
+func (lp *LP) Ignored(d Diagnostic) bool {
+	for _, ign := range lp.Ignores {
+		next := !ign.Trailing && d.Pos.Line == ign.Pos.Line+1
+		if ign.Pos.File != d.Pos.File || d.Pos.Line != ign.Pos.Line && !next {
+			continue
+		}
+		if ign.Checks == nil {
+			return true
+		}
+		for _, id := range ign.Checks {
+			if id == d.Check {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package lp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoredTrailing(t *testing.T) {
+	const model = `Minimize
+ obj: x
+Subject To
+ c1: x >= 1 \ lpvet:ignore LP007
+ c2: x >= 2
+\ lpvet:ignore LP007
+ c3: x >= 3
+End
+`
+	lp, err := ParseReader("m.lp", strings.NewReader(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		line int32
+		want bool
+	}{
+		{4, true},  // the line of a trailing comment
+		{5, false}, // the line after a trailing comment
+		{7, true},  // the line after a comment by itself
+		{8, false},
+	} {
+		d := Diagnostic{Pos: Pos{File: "m.lp", Line: tt.line, Col: 2}, Check: checkFreeBounded}
+		if got := lp.Ignored(d); got != tt.want {
+			t.Errorf("Ignored at line %d = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package lp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxIsoSteps limits how many guesses Isomorphism makes
+// when refinement alone cannot tell variables or constraints apart.
+const maxIsoSteps = 10000
+
+var errIsoTooHard = errors.New("the models are too symmetric to decide whether they are isomorphic")
+
+// Isomorphism tries to find a renaming of the variables and constraints
+// of a that makes it identical to b, so that models produced by
+// different versions of a generator can be compared.
+// It returns maps from the names of the variables and constraints of a
+// to those of b, with unnamed constraints named R1, R2, and so on
+// by position, or an error describing a difference between the models.
+//
+// Models are compared as Compare does, after renaming, except that
+// objectives are matched by position.
+func Isomorphism(a, b *LP) (vars, rows map[string]string, err error) {
+	ga, gb := newIsoGraph(a), newIsoGraph(b)
+	switch {
+	case len(ga.vars) != len(gb.vars):
+		return nil, nil, fmt.Errorf("the models have %d and %d variables", len(ga.vars), len(gb.vars))
+	case len(ga.rows) != len(gb.rows):
+		return nil, nil, fmt.Errorf("the models have %d and %d constraints", len(ga.rows), len(gb.rows))
+	case len(a.objectives()) != len(b.objectives()):
+		return nil, nil, fmt.Errorf("the models have %d and %d objectives", len(a.objectives()), len(b.objectives()))
+	}
+	for i, o := range a.objectives() {
+		if p := b.objectives()[i]; o.Sense != p.Sense {
+			return nil, nil, fmt.Errorf("objective %s is to %s, but %s is to %s", o.Name, o.Sense, p.Name, p.Sense)
+		}
+	}
+	initColors(ga, gb)
+	if err := mismatch(ga, gb, false); err != nil {
+		return nil, nil, err
+	}
+	steps := 0
+	if err := isoSearch(ga, gb, &steps); err != nil {
+		return nil, nil, err
+	}
+	vars = make(map[string]string)
+	for i, c := range ga.varColor {
+		vars[ga.vars[i].Value] = gb.vars[gb.varWithColor(c)].Value
+	}
+	rows = make(map[string]string)
+	for i, c := range ga.rowColor {
+		rows[ga.rows[i]] = gb.rows[gb.rowWithColor(c)]
+	}
+	return vars, rows, nil
+}
+
+// An isoGraph is the bipartite graph of the variables and constraints
+// of a model, with constraints linked to the variables in them.
+// Variables and constraints are colored by their properties,
+// and refinement splits colors by the colors of their neighbors.
+type isoGraph struct {
+	m *LP
+
+	vars    []Symbol
+	rows    []string
+	varNbrs [][]isoEdge // rows of each variable
+	rowNbrs [][]isoEdge // variables of each row
+
+	varDesc, rowDesc   []string // properties that the initial colors stand for
+	varColor, rowColor []int
+}
+
+// An isoEdge links a variable and a row, labeled by its coefficient.
+type isoEdge struct {
+	to    int
+	label string
+}
+
+func newIsoGraph(m *LP) *isoGraph {
+	g := &isoGraph{m: m}
+	cols, kinds := m.columns()
+	bounds := m.varBounds(kinds)
+	index := make(map[string]int)
+	objs := m.objectives()
+	objCoefs := make([][]string, len(cols))
+	for i, sym := range cols {
+		index[sym.Value] = i
+		objCoefs[i] = make([]string, len(objs))
+		for k := range objs {
+			objCoefs[i][k] = "0"
+		}
+	}
+	for k, o := range objs {
+		for _, t := range combine(o.Expr, Expr{}).Terms {
+			objCoefs[index[t.Var.Value]][k] = formatNum(t.Coef)
+		}
+	}
+	g.vars = cols
+	for i, sym := range cols {
+		lo, hi := boundsOf(sym.Value, bounds, kinds)
+		desc := kinds[sym.Value].String() + " with bounds " + rangeText(lo, hi)
+		if len(objs) > 0 {
+			desc += " and objective coefficient " + strings.Join(objCoefs[i], ", ")
+		}
+		g.varDesc = append(g.varDesc, desc)
+	}
+	g.varNbrs = make([][]isoEdge, len(cols))
+
+	_, g.rows = m.rowNames()
+	g.rowNbrs = make([][]isoEdge, len(m.Rows))
+	link := func(r int, v string, label string) {
+		g.rowNbrs[r] = append(g.rowNbrs[r], isoEdge{index[v], label})
+		g.varNbrs[index[v]] = append(g.varNbrs[index[v]], isoEdge{r, label})
+	}
+	for r, c := range m.Rows {
+		e, lo, hi := rowForm(c)
+		desc := limitsText(lo, hi, c.Ranged)
+		for _, t := range e.Terms {
+			link(r, t.Var.Value, formatNum(t.Coef))
+		}
+		for _, q := range e.Quad {
+			label := "[" + formatNum(q.Coef) + "]"
+			link(r, q.Var1.Value, label)
+			link(r, q.Var2.Value, label)
+		}
+		if ind := c.Indicator; ind != nil {
+			link(r, ind.Var.Value, "->")
+			desc = strings.TrimPrefix(indicatorText(ind), ind.Var.Value+" ") + " " + desc
+		}
+		g.rowDesc = append(g.rowDesc, desc)
+	}
+	return g
+}
+
+// initColors colors the variables and rows of both graphs by their properties.
+func initColors(gs ...*isoGraph) {
+	var varSigs, rowSigs [][]string
+	for _, g := range gs {
+		varSigs = append(varSigs, g.varDesc)
+		rowSigs = append(rowSigs, g.rowDesc)
+	}
+	for i, c := range recolor(varSigs) {
+		gs[i].varColor = c
+	}
+	for i, c := range recolor(rowSigs) {
+		gs[i].rowColor = c
+	}
+}
+
+// recolor numbers the signatures of each graph,
+// giving equal signatures the same color across graphs.
+func recolor(sigs [][]string) [][]int {
+	var all []string
+	for _, s := range sigs {
+		all = append(all, s...)
+	}
+	sort.Strings(all)
+	color := make(map[string]int)
+	for _, s := range all {
+		if _, ok := color[s]; !ok {
+			color[s] = len(color)
+		}
+	}
+	out := make([][]int, len(sigs))
+	for i, s := range sigs {
+		out[i] = make([]int, len(s))
+		for j, sig := range s {
+			out[i][j] = color[sig]
+		}
+	}
+	return out
+}
+
+// refine splits the colors of both graphs by the colors of their
+// neighbors until no more colors split, or until the graphs
+// differ in how many variables or rows have some color.
+func refine(ga, gb *isoGraph) error {
+	count := func() int {
+		seen := make(map[int]bool)
+		for _, g := range []*isoGraph{ga, gb} {
+			for _, c := range g.varColor {
+				seen[c] = true
+			}
+			for _, c := range g.rowColor {
+				seen[-c-1] = true
+			}
+		}
+		return len(seen)
+	}
+	sig := func(color int, nbrs []isoEdge, nbrColor []int) string {
+		parts := make([]string, len(nbrs))
+		for i, e := range nbrs {
+			parts[i] = e.label + "@" + strconv.Itoa(nbrColor[e.to])
+		}
+		sort.Strings(parts)
+		return strconv.Itoa(color) + ";" + strings.Join(parts, " ")
+	}
+	for n := count(); ; {
+		var varSigs, rowSigs [][]string
+		for _, g := range []*isoGraph{ga, gb} {
+			vs := make([]string, len(g.vars))
+			for i := range g.vars {
+				vs[i] = sig(g.varColor[i], g.varNbrs[i], g.rowColor)
+			}
+			rs := make([]string, len(g.rows))
+			for i := range g.rows {
+				rs[i] = sig(g.rowColor[i], g.rowNbrs[i], g.varColor)
+			}
+			varSigs = append(varSigs, vs)
+			rowSigs = append(rowSigs, rs)
+		}
+		vc, rc := recolor(varSigs), recolor(rowSigs)
+		ga.varColor, gb.varColor = vc[0], vc[1]
+		ga.rowColor, gb.rowColor = rc[0], rc[1]
+		if err := mismatch(ga, gb, true); err != nil {
+			return err
+		}
+		next := count()
+		if next == n {
+			return nil
+		}
+		n = next
+	}
+}
+
+// mismatch returns an error naming a variable or row
+// whose color is more common in one graph than in the other.
+// After refinement, the colors also stand for their neighbors.
+func mismatch(ga, gb *isoGraph, refined bool) error {
+	counts := func(colors []int) map[int]int {
+		n := make(map[int]int)
+		for _, c := range colors {
+			n[c]++
+		}
+		return n
+	}
+	// Rows first, since they split first when coefficients differ.
+	for _, x := range []struct {
+		what           string
+		ca, cb         []int
+		namesA, namesB func(int) string
+		descA, descB   []string
+		context        string
+	}{
+		{"constraint", ga.rowColor, gb.rowColor,
+			func(i int) string { return ga.rows[i] }, func(i int) string { return gb.rows[i] },
+			ga.rowDesc, gb.rowDesc, "with the same coefficients on corresponding variables"},
+		{"variable", ga.varColor, gb.varColor,
+			func(i int) string { return ga.vars[i].Value }, func(i int) string { return gb.vars[i].Value },
+			ga.varDesc, gb.varDesc, "in corresponding constraints"},
+	} {
+		suffix := ""
+		if refined {
+			suffix = " " + x.context
+		}
+		na, nb := counts(x.ca), counts(x.cb)
+		for i, c := range x.ca {
+			if na[c] > nb[c] {
+				return fmt.Errorf("%s %s (%s) of the first model has no counterpart in the second%s", x.what, x.namesA(i), x.descA[i], suffix)
+			}
+		}
+		for i, c := range x.cb {
+			if nb[c] > na[c] {
+				return fmt.Errorf("%s %s (%s) of the second model has no counterpart in the first%s", x.what, x.namesB(i), x.descB[i], suffix)
+			}
+		}
+	}
+	return nil
+}
+
+// isoSearch refines the colors of ga and gb until each variable and row
+// has a color of its own, guessing which ones correspond when
+// refinement alone cannot tell them apart.
+func isoSearch(ga, gb *isoGraph, steps *int) error {
+	if err := refine(ga, gb); err != nil {
+		return err
+	}
+	// Guess in the smallest class of variables or rows with the same color.
+	best, bestSize, isVar := -1, 0, false
+	for _, x := range []struct {
+		colors []int
+		isVar  bool
+	}{{ga.varColor, true}, {ga.rowColor, false}} {
+		size := make(map[int]int)
+		for _, c := range x.colors {
+			size[c]++
+		}
+		for _, c := range x.colors {
+			if size[c] > 1 && (best < 0 || size[c] < bestSize) {
+				best, bestSize, isVar = c, size[c], x.isVar
+			}
+		}
+	}
+	if best < 0 {
+		return verifyIso(ga, gb)
+	}
+
+	colorsA, colorsB := &ga.rowColor, &gb.rowColor
+	if isVar {
+		colorsA, colorsB = &ga.varColor, &gb.varColor
+	}
+	i := indexOf(*colorsA, best)
+	saved := [4][]int{ga.varColor, ga.rowColor, gb.varColor, gb.rowColor}
+	var first error
+	for j, c := range *colorsB {
+		if c != best {
+			continue
+		}
+		if *steps++; *steps > maxIsoSteps {
+			return errIsoTooHard
+		}
+		// Give the guessed pair a new color of its own.
+		fresh := 0
+		for _, cs := range saved {
+			for _, c := range cs {
+				if c >= fresh {
+					fresh = c + 1
+				}
+			}
+		}
+		ga.varColor, ga.rowColor = append([]int(nil), saved[0]...), append([]int(nil), saved[1]...)
+		gb.varColor, gb.rowColor = append([]int(nil), saved[2]...), append([]int(nil), saved[3]...)
+		(*colorsA)[i], (*colorsB)[j] = fresh, fresh
+		err := isoSearch(ga, gb, steps)
+		if err == nil || err == errIsoTooHard {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	ga.varColor, ga.rowColor, gb.varColor, gb.rowColor = saved[0], saved[1], saved[2], saved[3]
+	return first
+}
+
+func indexOf(colors []int, c int) int {
+	for i, x := range colors {
+		if x == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *isoGraph) varWithColor(c int) int { return indexOf(g.varColor, c) }
+func (g *isoGraph) rowWithColor(c int) int { return indexOf(g.rowColor, c) }
+
+// verifyIso checks that the correspondence given by the colors of ga and gb,
+// which differ for every variable and row, makes the models identical,
+// including the parts that the graphs leave out.
+func verifyIso(ga, gb *isoGraph) error {
+	a, b := ga.m, gb.m
+	vars := make(map[string]string)
+	inverse := make(map[string]string)
+	for i, c := range ga.varColor {
+		name := gb.vars[gb.varWithColor(c)].Value
+		vars[ga.vars[i].Value] = name
+		inverse[name] = ga.vars[i].Value
+	}
+	// Rename a's variables temporarily to compare statements.
+	a.rename(vars, nil)
+	defer a.rename(inverse, nil)
+
+	describe := func(what, name, other string, c Change) error {
+		return fmt.Errorf("%s %s corresponds to %s, but its %s differs: %s in the first model and %s in the second",
+			what, name, other, c.Detail, c.Old, c.New)
+	}
+	for k, o := range a.objectives() {
+		if cs := compareObjectives(o, b.objectives()[k]); len(cs) > 0 {
+			return describe("objective", o.Name, b.objectives()[k].Name, cs[0])
+		}
+	}
+	for i, c := range ga.rowColor {
+		j := gb.rowWithColor(c)
+		if cs := compareRows(ga.rows[i], a.Rows[i], b.Rows[j]); len(cs) > 0 {
+			return describe("constraint", ga.rows[i], gb.rows[j], cs[0])
+		}
+	}
+	texts := func(m *LP) (sos, gen, pwl []string) {
+		for _, s := range m.SOS {
+			sos = append(sos, sosText(s))
+		}
+		for _, g := range m.GenCons {
+			gen = append(gen, genConText(g))
+		}
+		for _, pw := range m.PWLObjs {
+			pwl = append(pwl, pw.Var.Value+": "+pwlText(pw))
+		}
+		sort.Strings(sos)
+		sort.Strings(gen)
+		sort.Strings(pwl)
+		return sos, gen, pwl
+	}
+	sa, ga2, pa := texts(a)
+	sb, gb2, pb := texts(b)
+	for _, x := range []struct {
+		what   string
+		as, bs []string
+	}{{"SOS constraints", sa, sb}, {"general constraints", ga2, gb2}, {"piecewise-linear objectives", pa, pb}} {
+		if strings.Join(x.as, "\n") != strings.Join(x.bs, "\n") {
+			return fmt.Errorf("the %s differ", x.what)
+		}
+	}
+	return nil
+}
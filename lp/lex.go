@@ -0,0 +1,241 @@
+package lp
+
+import (
+	"strconv"
+	"strings"
+)
+
+type tokKind int
+
+const (
+	tokIdent tokKind = iota
+	tokNum
+	tokPlus
+	tokMinus
+	tokColon
+	tokRel
+	tokLParen
+	tokRParen
+	tokComma
+	tokSemi
+	tokStar
+	tokArrow // "->" or "<->" in indicator constraints
+	tokLBrack
+	tokRBrack
+	tokCaret
+	tokSlash
+)
+
+func (k tokKind) String() string {
+	switch k {
+	case tokIdent:
+		return "name"
+	case tokNum:
+		return "number"
+	case tokPlus:
+		return "'+'"
+	case tokMinus:
+		return "'-'"
+	case tokColon:
+		return "':'"
+	case tokRel:
+		return "relation"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokComma:
+		return "','"
+	case tokSemi:
+		return "';'"
+	case tokStar:
+		return "'*'"
+	case tokArrow:
+		return "'->'"
+	case tokLBrack:
+		return "'['"
+	case tokRBrack:
+		return "']'"
+	case tokCaret:
+		return "'^'"
+	case tokSlash:
+		return "'/'"
+	}
+	return "token"
+}
+
+type token struct {
+	kind tokKind
+	text string
+	rel  Rel     // set if kind == tokRel
+	num  float64 // set if kind == tokNum
+	pos  Pos
+
+	// For numbers that run straight into a name, as in 1y,
+	// the text of both, which may be a name starting with a digit.
+	name string
+}
+
+// isDelim reports whether c ends a name or number.
+func isDelim(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\v', '\f', '+', '-', '<', '>', '=', ':':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// punctKinds maps the optional punctuation characters to their tokens.
+var punctKinds = map[byte]tokKind{
+	'(': tokLParen,
+	')': tokRParen,
+	',': tokComma,
+	';': tokSemi,
+	'*': tokStar,
+	'[': tokLBrack,
+	']': tokRBrack,
+	'^': tokCaret,
+	'/': tokSlash,
+}
+
+// lex splits a single line of an LP file into tokens.
+// pos.Col is the column of line[0] in the file.
+// The characters in punct, which must be in punctKinds,
+// are separate tokens rather than part of names.
+// Comments must already have been removed.
+func lex(line string, pos Pos, punct string) ([]token, error) {
+	var toks []token
+	// at returns the position of line[i:j].
+	at := func(i, j int) Pos {
+		p := pos
+		p.Col += int32(i)
+		p.EndCol = p.Col + int32(j-i)
+		return p
+	}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\v' || c == '\f':
+			i++
+		case c == '+':
+			toks = append(toks, token{kind: tokPlus, text: "+", pos: at(i, i+1)})
+			i++
+		case strings.HasPrefix(line[i:], "->"):
+			toks = append(toks, token{kind: tokArrow, text: "->", pos: at(i, i+2)})
+			i += 2
+		case strings.HasPrefix(line[i:], "<->"):
+			toks = append(toks, token{kind: tokArrow, text: "<->", pos: at(i, i+3)})
+			i += 3
+		case c == '-':
+			toks = append(toks, token{kind: tokMinus, text: "-", pos: at(i, i+1)})
+			i++
+		case c == ':':
+			toks = append(toks, token{kind: tokColon, text: ":", pos: at(i, i+1)})
+			i++
+		case strings.IndexByte(punct, c) >= 0:
+			toks = append(toks, token{kind: punctKinds[c], text: line[i : i+1], pos: at(i, i+1)})
+			i++
+		case c == '<' || c == '>' || c == '=':
+			j := i + 1
+			if j < len(line) && (line[j] == '=' || (c == '=' && (line[j] == '<' || line[j] == '>'))) {
+				j++
+			}
+			text := line[i:j]
+			var rel Rel
+			switch {
+			case strings.ContainsRune(text, '<'):
+				rel = RelLE
+			case strings.ContainsRune(text, '>'):
+				rel = RelGE
+			default:
+				rel = RelEQ
+			}
+			toks = append(toks, token{kind: tokRel, text: text, rel: rel, pos: at(i, j)})
+			i = j
+		case isDigit(c) || c == '.':
+			j := scanNum(line, i)
+			k := scanName(line, j, punct)
+			if j == i {
+				if k > i+1 {
+					return nil, digitName(at(i, k), line[i:k])
+				}
+				return nil, errorAt(at(i, i+1), "malformed number")
+			}
+			v, err := strconv.ParseFloat(line[i:j], 64)
+			if err != nil {
+				return nil, errorAt(at(i, j), "malformed number %q", line[i:j])
+			}
+			t := token{kind: tokNum, text: line[i:j], num: v, pos: at(i, j)}
+			if k > j {
+				t.name = line[i:k]
+			}
+			toks = append(toks, t)
+			i = j
+		default:
+			j := scanName(line, i, punct)
+			toks = append(toks, token{kind: tokIdent, text: line[i:j], pos: at(i, j)})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// scanName returns the end of the name starting at s[i],
+// which is i if s[i] cannot be part of a name.
+func scanName(s string, i int, punct string) int {
+	j := i
+	for j < len(s) && !isDelim(s[j]) && strings.IndexByte(punct, s[j]) < 0 {
+		j++
+	}
+	return j
+}
+
+// nameError returns the error for a number that runs into a name,
+// read as the name it makes up.
+func (t token) nameError() error {
+	pos := t.pos
+	pos.EndCol = pos.Col + int32(len(t.name))
+	return digitName(pos, t.name)
+}
+
+// digitName returns the error for name at pos, which starts with a digit or period
+// and so is read as a number.
+func digitName(pos Pos, name string) error {
+	return errorFor(checkInvalidName, pos, "name cannot start with a digit or period: %q (use %q)", name, "_"+name)
+}
+
+// scanNum returns the end of the number starting at s[i].
+// It returns i if there are no digits.
+func scanNum(s string, i int) int {
+	j := i
+	digits := 0
+	for j < len(s) && isDigit(s[j]) {
+		j++
+		digits++
+	}
+	if j < len(s) && s[j] == '.' {
+		j++
+		for j < len(s) && isDigit(s[j]) {
+			j++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return i
+	}
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+		if k < len(s) && isDigit(s[k]) {
+			for k < len(s) && isDigit(s[k]) {
+				k++
+			}
+			j = k
+		}
+	}
+	return j
+}
@@ -0,0 +1,60 @@
+package lp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	for _, tt := range []struct {
+		line  string
+		punct string
+		want  string // tokens as kind@col:text
+		err   string
+	}{
+		{" c1: 2 x + 3.5y >= 1e3", "", `name@2:c1 ':'@4:: number@6:2 name@8:x '+'@10:+ number@12:3.5 name@15:y relation@17:>= number@20:1e3`, ""},
+		{"x - y =< -1", "", `name@1:x '-'@3:- name@5:y relation@7:=< '-'@10:- number@11:1`, ""},
+		{"x = 2", "", `name@1:x relation@3:= number@5:2`, ""},
+		{"a<b", "", `name@1:a relation@2:< name@3:b`, ""},
+		{"x.1 + .5 z", "", `name@1:x.1 '+'@5:+ number@7:.5 name@10:z`, ""},
+		{"b = 1 -> x >= 2", "", `name@1:b relation@3:= number@5:1 '->'@7:-> name@10:x relation@12:>= number@15:2`, ""},
+		{"[ x * y ]", "[]*", `'['@1:[ name@3:x '*'@5:* name@7:y ']'@9:]`, ""},
+		{"[ x * y ]", "", `name@1:[ name@3:x name@5:* name@7:y name@9:]`, ""},
+		{"int x, y;", ",;", `name@1:int name@5:x ','@6:, name@8:y ';'@9:;`, ""},
+		{"x + .", "", "", "m.lp:1:5: malformed number"},
+		{"x + .abc", "", "", `m.lp:1:5: name cannot start with a digit or period: ".abc"`},
+	} {
+		toks, err := lex(tt.line, Pos{File: "m.lp", Line: 1, Col: 1}, tt.punct)
+		if tt.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("lex(%q): got error %v, want %q", tt.line, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lex(%q): %v", tt.line, err)
+			continue
+		}
+		var got []string
+		for _, tok := range toks {
+			got = append(got, fmt.Sprintf("%s@%d:%s", tok.kind, tok.pos.Col, tok.text))
+		}
+		if s := strings.Join(got, " "); s != tt.want {
+			t.Errorf("lex(%q):\n got %s\nwant %s", tt.line, s, tt.want)
+		}
+	}
+}
+
+func TestLexNumberIntoName(t *testing.T) {
+	toks, err := lex("3x1 + 2", Pos{File: "m.lp", Line: 1, Col: 1}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != 4 || toks[0].kind != tokNum || toks[0].num != 3 || toks[0].name != "3x1" {
+		t.Fatalf("got %+v, want the number 3 with name 3x1 first", toks)
+	}
+	if toks[1].kind != tokIdent || toks[1].text != "x1" {
+		t.Errorf("got %+v second, want name x1", toks[1])
+	}
+}
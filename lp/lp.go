@@ -0,0 +1,182 @@
+// Package lp parses CPLEX LP files and checks them for common mistakes.
+package lp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An LP is a parsed model.
+//
+// Each Section records the variables that appear in it,
+// while Obj, Rows, and VarBounds hold the parsed statements.
+type LP struct {
+	Objective      Section
+	Constraints    Section
+	Bounds         Section
+	GeneralVars    Section
+	BinaryVars     Section
+	SemiContVars   Section
+	SemiIntVars    Section
+	CustomContVars Section
+	SOSVars        Section
+	FreeVars       Section // declared free in bounds
+
+	Obj       *Objective // nil if there is no objective section
+	Rows      []*Constraint
+	VarBounds []*Bound
+	SOS       []*SOS
+
+	// Gurobi extensions.
+	MultiObj []*Objective // all objectives of a multi-objective model; Obj is the first
+	GenCons  []*GenConstraint
+	PWLObjs  []*PWLObj
+
+	// Headers holds the section headers, such as "Subject To",
+	// in the order they appear.
+	Headers []Symbol
+
+	// RowNames records the names of objectives and constraints
+	// where they are defined and, in MPS files, wherever rows are referenced.
+	RowNames Section
+
+	Ignores []Ignore // lpvet:ignore comments
+}
+
+// sections returns all of lp's sections.
+func (lp *LP) sections() []*Section {
+	return []*Section{
+		&lp.Objective, &lp.Constraints, &lp.Bounds,
+		&lp.GeneralVars, &lp.BinaryVars, &lp.SemiContVars, &lp.SemiIntVars,
+		&lp.CustomContVars, &lp.SOSVars, &lp.FreeVars,
+	}
+}
+
+type Section struct {
+	syms   []Symbol
+	symSet map[string]bool
+}
+
+func (s *Section) AddSym(sym Symbol) {
+	if s.symSet == nil {
+		s.symSet = make(map[string]bool)
+	}
+	s.syms = append(s.syms, sym)
+	s.symSet[sym.Value] = true
+}
+
+func (s *Section) Syms() []Symbol { return s.syms }
+func (s *Section) HasSym(sym Symbol) bool {
+	if s.symSet == nil {
+		return false
+	}
+	return s.symSet[sym.Value]
+}
+
+type Symbol struct {
+	Value string
+	Pos   Pos
+}
+
+// A Pos is the location of a token in a file.
+// Col and EndCol are 1-based byte offsets into the line,
+// with EndCol just past the token's last byte.
+// They are 0 for positions that only refer to a line.
+type Pos struct {
+	File   string
+	Line   int32
+	Col    int32
+	EndCol int32
+}
+
+func (p Pos) String() string {
+	s := p.File + ":" + strconv.Itoa(int(p.Line))
+	if p.Col > 0 {
+		s += ":" + strconv.Itoa(int(p.Col))
+	}
+	return s
+}
+
+const (
+	MaxLineLen           = 510
+	MaxVarLen            = 255
+	MaxConstraintNameLen = MaxVarLen
+)
+
+// validVarName reports whether n is made of the characters
+// that CPLEX allows in names.
+func validVarName(n string) bool {
+	for _, c := range n {
+		switch {
+		case 'a' <= c && c <= 'z':
+		case 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+		default:
+			switch c {
+			case '!', '"', '#', '$', '%', '&', '(', ')', ',', '.', ';', '?', '@', '_', '`', '\'', '{', '}', '|', '~':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CheckName returns an error if name cannot be used
+// as a variable or constraint name in a model in format f.
+func CheckName(f Format, name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if len(name) > MaxVarLen {
+		return fmt.Errorf("name too long: %q (%d > %d)", name, len(name), MaxVarLen)
+	}
+	switch f {
+	case FormatMPS, FormatFreeMPS:
+		if strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("MPS names cannot contain spaces: %q", name)
+		}
+		if f == FormatMPS && len(name) > 8 {
+			return fmt.Errorf("fixed-format MPS names cannot be longer than 8 characters: %q", name)
+		}
+		return nil
+	case FormatLPSolve:
+		if !validLPSolveName(name) {
+			return fmt.Errorf("invalid name: %q", name)
+		}
+	default:
+		if !validVarName(name) {
+			return fmt.Errorf("invalid name: %q", name)
+		}
+	}
+	// Otherwise the name would be read as a number.
+	if c := name[0]; '0' <= c && c <= '9' || c == '.' {
+		return fmt.Errorf("name cannot start with a digit or period: %q", name)
+	}
+	return nil
+}
+
+// CheckRowName is like CheckName for the name of an objective or constraint,
+// which in LP files also cannot be a keyword such as st or free.
+func CheckRowName(f Format, name string) error {
+	if err := CheckName(f, name); err != nil {
+		return err
+	}
+	if (f == FormatLP || f == FormatGLPK) && isKeyword(name) {
+		return fmt.Errorf("name is a keyword: %q", name)
+	}
+	return nil
+}
+
+// CheckVarName is like CheckRowName for the name of a variable,
+// which in LP files also cannot read as an exponent, such as e1.
+func CheckVarName(f Format, name string) error {
+	if err := CheckRowName(f, name); err != nil {
+		return err
+	}
+	if (f == FormatLP || f == FormatGLPK) && isExponentLike(name) {
+		return fmt.Errorf("name reads as an exponent: %q", name)
+	}
+	return nil
+}
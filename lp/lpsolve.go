@@ -0,0 +1,399 @@
+package lp
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+)
+
+// lpsolveInf is the magnitude at which lp_solve treats numbers as infinite.
+const lpsolveInf = 1e30
+
+func validLPSolveName(n string) bool {
+	for _, c := range n {
+		switch {
+		case 'a' <= c && c <= 'z':
+		case 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+		default:
+			switch c {
+			case '_', '[', ']', '{', '}', '/', '.', '&', '#', '$', '%', '~', '\'', '@', '^':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lexLPSolve tokenizes an lp_solve LP file,
+// dropping "//" and "/* */" comments.
+// Lines that cannot be tokenized are added to errs and skipped.
+// lpvet:ignore comments are added to lp.
+func lexLPSolve(lp *LP, name string, r io.Reader, errs *ErrorList) ([]token, error) {
+	var (
+		toks    []token
+		inBlock bool
+	)
+	pos := Pos{File: name, Col: 1}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		pos.Line++
+		// Comments are blanked out rather than removed
+		// to keep the columns of later tokens.
+		b := []byte(s.Text())
+		for i := 0; i < len(b); i++ {
+			switch {
+			case inBlock:
+				if b[i] == '*' && i+1 < len(b) && b[i+1] == '/' {
+					b[i+1] = ' '
+					inBlock = false
+				}
+				b[i] = ' '
+			case b[i] == '/' && i+1 < len(b) && b[i+1] == '/':
+				lp.addIgnore(string(b[i+2:]), pos, strings.TrimSpace(string(b[:i])) != "", errs)
+				b = b[:i]
+			case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+				b[i], b[i+1] = ' ', ' '
+				inBlock = true
+				i++
+			}
+		}
+		lineToks, err := lex(string(b), pos, ",;*")
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+		toks = append(toks, lineToks...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if inBlock {
+		errs.add(errorAt(pos, "unterminated comment"))
+	}
+	return toks, nil
+}
+
+// ParseLPSolve parses a model in lp_solve's LP format from r.
+// name is used in positions.
+//
+// Statements end with ';'. The first is the objective,
+// optionally prefixed by "max:" or "min:".
+// Relations on a single unlabeled variable are bounds,
+// int, bin, sec, sin, and free declare variables,
+// and sos1, sos2, and sos sections hold special ordered sets.
+// Since lp_solve does not require declarations,
+// all other variables are declared as continuous.
+//
+// A statement with a syntax error is skipped,
+// and parsing resumes after its ';'.
+func ParseLPSolve(name string, r io.Reader) (*LP, error) {
+	return parseLPSolve(name, r, DefaultLimits)
+}
+
+func parseLPSolve(name string, r io.Reader, lim Limits) (*LP, error) {
+	var errs ErrorList
+	lp := new(LP)
+	toks, err := lexLPSolve(lp, name, r, &errs)
+	if err != nil {
+		return nil, err
+	}
+	first := true
+	sos := -1 // type of the sets in the current sos section, if any
+	for len(toks) > 0 {
+		end := 0
+		for end < len(toks) && toks[end].kind != tokSemi {
+			end++
+		}
+		if end == len(toks) {
+			errs.add(errorAt(toks[end-1].pos, "missing ';'"))
+			break
+		}
+		p := parser{lp: lp, toks: toks[:end], validName: validLPSolveName, maxVarLen: lim.MaxVarLen, nameChars: lim.NameChars, errs: &errs}
+		stmtAt := toks[end].pos
+		if end > 0 {
+			stmtAt = toks[0].pos
+		}
+		toks = toks[end+1:]
+		if first {
+			err = p.parseLPSolveObjective(stmtAt)
+			first = false
+		} else if !p.done() {
+			err = p.parseLPSolveStmt(&sos)
+		}
+		if err != nil {
+			errs.add(err)
+		}
+	}
+	if first {
+		errs.add(errorAt(Pos{File: name}, "missing objective"))
+	}
+	declareImplicit(lp)
+	return lp, errs.err()
+}
+
+func (p *parser) parseLPSolveObjective(at Pos) error {
+	obj := &Objective{Sense: Minimize, Pos: at}
+	if p.i+1 < len(p.toks) && p.toks[p.i].kind == tokIdent && p.toks[p.i+1].kind == tokColon {
+		switch strings.ToLower(p.toks[p.i].text) {
+		case "max", "maximize", "maximise", "maximum":
+			obj.Sense = Maximize
+			p.i += 2
+		case "min", "minimize", "minimise", "minimum":
+			p.i += 2
+		}
+	}
+	obj.Name = p.label()
+	p.kind = secObjective
+	if !p.done() {
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		obj.Expr = e
+	}
+	if !p.done() {
+		return p.unexpected("'+', '-', or ';'")
+	}
+	p.lp.Obj = obj
+	return nil
+}
+
+// parseLPSolveStmt parses a statement other than the objective.
+// *sos is the type of the sets in the current sos section, 0 if they each
+// give their type, or -1 outside of sos sections; it is updated as sections start and end.
+func (p *parser) parseLPSolveStmt(sos *int) error {
+	if t := p.peek(); t.kind == tokIdent && (p.i+1 >= len(p.toks) || p.toks[p.i+1].kind != tokColon) {
+		var sec *Section
+		*sos = -1
+		switch strings.ToLower(t.text) {
+		case "int":
+			sec = &p.lp.GeneralVars
+		case "bin":
+			sec = &p.lp.BinaryVars
+		case "sec":
+			sec = &p.lp.SemiContVars
+		case "sin":
+			sec = &p.lp.SemiIntVars
+		case "free":
+			sec = &p.lp.CustomContVars
+		case "sos":
+			*sos = 0
+		case "sos1":
+			*sos = 1
+		case "sos2":
+			*sos = 2
+		}
+		if sec != nil {
+			p.next()
+			return p.parseLPSolveDecls(strings.ToLower(t.text), sec)
+		}
+		if *sos >= 0 {
+			p.next()
+			if p.done() {
+				return nil
+			}
+		}
+	}
+	if *sos >= 0 {
+		return p.parseLPSolveSOS(*sos)
+	}
+
+	c := &Constraint{Pos: p.peek().pos}
+	c.Name = p.label()
+	lhs, err := p.parseExpr()
+	if err != nil {
+		return err
+	}
+	if !p.peekKind(tokRel) {
+		return p.unexpected("relation")
+	}
+	c.LHS = lhs
+	c.Rel = p.next().rel
+	if c.RHS, err = p.parseExpr(); err != nil {
+		return err
+	}
+	if p.peekKind(tokRel) {
+		// lo <= x <= hi
+		rel2 := p.next().rel
+		hi, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		if !p.done() {
+			return p.unexpected("';'")
+		}
+		if len(c.LHS.Terms) != 0 || len(hi.Terms) != 0 {
+			return errorAt(c.Pos, "limits of a double-sided constraint must be constants")
+		}
+		if c.Name != "" || len(c.RHS.Terms) != 1 || c.RHS.Terms[0].Coef != 1 {
+			if err := c.setRange(c.LHS.Constant, c.RHS, rel2, hi.Constant); err != nil {
+				return err
+			}
+			c.RangeLo, c.RHS.Constant = lpsolveNum(c.RangeLo), lpsolveNum(c.RHS.Constant)
+			for _, t := range c.LHS.Terms {
+				p.lp.Constraints.AddSym(t.Var)
+			}
+			p.lp.Rows = append(p.lp.Rows, c)
+			return nil
+		}
+		b := &Bound{Var: c.RHS.Terms[0].Var, Pos: c.Pos}
+		b.set(c.Rel.Flip(), lpsolveNum(c.LHS.Constant-c.RHS.Constant))
+		b.set(rel2, lpsolveNum(hi.Constant-c.RHS.Constant))
+		p.addBound(b)
+		return nil
+	}
+	if !p.done() {
+		return p.unexpected("'+', '-', or ';'")
+	}
+
+	// A relation on a single variable without a label is a bound.
+	if c.Name == "" && len(c.LHS.Terms)+len(c.RHS.Terms) == 1 {
+		t, rel := Term{}, c.Rel
+		if len(c.LHS.Terms) == 1 {
+			t = c.LHS.Terms[0]
+		} else {
+			t = c.RHS.Terms[0]
+			t.Coef = -t.Coef
+		}
+		if t.Coef != 0 {
+			// t.Coef*x + lhs.Constant rel rhs.Constant
+			v := (c.RHS.Constant - c.LHS.Constant) / t.Coef
+			if t.Coef < 0 {
+				rel = rel.Flip()
+			}
+			b := &Bound{Var: t.Var, Pos: c.Pos}
+			b.set(rel, lpsolveNum(v))
+			p.addBound(b)
+			return nil
+		}
+	}
+	for _, t := range c.LHS.Terms {
+		p.lp.Constraints.AddSym(t.Var)
+	}
+	for _, t := range c.RHS.Terms {
+		p.lp.Constraints.AddSym(t.Var)
+	}
+	p.lp.Rows = append(p.lp.Rows, c)
+	return nil
+}
+
+func (p *parser) addBound(b *Bound) {
+	p.lp.Bounds.AddSym(b.Var)
+	p.lp.VarBounds = append(p.lp.VarBounds, b)
+}
+
+// lpsolveNum converts lp_solve's large values to infinities.
+func lpsolveNum(v float64) float64 {
+	switch {
+	case v >= lpsolveInf:
+		return math.Inf(1)
+	case v <= -lpsolveInf:
+		return math.Inf(-1)
+	}
+	return v
+}
+
+// parseLPSolveDecls parses the variable list of an
+// int, bin, sec, sin, or free declaration.
+func (p *parser) parseLPSolveDecls(kind string, sec *Section) error {
+	for !p.done() {
+		if p.peekKind(tokComma) {
+			p.next()
+			continue
+		}
+		if !p.peekKind(tokIdent) {
+			return p.unexpected("variable name")
+		}
+		sym, err := p.sym(p.next())
+		if err != nil {
+			return err
+		}
+		sec.AddSym(sym)
+		switch kind {
+		case "free":
+			b := &Bound{Var: sym, Pos: sym.Pos}
+			b.setFree()
+			p.addBound(b)
+			p.lp.FreeVars.AddSym(sym)
+		}
+	}
+	return nil
+}
+
+// parseLPSolveSOS parses a set of the form "name: x1:5,x2:9,x3:12 <= n".
+// If typ is nonzero, the set is in an sos1 or sos2 section,
+// and the optional n is its priority, which is ignored.
+// Otherwise n is its type.
+// Members without a weight are weighted by their position.
+func (p *parser) parseLPSolveSOS(typ int) error {
+	p.kind = secSOS
+	s := &SOS{Type: typ, Pos: p.peek().pos}
+	if !p.isSOSMember() {
+		s.Name = p.label()
+	}
+	for !p.done() && !p.peekKind(tokRel) {
+		if p.peekKind(tokComma) {
+			p.next()
+			continue
+		}
+		if !p.peekKind(tokIdent) {
+			return p.unexpected("variable name")
+		}
+		sym, err := p.sym(p.next())
+		if err != nil {
+			return err
+		}
+		w := float64(len(s.Members) + 1)
+		if p.peekKind(tokColon) {
+			p.next()
+			if w, err = p.parseNum(); err != nil {
+				return err
+			}
+		}
+		p.lp.SOSVars.AddSym(sym)
+		s.Members = append(s.Members, SOSMember{Var: sym, Weight: w})
+	}
+	if len(s.Members) == 0 {
+		return p.unexpected("variable name")
+	}
+	if p.peekKind(tokRel) && p.peek().rel == RelLE {
+		p.next()
+		at := p.peek().pos
+		n, err := p.parseNum()
+		if err != nil {
+			return err
+		}
+		if typ == 0 {
+			if n != 1 && n != 2 {
+				return errorAt(at, "unsupported SOS type %v", n)
+			}
+			s.Type = int(n)
+		}
+	} else if typ == 0 {
+		return p.unexpected("'<='")
+	}
+	if !p.done() {
+		return p.unexpected("';'")
+	}
+	p.lp.SOS = append(p.lp.SOS, s)
+	return nil
+}
+
+// declareImplicit declares all undeclared variables as continuous.
+func declareImplicit(lp *LP) {
+	declared := func(sym Symbol) bool {
+		return lp.GeneralVars.HasSym(sym) || lp.BinaryVars.HasSym(sym) ||
+			lp.SemiContVars.HasSym(sym) || lp.SemiIntVars.HasSym(sym) ||
+			lp.CustomContVars.HasSym(sym)
+	}
+	for _, sec := range []*Section{&lp.Objective, &lp.Constraints, &lp.Bounds, &lp.SOSVars} {
+		for _, sym := range sec.Syms() {
+			if !declared(sym) {
+				lp.CustomContVars.AddSym(sym)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package lp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseLPSolveSOS(t *testing.T) {
+	for _, tt := range []struct {
+		sets string
+		want string // sets as name/type: var:weight ...
+		err  string
+	}{
+		{"sos1\ns1: x:5,y:9 <= 2;\n", "s1/1: x:5 y:9", ""},
+		{"sos2\ns1: x:5,y:9,z:12;\ns2: y:1,z:2;\n", "s1/2: x:5 y:9 z:12, s2/2: y:1 z:2", ""},
+		{"sos2\ns1: x,y,z;\n", "s1/2: x:1 y:2 z:3", ""},
+		{"sos\ns1: x:1,y:2 <= 2;\n", "s1/2: x:1 y:2", ""},
+		{"sos1\ns1: x:-1,y:+2;\n", "s1/1: x:-1 y:2", ""},
+		{"sos\ns1: x:1,y:2;\n", "", "expected '<='"},
+		{"sos\ns1: x:1,y:2 <= 3;\n", "", "unsupported SOS type 3"},
+		{"sos2\ns1: x:1,y:2;\nint z;\ns2: x + y <= 1;\n", "s1/2: x:1 y:2", ""},
+	} {
+		model := "max: x + y + z;\nc1: x + y + z <= 3;\n" + tt.sets
+		lp, err := ParseLPSolve("m.lp", strings.NewReader(model))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: got error %v, want %q", tt.sets, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.sets, err)
+			continue
+		}
+		var sets []string
+		for _, s := range lp.SOS {
+			text := fmt.Sprintf("%s/%d:", s.Name, s.Type)
+			for _, m := range s.Members {
+				text += fmt.Sprintf(" %s:%v", m.Var.Value, m.Weight)
+			}
+			sets = append(sets, text)
+		}
+		if got := strings.Join(sets, ", "); got != tt.want {
+			t.Errorf("%q: got sets %q, want %q", tt.sets, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package lp
+
+import "sort"
+
+// A Matrix is the constraint matrix of a model in coordinate form.
+type Matrix struct {
+	Rows []string // names of the constraints, with unnamed ones named R1, R2, ...
+	Cols []string // names of the variables, in order of first appearance
+
+	// Entries holds the nonzero linear coefficients,
+	// ordered by row and then by column.
+	Entries []Entry
+}
+
+// An Entry is a nonzero coefficient of a Matrix.
+type Entry struct {
+	Row, Col int
+	Value    float64
+}
+
+// ConstraintMatrix returns the matrix of the linear coefficients
+// of the constraints of lp, after moving variables to the left.
+// Quadratic terms are left out.
+func ConstraintMatrix(lp *LP) *Matrix {
+	cols, _ := lp.columns()
+	_, rows := lp.rowNames()
+	m := &Matrix{Rows: rows}
+	colOf := make(map[string]int)
+	for i, sym := range cols {
+		m.Cols = append(m.Cols, sym.Value)
+		colOf[sym.Value] = i
+	}
+	for i, c := range lp.Rows {
+		e, _, _ := rowForm(c)
+		var row []Entry
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				row = append(row, Entry{i, colOf[t.Var.Value], t.Coef})
+			}
+		}
+		// Terms are ordered by name; order them by column instead.
+		sort.Slice(row, func(i, j int) bool { return row[i].Col < row[j].Col })
+		m.Entries = append(m.Entries, row...)
+	}
+	return m
+}
+
+// A StandardForm holds the data of a linear model as vectors:
+// it optimizes Obj·x + ObjConstant subject to RowLo <= Ax <= RowHi
+// and ColLo <= x <= ColHi, where x[j] is integer if Integer[j].
+// Infinite limits are given as ±Inf.
+type StandardForm struct {
+	*Matrix
+	Sense       Sense
+	Obj         []float64 // by column
+	ObjConstant float64
+	RowLo       []float64
+	RowHi       []float64
+	ColLo       []float64
+	ColHi       []float64
+	Integer     []bool // general, binary, and semi-integer variables
+}
+
+// ToStandardForm returns the data of lp as vectors.
+// Only the first objective of multi-objective models is included,
+// and quadratic terms, SOS, general constraints, and piecewise-linear
+// objectives are left out.
+// Semi-continuous variables keep their bounds, without the option of 0.
+func ToStandardForm(lp *LP) *StandardForm {
+	m := ConstraintMatrix(lp)
+	n := len(m.Cols)
+	sf := &StandardForm{
+		Matrix:  m,
+		Obj:     make([]float64, n),
+		ColLo:   make([]float64, n),
+		ColHi:   make([]float64, n),
+		Integer: make([]bool, n),
+	}
+	colOf := make(map[string]int)
+	for j, v := range Variables(lp) {
+		colOf[v.Name] = j
+		sf.ColLo[j], sf.ColHi[j] = v.Lo, v.Hi
+		switch v.Type {
+		case "general", "binary", "semi-integer":
+			sf.Integer[j] = true
+		}
+	}
+	if lp.Obj != nil {
+		sf.Sense = lp.Obj.Sense
+		sf.ObjConstant = lp.Obj.Expr.Constant
+		for _, t := range combine(lp.Obj.Expr, Expr{}).Terms {
+			sf.Obj[colOf[t.Var.Value]] = t.Coef
+		}
+	}
+	for _, c := range lp.Rows {
+		_, lo, hi := rowForm(c)
+		sf.RowLo = append(sf.RowLo, lo)
+		sf.RowHi = append(sf.RowHi, hi)
+	}
+	return sf
+}
@@ -0,0 +1,99 @@
+package lp
+
+import "fmt"
+
+// Merge returns the model made of all the statements of models,
+// such as when a model is split into files with its constraints in one
+// and its declarations in another.
+// The positions of symbols and statements are kept,
+// so they refer to the files the statements are in.
+// The objective is that of the first model with one.
+func Merge(models ...*LP) *LP {
+	m := new(LP)
+	for _, o := range models {
+		secs, osecs := m.sections(), o.sections()
+		for i := range secs {
+			for _, sym := range osecs[i].Syms() {
+				secs[i].AddSym(sym)
+			}
+		}
+		for _, sym := range o.RowNames.Syms() {
+			m.RowNames.AddSym(sym)
+		}
+		if m.Obj == nil {
+			m.Obj = o.Obj
+			m.MultiObj = o.MultiObj
+		}
+		m.Rows = append(m.Rows, o.Rows...)
+		m.VarBounds = append(m.VarBounds, o.VarBounds...)
+		m.SOS = append(m.SOS, o.SOS...)
+		m.GenCons = append(m.GenCons, o.GenCons...)
+		m.PWLObjs = append(m.PWLObjs, o.PWLObjs...)
+		m.Headers = append(m.Headers, o.Headers...)
+		m.Ignores = append(m.Ignores, o.Ignores...)
+	}
+	return m
+}
+
+// vetAcrossFiles reports the variables declared in more than one file
+// and the constraint names used in more than one file
+// of a model merged from several, at their uses after the first.
+func vetAcrossFiles(lp *LP, warnings bool) []Diagnostic {
+	var diags []Diagnostic
+	if warnings {
+		first := make(map[string]Pos)
+		reported := make(map[string]bool) // by variable and file
+		for _, sec := range []*Section{&lp.GeneralVars, &lp.BinaryVars, &lp.SemiContVars, &lp.SemiIntVars, &lp.CustomContVars} {
+			for _, sym := range sec.Syms() {
+				at, ok := first[sym.Value]
+				if !ok {
+					first[sym.Value] = sym.Pos
+					continue
+				}
+				if key := sym.Value + "\x00" + sym.Pos.File; at.File != sym.Pos.File && !reported[key] {
+					reported[key] = true
+					diags = append(diags, Diagnostic{
+						Pos:      sym.Pos,
+						Severity: Warning,
+						Check:    checkAcrossFiles,
+						Symbol:   sym.Value,
+						Message:  fmt.Sprintf("variable %s is also declared at %s", sym.Value, at),
+					})
+				}
+			}
+		}
+	}
+
+	first := make(map[string]Pos)
+	inFile := make(map[string]bool) // by name and file; repeats within a file are LP019
+	for _, o := range lp.objectives() {
+		if o.Name != "" {
+			first[o.Name] = o.Pos
+			inFile[o.Name+"\x00"+o.Pos.File] = true
+		}
+	}
+	for _, c := range lp.Rows {
+		if c.Name == "" {
+			continue
+		}
+		key := c.Name + "\x00" + c.Pos.File
+		if inFile[key] {
+			continue
+		}
+		inFile[key] = true
+		at, ok := first[c.Name]
+		if !ok {
+			first[c.Name] = c.Pos
+			continue
+		}
+		if at.File != c.Pos.File {
+			diags = append(diags, Diagnostic{
+				Pos:      c.Pos,
+				Severity: Error,
+				Check:    checkAcrossFiles,
+				Message:  fmt.Sprintf("constraint name %s is also used at %s", c.Name, at),
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,601 @@
+package lp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type mpsSection int
+
+const (
+	mpsNone mpsSection = iota
+	mpsName
+	mpsRows
+	mpsColumns
+	mpsRHS
+	mpsRanges
+	mpsBounds
+	mpsObjSense
+	mpsObjName
+	mpsQuadObj
+	mpsQMatrix
+	mpsEnd
+	mpsUnknown // data of an unknown section, which is skipped
+)
+
+var mpsSections = map[string]mpsSection{
+	"NAME":      mpsName,
+	"ROWS":      mpsRows,
+	"COLUMNS":   mpsColumns,
+	"RHS":       mpsRHS,
+	"RANGES":    mpsRanges,
+	"BOUNDS":    mpsBounds,
+	"OBJSENSE":  mpsObjSense,
+	"OBJSENSE:": mpsObjSense,
+	"OBJNAME":   mpsObjName,
+	"OBJNAME:":  mpsObjName,
+	"QUADOBJ":   mpsQuadObj,
+	"QMATRIX":   mpsQMatrix,
+	"ENDATA":    mpsEnd,
+}
+
+// mpsFieldCols holds the 0-based [start, end) columns
+// of the six fields in a fixed-format MPS data line.
+var mpsFieldCols = [6][2]int{{1, 3}, {4, 12}, {14, 22}, {24, 36}, {39, 47}, {49, 61}}
+
+// fixedFields splits a fixed-format data line into its six fields.
+func fixedFields(line string) [6]string {
+	var f [6]string
+	for i, c := range mpsFieldCols {
+		if c[0] >= len(line) {
+			break
+		}
+		end := c[1]
+		if end > len(line) {
+			end = len(line)
+		}
+		f[i] = strings.TrimSpace(line[c[0]:end])
+	}
+	return f
+}
+
+// freeFields splits a free-format data line in section sec
+// into the same six fields used by fixed-format files.
+// Set names in RHS, RANGES, and BOUNDS are optional.
+func freeFields(sec mpsSection, line string) ([6]string, error) {
+	var f [6]string
+	t := strings.Fields(line)
+	switch sec {
+	case mpsRows:
+		if len(t) != 2 {
+			return f, errors.New("expected two fields")
+		}
+	case mpsColumns:
+		switch {
+		case len(t) == 3 && strings.Trim(t[1], "'") == "MARKER":
+			t = []string{"", t[0], t[1], "", t[2]}
+		case len(t) == 3 || len(t) == 5:
+			t = append([]string{""}, t...)
+		default:
+			return f, errors.New("expected three or five fields")
+		}
+	case mpsRHS, mpsRanges:
+		switch len(t) {
+		case 2, 4:
+			t = append([]string{"", ""}, t...)
+		case 3, 5:
+			t = append([]string{""}, t...)
+		default:
+			return f, errors.New("expected two to five fields")
+		}
+	case mpsBounds:
+		if len(t) < 2 {
+			return f, errors.New("missing bound type or column")
+		}
+		hasValue := true
+		switch strings.ToUpper(t[0]) {
+		case "FR", "MI", "PL":
+			hasValue = false
+		case "BV", "SC":
+			_, err := strconv.ParseFloat(t[len(t)-1], 64)
+			hasValue = err == nil && len(t) > 2
+		}
+		n := 2
+		if hasValue {
+			n = 3
+		}
+		switch len(t) {
+		case n:
+			t = append([]string{t[0], ""}, t[1:]...)
+		case n + 1:
+		default:
+			return f, errors.New("wrong number of fields in bound")
+		}
+	default:
+		t = append([]string{""}, t...)
+	}
+	copy(f[:], t)
+	return f, nil
+}
+
+type colKind int
+
+const (
+	colCont colKind = iota
+	colInt
+	colBinary
+	colSemi
+	colSemiInt
+)
+
+type mpsParser struct {
+	lp      *LP
+	pos     Pos
+	line    string // current line, for fieldPos
+	freeFmt bool
+	sense   Sense
+	objName string // from OBJNAME, if any
+	objRow  string
+	rows    map[string]*Constraint
+	free    map[string]bool // non-objective N rows
+
+	rangeVals map[string]float64 // from RANGES, applied by finish
+
+	cols     []Symbol // in order of first appearance
+	colKinds map[string]colKind
+	inInt    bool
+}
+
+// ParseMPS parses a fixed-format MPS file from r.
+// name is used in positions.
+//
+// The first N row is the objective unless OBJNAME names another,
+// and OBJSENSE may change its sense to maximization.
+//
+// Columns are treated as declared variables: integer columns
+// (within INTORG/INTEND markers or with LI/UI bounds) are general,
+// BV and SC bounds make binary and semi-continuous variables
+// (semi-integer for integer columns), and all others are continuous.
+// Quadratic objective terms are read from QUADOBJ or QMATRIX sections.
+func ParseMPS(name string, r io.Reader) (*LP, error) {
+	return parseMPS(name, r, false)
+}
+
+// ParseFreeMPS parses a free-format MPS file from r,
+// where fields are separated by whitespace
+// and names may be of any length but cannot contain spaces.
+// It otherwise behaves like ParseMPS.
+func ParseFreeMPS(name string, r io.Reader) (*LP, error) {
+	return parseMPS(name, r, true)
+}
+
+func parseMPS(name string, r io.Reader, free bool) (*LP, error) {
+	p := &mpsParser{
+		lp:  new(LP),
+		pos: Pos{File: name},
+
+		freeFmt: free,
+		rows:    make(map[string]*Constraint),
+		free:    make(map[string]bool),
+
+		rangeVals: make(map[string]float64),
+		colKinds:  make(map[string]colKind),
+	}
+	var errs ErrorList
+	sec := mpsNone
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		p.pos.Line++
+		line := strings.TrimRight(s.Text(), " \t\r")
+		p.line = line
+		if line == "" {
+			continue
+		}
+		if line[0] == '*' {
+			p.lp.addIgnore(line[1:], p.pos, false, &errs)
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			fields := strings.Fields(line)
+			next, ok := mpsSections[strings.ToUpper(fields[0])]
+			if !ok {
+				// Skip the section's data too, since its layout is unknown.
+				errs.add(p.errorf("unknown section %q", fields[0]))
+				sec = mpsUnknown
+				continue
+			}
+			sec = next
+			hpos := p.pos
+			hpos.Col, hpos.EndCol = 1, int32(len(fields[0]))+1
+			p.lp.Headers = append(p.lp.Headers, Symbol{Value: fields[0], Pos: hpos})
+			if len(fields) > 1 {
+				// Free MPS allows "OBJSENSE MAX" on one line.
+				switch sec {
+				case mpsObjSense:
+					if err := p.objSense(fields[1]); err != nil {
+						errs.add(err)
+					}
+				case mpsObjName:
+					p.objName = fields[1]
+				}
+			}
+			continue
+		}
+		f := fixedFields(line)
+		if free {
+			var err error
+			if f, err = freeFields(sec, line); err != nil {
+				errs.add(p.errorf("%v", err))
+				continue
+			}
+		}
+		var err error
+		switch sec {
+		case mpsRows:
+			err = p.row(f)
+		case mpsColumns:
+			err = p.column(f)
+		case mpsRHS:
+			err = p.rhs(f)
+		case mpsRanges:
+			err = p.ranges(f)
+		case mpsBounds:
+			err = p.bound(f)
+		case mpsQuadObj, mpsQMatrix:
+			err = p.quad(f, sec == mpsQMatrix)
+		case mpsObjSense:
+			err = p.objSense(strings.TrimSpace(line))
+		case mpsObjName:
+			p.objName = strings.TrimSpace(line)
+		case mpsName:
+			err = p.errorf("unexpected data in NAME section")
+		case mpsNone, mpsEnd:
+			err = p.errorf("not in a section")
+		case mpsUnknown:
+		}
+		if err != nil {
+			errs.add(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	p.finish()
+	return p.lp, errs.err()
+}
+
+// fieldPos returns the position of field k of f,
+// which holds the fields of the current line.
+func (p *mpsParser) fieldPos(f [6]string, k int) Pos {
+	pos := p.pos
+	start := -1
+	if p.freeFmt {
+		// freeFields only inserts empty fields,
+		// so f[k] is preceded by as many words as nonempty fields.
+		n := 0
+		for _, s := range f[:k] {
+			if s != "" {
+				n++
+			}
+		}
+		inWord := false
+		for i := 0; i < len(p.line); i++ {
+			space := p.line[i] == ' ' || p.line[i] == '\t'
+			if !space && !inWord {
+				if n == 0 {
+					start = i
+					break
+				}
+				n--
+			}
+			inWord = !space
+		}
+	} else if c := mpsFieldCols[k][0]; c < len(p.line) {
+		start = c + strings.Index(p.line[c:], f[k])
+	}
+	if start < 0 {
+		return pos
+	}
+	pos.Col = int32(start) + 1
+	pos.EndCol = pos.Col + int32(len(f[k]))
+	return pos
+}
+
+func (p *mpsParser) errorf(format string, args ...interface{}) error {
+	return errorAt(p.pos, format, args...)
+}
+
+func (p *mpsParser) objSense(s string) error {
+	switch strings.ToUpper(s) {
+	case "MIN", "MINIMIZE":
+		p.sense = Minimize
+	case "MAX", "MAXIMIZE":
+		p.sense = Maximize
+	default:
+		return p.errorf("unknown objective sense %q", s)
+	}
+	if p.lp.Obj != nil {
+		p.lp.Obj.Sense = p.sense
+	}
+	return nil
+}
+
+func (p *mpsParser) num(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, p.errorf("malformed number %q", s)
+	}
+	return v, nil
+}
+
+func (p *mpsParser) row(f [6]string) error {
+	name := f[1]
+	if name == "" {
+		return p.errorf("missing row name")
+	}
+	if p.rows[name] != nil || p.free[name] || name == p.objRow {
+		return p.errorf("duplicate row %s", name)
+	}
+	c := &Constraint{Name: name, Pos: p.fieldPos(f, 1)}
+	p.lp.RowNames.AddSym(Symbol{Value: name, Pos: c.Pos})
+	switch strings.ToUpper(f[0]) {
+	case "N":
+		if p.objRow == "" && (p.objName == "" || p.objName == name) {
+			p.objRow = name
+			p.lp.Obj = &Objective{Name: name, Sense: p.sense, Pos: c.Pos}
+		} else {
+			p.free[name] = true
+		}
+		return nil
+	case "L":
+		c.Rel = RelLE
+	case "G":
+		c.Rel = RelGE
+	case "E":
+		c.Rel = RelEQ
+	default:
+		return p.errorf("unknown row type %q", f[0])
+	}
+	p.rows[name] = c
+	p.lp.Rows = append(p.lp.Rows, c)
+	return nil
+}
+
+func (p *mpsParser) column(f [6]string) error {
+	if strings.Trim(f[2], "'") == "MARKER" {
+		switch strings.Trim(f[4], "'") {
+		case "INTORG":
+			p.inInt = true
+		case "INTEND":
+			p.inInt = false
+		default:
+			return p.errorf("unknown marker %q", f[4])
+		}
+		return nil
+	}
+	if f[1] == "" {
+		return p.errorf("missing column name")
+	}
+	sym := Symbol{Value: f[1], Pos: p.fieldPos(f, 1)}
+	if _, ok := p.colKinds[sym.Value]; !ok {
+		p.cols = append(p.cols, sym)
+		p.colKinds[sym.Value] = colCont
+	}
+	if p.inInt {
+		p.colKinds[sym.Value] = colInt
+	}
+	for i := 2; i+1 < len(f); i += 2 {
+		if f[i] == "" {
+			continue
+		}
+		v, err := p.num(f[i+1])
+		if err != nil {
+			return err
+		}
+		p.rowRef(f, i)
+		switch {
+		case f[i] == p.objRow:
+			p.lp.Obj.Expr.Terms = append(p.lp.Obj.Expr.Terms, Term{Coef: v, Var: sym})
+			p.lp.Objective.AddSym(sym)
+		case p.free[f[i]]:
+		case p.rows[f[i]] != nil:
+			c := p.rows[f[i]]
+			c.LHS.Terms = append(c.LHS.Terms, Term{Coef: v, Var: sym})
+			p.lp.Constraints.AddSym(sym)
+		default:
+			return p.errorf("unknown row %s", f[i])
+		}
+	}
+	return nil
+}
+
+// quad adds a quadratic objective term from a QUADOBJ line,
+// which gives one triangle of Q in the objective's x'Qx/2,
+// or from a QMATRIX line, which gives all of Q.
+func (p *mpsParser) quad(f [6]string, full bool) error {
+	if f[1] == "" || f[2] == "" {
+		return p.errorf("expected two columns and a value")
+	}
+	if p.lp.Obj == nil {
+		return p.errorf("quadratic term without an objective")
+	}
+	v, err := p.num(f[3])
+	if err != nil {
+		return err
+	}
+	sym1 := Symbol{Value: f[1], Pos: p.fieldPos(f, 1)}
+	sym2 := Symbol{Value: f[2], Pos: p.fieldPos(f, 2)}
+	for _, sym := range []Symbol{sym1, sym2} {
+		if _, ok := p.colKinds[sym.Value]; !ok {
+			return p.errorf("unknown column %s", sym.Value)
+		}
+		p.lp.Objective.AddSym(sym)
+	}
+	if full || f[1] == f[2] {
+		v /= 2
+	}
+	p.lp.Obj.Expr.Quad = append(p.lp.Obj.Expr.Quad, QuadTerm{Coef: v, Var1: sym1, Var2: sym2})
+	return nil
+}
+
+// rowRef records the reference to a row in field k of f, if the row exists.
+func (p *mpsParser) rowRef(f [6]string, k int) {
+	if name := f[k]; name == p.objRow || p.free[name] || p.rows[name] != nil {
+		p.lp.RowNames.AddSym(Symbol{Value: name, Pos: p.fieldPos(f, k)})
+	}
+}
+
+func (p *mpsParser) rhs(f [6]string) error {
+	for i := 2; i+1 < len(f); i += 2 {
+		if f[i] == "" {
+			continue
+		}
+		v, err := p.num(f[i+1])
+		if err != nil {
+			return err
+		}
+		p.rowRef(f, i)
+		switch {
+		case f[i] == p.objRow:
+			// The objective RHS is the negated objective constant.
+			p.lp.Obj.Expr.Constant = -v
+		case p.free[f[i]]:
+		case p.rows[f[i]] != nil:
+			p.rows[f[i]].RHS = Expr{Constant: v}
+		default:
+			return p.errorf("unknown row %s", f[i])
+		}
+	}
+	return nil
+}
+
+func (p *mpsParser) ranges(f [6]string) error {
+	for i := 2; i+1 < len(f); i += 2 {
+		if f[i] == "" {
+			continue
+		}
+		v, err := p.num(f[i+1])
+		if err != nil {
+			return err
+		}
+		if p.rows[f[i]] == nil {
+			return p.errorf("range for unknown row %s", f[i])
+		}
+		p.rowRef(f, i)
+		p.rangeVals[f[i]] = v
+	}
+	return nil
+}
+
+// applyRange turns c into a ranged row using the RANGES value r.
+// Following the MPS convention, the range extends away from the RHS:
+// below it for L rows, above it for G rows, and by the sign of r
+// for E rows.
+func applyRange(c *Constraint, r float64) {
+	rhs := c.RHS.Constant
+	lo, hi := rhs, rhs
+	switch {
+	case c.Rel == RelLE:
+		lo = rhs - math.Abs(r)
+	case c.Rel == RelGE:
+		hi = rhs + math.Abs(r)
+	case r < 0:
+		lo = rhs + r
+	default:
+		hi = rhs + r
+	}
+	c.Rel = RelLE
+	c.RHS = Expr{Constant: hi}
+	c.Ranged = true
+	c.RangeLo = lo
+}
+
+func (p *mpsParser) bound(f [6]string) error {
+	typ := strings.ToUpper(f[0])
+	if f[2] == "" {
+		return p.errorf("missing column name in bound")
+	}
+	b := &Bound{Var: Symbol{Value: f[2], Pos: p.fieldPos(f, 2)}, Pos: p.fieldPos(f, 2)}
+	var v float64
+	switch typ {
+	case "UP", "LO", "FX", "LI", "UI":
+		var err error
+		if v, err = p.num(f[3]); err != nil {
+			return err
+		}
+	case "SC":
+		v = math.Inf(1)
+		if f[3] != "" {
+			var err error
+			if v, err = p.num(f[3]); err != nil {
+				return err
+			}
+		}
+	}
+	switch typ {
+	case "UP", "UI", "SC":
+		b.set(RelLE, v)
+	case "LO", "LI":
+		b.set(RelGE, v)
+	case "FX":
+		b.set(RelEQ, v)
+	case "FR":
+		b.setFree()
+		p.lp.FreeVars.AddSym(b.Var)
+	case "MI":
+		b.set(RelGE, math.Inf(-1))
+	case "PL":
+		b.set(RelLE, math.Inf(1))
+	case "BV":
+		b.set(RelGE, 0)
+		b.set(RelLE, 1)
+	default:
+		return p.errorf("unknown bound type %q", f[0])
+	}
+	if k, ok := p.colKinds[b.Var.Value]; ok {
+		switch typ {
+		case "BV":
+			p.colKinds[b.Var.Value] = colBinary
+		case "SC":
+			if k == colInt {
+				p.colKinds[b.Var.Value] = colSemiInt
+			} else {
+				p.colKinds[b.Var.Value] = colSemi
+			}
+		case "LI", "UI":
+			if k == colCont {
+				p.colKinds[b.Var.Value] = colInt
+			}
+		}
+	}
+	p.lp.Bounds.AddSym(b.Var)
+	p.lp.VarBounds = append(p.lp.VarBounds, b)
+	return nil
+}
+
+// finish applies ranges and declares every column according to its kind.
+func (p *mpsParser) finish() {
+	for _, c := range p.lp.Rows {
+		if r, ok := p.rangeVals[c.Name]; ok {
+			applyRange(c, r)
+		}
+	}
+	for _, sym := range p.cols {
+		switch p.colKinds[sym.Value] {
+		case colCont:
+			p.lp.CustomContVars.AddSym(sym)
+		case colInt:
+			p.lp.GeneralVars.AddSym(sym)
+		case colBinary:
+			p.lp.BinaryVars.AddSym(sym)
+		case colSemi:
+			p.lp.SemiContVars.AddSym(sym)
+		case colSemiInt:
+			p.lp.SemiIntVars.AddSym(sym)
+		}
+	}
+}
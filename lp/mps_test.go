@@ -0,0 +1,151 @@
+package lp
+
+import (
+	"strings"
+	"testing"
+)
+
+const fixedMPS = `NAME          TESTMPS
+ROWS
+ N  COST
+ L  LIM1
+ G  LIM2
+ E  MYEQN
+COLUMNS
+    X ONE     COST      1              LIM1      1
+    X ONE     LIM2      1
+    Y         COST      2              LIM1      1
+    Y         MYEQN     -1
+    Z         LIM2      1
+RHS
+    RHS       LIM1      4              LIM2      1
+    RHS       MYEQN     7
+RANGES
+    RNG       LIM1      2.5
+BOUNDS
+ UP BND       X ONE     4
+ MI BND       Y
+ BV BND       Z
+ENDATA
+`
+
+func TestParseMPS(t *testing.T) {
+	lp, err := ParseMPS("m.mps", strings.NewReader(fixedMPS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `minimize COST: X ONE + 2 Y
+LIM1: 1.5 <= X ONE + Y <= 4
+LIM2: X ONE + Z >= 1
+MYEQN: - Y = 7
+X ONE <= 4
+Y >= -inf
+0 <= Z <= 1
+binary Z
+`
+	if got := summary(lp); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseMPSErrors(t *testing.T) {
+	const head = "NAME          T\nROWS\n N  COST\n L  LIM1\nCOLUMNS\n"
+	for _, tt := range []struct {
+		name  string
+		model string
+		want  string
+	}{
+		{
+			"unknown row",
+			head + "    X         COST      1              LIM9      1\nENDATA\n",
+			"m.mps:6: unknown row LIM9 [LP011]",
+		},
+		{
+			"malformed number",
+			head + "    X         COST      1              LIM1      abc\nENDATA\n",
+			`m.mps:6: malformed number "abc" [LP011]`,
+		},
+		{
+			"unknown row type",
+			"NAME          T\nROWS\n N  COST\n Q  LIM1\nCOLUMNS\n    X         COST      1\nENDATA\n",
+			`m.mps:4: unknown row type "Q" [LP011]`,
+		},
+		{
+			"unknown bound type",
+			head + "    X         COST      1              LIM1      1\nBOUNDS\n XX BND       X         4\nENDATA\n",
+			`m.mps:8: unknown bound type "XX" [LP011]`,
+		},
+		{
+			"unknown section",
+			head + "    X         COST      1              LIM1      1\nFOO\n bar\nENDATA\n",
+			`m.mps:7: unknown section "FOO" [LP011]`,
+		},
+		{
+			"missing ENDATA",
+			head + "    X         COST      1              LIM1      1\n",
+			"",
+		},
+	} {
+		_, err := ParseMPS("m.mps", strings.NewReader(tt.model))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got error %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseFreeMPS(t *testing.T) {
+	const body = "ROWS\n N cost\n L lim1\nCOLUMNS\n long_variable_name cost 1 lim1 2\n y cost -1 lim1 1\nRHS\n rhs lim1 8\nBOUNDS\n UP bnd y 3\nENDATA\n"
+	for _, tt := range []struct {
+		name  string
+		model string
+		want  string
+		err   string
+	}{
+		{
+			"minimize by default",
+			"NAME free\n" + body,
+			"minimize cost: long_variable_name - y\nlim1: 2 long_variable_name + y <= 8\ny <= 3\n",
+			"",
+		},
+		{
+			"OBJSENSE on its own line",
+			"NAME free\nOBJSENSE\n    MAX\n" + body,
+			"maximize cost: long_variable_name - y\nlim1: 2 long_variable_name + y <= 8\ny <= 3\n",
+			"",
+		},
+		{
+			"OBJSENSE on one line",
+			"NAME free\nOBJSENSE MAXIMIZE\n" + body,
+			"maximize cost: long_variable_name - y\nlim1: 2 long_variable_name + y <= 8\ny <= 3\n",
+			"",
+		},
+		{
+			"OBJSENSE MIN",
+			"NAME free\nOBJSENSE\n    MIN\n" + body,
+			"minimize cost: long_variable_name - y\nlim1: 2 long_variable_name + y <= 8\ny <= 3\n",
+			"",
+		},
+		{
+			"unknown sense",
+			"NAME free\nOBJSENSE\n    UP\n" + body,
+			"minimize cost: long_variable_name - y\nlim1: 2 long_variable_name + y <= 8\ny <= 3\n",
+			`m.mps:3: unknown objective sense "UP" [LP011]`,
+		},
+	} {
+		lp, err := ParseFreeMPS("m.mps", strings.NewReader(tt.model))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.name, got, tt.err)
+		}
+		if got := summary(lp); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package lp
+
+import (
+	"math"
+	"sort"
+)
+
+// Normalize puts lp in a canonical form, so that models that differ only
+// in the order and grouping of their terms and statements
+// are written the same way by WriteLP.
+//
+// Rows are sorted by name, with unnamed rows last in their original order,
+// and each has its variables on the left, sorted by name with the terms of
+// each variable combined, and its constant on the right.
+// Objective terms are combined and sorted the same way, and SOS constraints,
+// general constraints, and piecewise-linear objectives are sorted by name.
+// Bounds and declarations follow the order in which variables first appear,
+// which then only depends on the model.
+func Normalize(lp *LP) {
+	for _, o := range lp.objectives() {
+		e := combine(o.Expr, Expr{})
+		e.Constant = o.Expr.Constant
+		sortExpr(e)
+		o.Expr = e
+	}
+
+	for _, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		sortExpr(e)
+		c.LHS = e
+		switch {
+		case c.Ranged:
+			c.RangeLo, c.RHS = lo, Expr{Constant: hi}
+		case lo == hi:
+			c.Rel, c.RHS = RelEQ, Expr{Constant: lo}
+		case math.IsInf(lo, -1):
+			c.Rel, c.RHS = RelLE, Expr{Constant: hi}
+		default:
+			c.Rel, c.RHS = RelGE, Expr{Constant: lo}
+		}
+	}
+	sort.SliceStable(lp.Rows, func(i, j int) bool {
+		a, b := lp.Rows[i].Name, lp.Rows[j].Name
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+
+	sort.SliceStable(lp.SOS, func(i, j int) bool { return lp.SOS[i].Name < lp.SOS[j].Name })
+	sort.SliceStable(lp.GenCons, func(i, j int) bool { return lp.GenCons[i].Name < lp.GenCons[j].Name })
+	sort.SliceStable(lp.PWLObjs, func(i, j int) bool { return lp.PWLObjs[i].Var.Value < lp.PWLObjs[j].Var.Value })
+}
+
+// sortExpr sorts the terms of e by variable.
+func sortExpr(e Expr) {
+	sort.Slice(e.Terms, func(i, j int) bool { return e.Terms[i].Var.Value < e.Terms[j].Var.Value })
+	sort.Slice(e.Quad, func(i, j int) bool {
+		a, b := e.Quad[i], e.Quad[j]
+		if a.Var1.Value != b.Var1.Value {
+			return a.Var1.Value < b.Var1.Value
+		}
+		return a.Var2.Value < b.Var2.Value
+	})
+}
@@ -0,0 +1,217 @@
+package lp
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+)
+
+type osil struct {
+	XMLName        xml.Name   `xml:"osil"`
+	Xmlns          string     `xml:"xmlns,attr"`
+	XmlnsXSI       string     `xml:"xmlns:xsi,attr"`
+	SchemaLocation string     `xml:"xsi:schemaLocation,attr"`
+	Header         osilHeader `xml:"instanceHeader"`
+	Data           osilData   `xml:"instanceData"`
+}
+
+type osilHeader struct {
+	Name string `xml:"name"`
+}
+
+type osilData struct {
+	Variables   osilVariables    `xml:"variables"`
+	Objectives  *osilObjectives  `xml:"objectives"`
+	Constraints *osilConstraints `xml:"constraints"`
+	Linear      *osilLinear      `xml:"linearConstraintCoefficients"`
+	Quadratic   *osilQuadratic   `xml:"quadraticCoefficients"`
+}
+
+type osilVariables struct {
+	N    int       `xml:"numberOfVariables,attr"`
+	Vars []osilVar `xml:"var"`
+}
+
+type osilVar struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+	LB   string `xml:"lb,attr"`
+	UB   string `xml:"ub,attr"`
+}
+
+type osilObjectives struct {
+	N    int       `xml:"numberOfObjectives,attr"`
+	Objs []osilObj `xml:"obj"`
+}
+
+type osilObj struct {
+	Name     string     `xml:"name,attr,omitempty"`
+	MaxOrMin string     `xml:"maxOrMin,attr"`
+	Constant string     `xml:"constant,attr,omitempty"`
+	N        int        `xml:"numberOfObjCoef,attr"`
+	Coefs    []osilCoef `xml:"coef"`
+}
+
+type osilCoef struct {
+	Idx   int    `xml:"idx,attr"`
+	Value string `xml:",chardata"`
+}
+
+type osilConstraints struct {
+	N    int       `xml:"numberOfConstraints,attr"`
+	Cons []osilCon `xml:"con"`
+}
+
+type osilCon struct {
+	Name string `xml:"name,attr"`
+	LB   string `xml:"lb,attr,omitempty"`
+	UB   string `xml:"ub,attr,omitempty"`
+}
+
+// osilLinear holds the constraint matrix row by row.
+type osilLinear struct {
+	N      int      `xml:"numberOfValues,attr"`
+	Start  []string `xml:"start>el"`
+	ColIdx []string `xml:"colIdx>el"`
+	Value  []string `xml:"value>el"`
+}
+
+type osilQuadratic struct {
+	N     int         `xml:"numberOfQuadraticTerms,attr"`
+	Terms []osilQTerm `xml:"qTerm"`
+}
+
+type osilQTerm struct {
+	Idx    int    `xml:"idx,attr"` // row, or -1, -2, ... for objectives
+	IdxOne int    `xml:"idxOne,attr"`
+	IdxTwo int    `xml:"idxTwo,attr"`
+	Coef   string `xml:"coef,attr"`
+}
+
+// WriteOSiL writes lp to w in the Optimization Services instance
+// language (OSiL), with name as the name of the instance.
+//
+// Rows without names are named R1, R2, and so on by position.
+// WriteOSiL returns an error for constructs that OSiL cannot hold,
+// such as indicator and general constraints.
+func WriteOSiL(w io.Writer, lp *LP, name string) error {
+	if err := lp.unsupported("OSiL files", true, true); err != nil {
+		return err
+	}
+	num := func(v float64) string {
+		switch {
+		case math.IsInf(v, 1):
+			return "INF"
+		case math.IsInf(v, -1):
+			return "-INF"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	doc := osil{
+		Xmlns:          "os.optimizationservices.org",
+		XmlnsXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "os.optimizationservices.org http://www.optimizationservices.org/schemas/2.0/OSiL.xsd",
+		Header:         osilHeader{Name: name},
+	}
+	data := &doc.Data
+
+	colOf := make(map[string]int)
+	for j, v := range Variables(lp) {
+		colOf[v.Name] = j
+		t := "C"
+		switch v.Type {
+		case "binary":
+			t = "B"
+		case "general":
+			t = "I"
+		case "semi-continuous":
+			t = "D"
+		case "semi-integer":
+			t = "J"
+		}
+		data.Variables.Vars = append(data.Variables.Vars, osilVar{v.Name, t, num(v.Lo), num(v.Hi)})
+	}
+	data.Variables.N = len(data.Variables.Vars)
+
+	var quad []osilQTerm
+	addQuad := func(idx int, e Expr) {
+		for _, q := range e.Quad {
+			if q.Coef != 0 {
+				quad = append(quad, osilQTerm{idx, colOf[q.Var1.Value], colOf[q.Var2.Value], num(q.Coef)})
+			}
+		}
+	}
+	if objs := lp.objectives(); len(objs) > 0 {
+		data.Objectives = &osilObjectives{N: len(objs)}
+		for i, o := range objs {
+			e := combine(o.Expr, Expr{})
+			obj := osilObj{Name: o.Name, MaxOrMin: "min"}
+			if o.Sense == Maximize {
+				obj.MaxOrMin = "max"
+			}
+			if o.Expr.Constant != 0 {
+				obj.Constant = num(o.Expr.Constant)
+			}
+			for _, t := range e.Terms {
+				if t.Coef != 0 {
+					obj.Coefs = append(obj.Coefs, osilCoef{colOf[t.Var.Value], num(t.Coef)})
+				}
+			}
+			obj.N = len(obj.Coefs)
+			data.Objectives.Objs = append(data.Objectives.Objs, obj)
+			addQuad(-1-i, e)
+		}
+	}
+
+	if len(lp.Rows) > 0 {
+		_, names := lp.rowNames()
+		data.Constraints = &osilConstraints{N: len(lp.Rows)}
+		lin := &osilLinear{Start: []string{"0"}}
+		for i, c := range lp.Rows {
+			e, lo, hi := rowForm(c)
+			con := osilCon{Name: names[i]}
+			if !math.IsInf(lo, -1) {
+				con.LB = num(lo)
+			}
+			if !math.IsInf(hi, 1) {
+				con.UB = num(hi)
+			}
+			data.Constraints.Cons = append(data.Constraints.Cons, con)
+			addQuad(i, e)
+		}
+		for _, row := range splitRows(ConstraintMatrix(lp)) {
+			for _, ent := range row {
+				lin.ColIdx = append(lin.ColIdx, strconv.Itoa(ent.Col))
+				lin.Value = append(lin.Value, num(ent.Value))
+			}
+			lin.Start = append(lin.Start, strconv.Itoa(len(lin.Value)))
+		}
+		if lin.N = len(lin.Value); lin.N > 0 {
+			data.Linear = lin
+		}
+	}
+	if len(quad) > 0 {
+		data.Quadratic = &osilQuadratic{N: len(quad), Terms: quad}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// splitRows returns the entries of m grouped by row.
+func splitRows(m *Matrix) [][]Entry {
+	rows := make([][]Entry, len(m.Rows))
+	for _, e := range m.Entries {
+		rows[e.Row] = append(rows[e.Row], e)
+	}
+	return rows
+}
@@ -0,0 +1,1125 @@
+package lp
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+)
+
+type secKind int
+
+const (
+	secNone secKind = iota
+	secObjective
+	secConstraints
+	secBounds
+	secGeneral
+	secBinary
+	secSemiCont
+	secSemiInt
+	secCustomCont
+	secGenCons // Gurobi general constraints
+	secPWLObj  // Gurobi piecewise-linear objective
+	secSOS
+	secEnd
+)
+
+// A header starts a new section.
+type header struct {
+	kind  secKind
+	sense Sense // for objective sections
+	multi bool  // Gurobi "multi-objectives" objective section
+	sos   int   // set type for old-style SOS1 and SOS2 sections
+	rest  string
+}
+
+// sectionHeader reports whether line starts a new section.
+// If so, it returns the header with the remainder of the line.
+func sectionHeader(line string) (header, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return header{}, false
+	}
+	var h header
+	// skip drops the next field from rest if it matches one of words.
+	skip := func(words ...string) {
+		if len(fields) < 2 {
+			return
+		}
+		for _, w := range words {
+			if strings.EqualFold(fields[1], w) {
+				h.rest = strings.TrimSpace(h.rest[len(fields[1]):])
+				fields = fields[1:]
+				return
+			}
+		}
+	}
+	h.rest = strings.TrimSpace(line[strings.Index(line, fields[0])+len(fields[0]):])
+	switch strings.ToUpper(fields[0]) {
+	case "MIN", "MINIMIZE", "MINIMUM":
+		h.kind, h.sense = secObjective, Minimize
+	case "MAX", "MAXIMIZE", "MAXIMUM":
+		h.kind, h.sense = secObjective, Maximize
+	case "SUBJECT", "SUCH":
+		h.kind = secConstraints
+		skip("TO", "THAT")
+	case "S.T", "S.T.", "ST", "ST.":
+		h.kind = secConstraints
+	case "BOUNDS", "BOUND":
+		h.kind = secBounds
+	case "GENERAL", "GEN", "GENERALS":
+		h.kind = secGeneral
+		if len(fields) >= 2 && strings.HasPrefix(strings.ToUpper(fields[1]), "CONSTRAINT") {
+			h.kind = secGenCons
+			skip(fields[1])
+		}
+	case "GENCONS":
+		h.kind = secGenCons
+	case "PWLOBJ":
+		h.kind = secPWLObj
+	case "SOS":
+		h.kind = secSOS
+	case "SOS1":
+		h.kind, h.sos = secSOS, 1
+	case "SOS2":
+		h.kind, h.sos = secSOS, 2
+	case "BINARY", "BIN", "BINARIES":
+		h.kind = secBinary
+	case "SEMI-CONTINUOUS", "SEMI", "SEMIS":
+		h.kind = secSemiCont
+	case "SEMI-INTEGER", "SEMI-INTEGERS", "SEMI-INT":
+		h.kind = secSemiInt
+	case "CONTINUOUS":
+		h.kind = secCustomCont
+	case "END":
+		h.kind = secEnd
+	default:
+		return header{}, false
+	}
+	if h.kind == secObjective && len(fields) >= 2 && strings.EqualFold(fields[1], "multi-objectives") {
+		h.multi = true
+		skip(fields[1])
+	}
+	return h, true
+}
+
+// headerSpellings are the section headers that misspelled headers
+// are compared to, as suggested to fix them.
+var headerSpellings = []string{
+	"Minimize", "Maximize", "Subject To", "Such That", "Bounds",
+	"General", "Generals", "Binary", "Binaries",
+	"Semi-Continuous", "Semi-Integer", "Continuous",
+}
+
+// SuggestHeader reports whether line, without any trailing comment,
+// is a misspelling of a section header, and if so returns the header.
+func SuggestHeader(line string) (string, bool) {
+	return misspelledHeader(line, secNone)
+}
+
+// misspelledHeader is like SuggestHeader for a line in a section of the given kind.
+// Lines in declaration sections are names, which may look like anything.
+func misspelledHeader(line string, kind secKind) (string, bool) {
+	switch kind {
+	case secGeneral, secBinary, secSemiCont, secSemiInt, secCustomCont:
+		return "", false
+	}
+	word := uncommented(line)
+	if word == "" || len(strings.Fields(word)) > 2 {
+		return "", false
+	}
+	if _, ok := sectionHeader(word); ok {
+		return "", false
+	}
+	norm := func(s string) string { return strings.ToUpper(strings.Join(strings.Fields(s), "")) }
+	word = norm(word)
+	best, bestDist := "", 3
+	for _, h := range headerSpellings {
+		// Short words are too easily a name that is a couple of edits away.
+		if d := editDistance(word, norm(h)); d < bestDist && len(h) >= 6 {
+			best, bestDist = h, d
+		}
+	}
+	return best, best != ""
+}
+
+// uncommented returns line without any trailing comment or space.
+func uncommented(line string) string {
+	if i := strings.IndexByte(line, '\\'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+// editDistance returns the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			d := prev[j-1]
+			if a[i-1] != b[j-1] {
+				d++
+			}
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// ParseReader parses an LP file from r. name is used in positions.
+//
+// Sections are split into lines and tokenized first.
+// Once a section ends, its tokens are parsed as a whole
+// since statements may span multiple lines.
+//
+// Statements with syntax errors are skipped up to the end of the line.
+// All errors are returned in an ErrorList along with the rest of the model.
+//
+// Files that start with GLPK's "\* Problem: ... *\" comment
+// are parsed as if by ParseGLPK.
+func ParseReader(name string, r io.Reader) (*LP, error) {
+	return parseLP(name, r, false, DefaultLimits)
+}
+
+// ParseGLPK parses an LP file written by GLPK from r.
+// Unlike ParseReader, it treats variables that are not declared
+// as continuous, since GLPK does not list them in any section.
+func ParseGLPK(name string, r io.Reader) (*LP, error) {
+	return parseLP(name, r, true, DefaultLimits)
+}
+
+// isGLPKHeader reports whether line is the comment
+// that GLPK writes at the start of LP files.
+func isGLPKHeader(line string) bool {
+	return strings.HasPrefix(line, "\\* Problem:") && strings.HasSuffix(line, "*\\")
+}
+
+func parseLP(name string, r io.Reader, glpk bool, lim Limits) (*LP, error) {
+	var (
+		lp     LP
+		errs   ErrorList
+		hdr    header
+		secAt  Pos
+		toks   []token
+		sawAny bool
+		stray  bool // reported content outside a section
+	)
+	flush := func() {
+		p := parser{lp: &lp, toks: toks, kind: hdr.kind, maxVarLen: lim.MaxVarLen, nameChars: lim.NameChars, errs: &errs}
+		switch hdr.kind {
+		case secObjective:
+			if hdr.multi {
+				p.parseMultiObjective(hdr.sense, secAt)
+			} else {
+				p.parseObjective(hdr.sense, secAt)
+			}
+		case secGenCons:
+			p.stmts(p.parseGenConstraint)
+		case secPWLObj:
+			p.stmts(p.parsePWLObj)
+		case secSOS:
+			p.stmts(func() error { return p.parseSOS(hdr.sos) })
+		case secConstraints:
+			p.stmts(p.parseConstraint)
+		case secBounds:
+			p.stmts(p.parseBound)
+		case secGeneral:
+			p.parseDecls(&lp.GeneralVars)
+		case secBinary:
+			p.parseDecls(&lp.BinaryVars)
+		case secSemiCont:
+			p.parseDecls(&lp.SemiContVars)
+		case secSemiInt:
+			p.parseDecls(&lp.SemiIntVars)
+		case secCustomCont:
+			p.parseDecls(&lp.CustomContVars)
+		}
+		toks = nil
+	}
+
+	pos := Pos{File: name}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		pos.Line++
+		if n := len(s.Text()); lim.MaxLineLen > 0 && n > lim.MaxLineLen {
+			// Keep going: the line is likely fine otherwise.
+			over := Pos{File: name, Line: pos.Line, Col: int32(lim.MaxLineLen) + 1, EndCol: int32(n) + 1}
+			errs.add(errorFor(checkLineTooLong, over, "line too long (%d > %d)", n, lim.MaxLineLen))
+		}
+		t := strings.TrimSpace(s.Text())
+		if !sawAny && t != "" {
+			sawAny = true
+			glpk = glpk || isGLPKHeader(t)
+		}
+		if i := strings.IndexByte(t, '\\'); i >= 0 && lp.addIgnore(t[i+1:], pos, i > 0, &errs) && i == 0 {
+			// Not an \lpvet: line to parse; the comment is the whole line.
+			t = ""
+		}
+		if strings.HasPrefix(t, "\\lpvet:") {
+			t = strings.TrimSpace(strings.TrimPrefix(t, "\\lpvet:"))
+		}
+		h, ok := sectionHeader(t)
+		hdrText, meant := "", ""
+		if ok {
+			hdrText = strings.TrimSpace(t[:len(t)-len(h.rest)])
+		} else if kw, near := misspelledHeader(t, hdr.kind); near {
+			// Read the line as the header it was meant to be,
+			// rather than report every statement after it.
+			h, ok = sectionHeader(kw)
+			hdrText, meant = uncommented(t), kw
+			h.rest = strings.TrimSpace(t[len(hdrText):])
+		}
+		if ok {
+			flush()
+			hdr, secAt, stray = h, pos, false
+			// As below, t is a suffix of the line.
+			hpos := pos
+			hpos.Col = int32(len(strings.TrimRightFunc(s.Text(), unicode.IsSpace))-len(t)) + 1
+			hpos.EndCol = hpos.Col + int32(len(hdrText))
+			if meant != "" {
+				errs.add(errorFor(checkMisspelled, hpos, "misspelled section header %q (did you mean %q?)", hdrText, meant))
+			}
+			lp.Headers = append(lp.Headers, Symbol{Value: hdrText, Pos: hpos})
+			t = h.rest
+		}
+		// Everything trimmed from t so far was at the start of the line
+		// or trailing space.
+		at := pos
+		at.Col = int32(len(strings.TrimRightFunc(s.Text(), unicode.IsSpace))-len(t)) + 1
+		if i := strings.IndexByte(t, '\\'); i >= 0 {
+			t = t[:i]
+		}
+		punct := ""
+		switch hdr.kind {
+		case secObjective, secConstraints:
+			punct = "[]^*/"
+		case secGenCons, secPWLObj:
+			punct = "(),"
+		}
+		lineToks, err := lex(t, at, punct)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+		if len(lineToks) == 0 {
+			continue
+		}
+		if hdr.kind == secNone || hdr.kind == secEnd {
+			// Report only the first line, not every line of a misformatted file.
+			if !stray {
+				hint := ""
+				if len(lp.Headers) == 0 {
+					hint = " (LP files start with Minimize or Maximize)"
+				}
+				errs.add(errorAt(lineToks[0].pos, "not in a section%s", hint))
+				stray = true
+			}
+			continue
+		}
+		toks = append(toks, lineToks...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	if glpk {
+		declareImplicit(&lp)
+	}
+	return &lp, errs.err()
+}
+
+type parser struct {
+	lp   *LP
+	toks []token
+	i    int
+	kind secKind
+
+	validName func(string) bool // validVarName if nil
+	maxVarLen int               // of names, or 0 for no limit
+	nameChars string            // allowed in names in addition to validName's
+
+	errs *ErrorList // errors recovered from by stmts
+}
+
+func (p *parser) done() bool { return p.i >= len(p.toks) }
+
+func (p *parser) peek() *token {
+	if p.done() {
+		return nil
+	}
+	return &p.toks[p.i]
+}
+
+func (p *parser) peekKind(k tokKind) bool {
+	t := p.peek()
+	return t != nil && t.kind == k
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	p.i++
+	return t
+}
+
+// errorf reports an error at the current token,
+// or at the last token if all have been consumed.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	var pos Pos
+	switch {
+	case !p.done():
+		pos = p.toks[p.i].pos
+	case len(p.toks) > 0:
+		pos = p.toks[len(p.toks)-1].pos
+	}
+	return errorAt(pos, format, args...)
+}
+
+// stmts calls parse until all tokens are consumed.
+// When parse fails, stmts records the error and skips
+// to the next line, where the next statement likely starts.
+func (p *parser) stmts(parse func() error) {
+	for !p.done() {
+		start := p.i
+		if err := parse(); err != nil {
+			p.errs.add(err)
+			p.skipLine(start)
+		}
+	}
+}
+
+// skipLine skips the rest of the current line,
+// consuming at least the token at start.
+func (p *parser) skipLine(start int) {
+	if p.i <= start {
+		p.i = start + 1
+	}
+	for !p.done() && p.toks[p.i].pos.Line == p.toks[p.i-1].pos.Line {
+		p.i++
+	}
+}
+
+// numEndsLine reports whether the next tokens are a signed number
+// that ends its line, so that nothing on a later line can be a term
+// that it multiplies.
+func (p *parser) numEndsLine() bool {
+	i := p.i
+	for i < len(p.toks) && (p.toks[i].kind == tokPlus || p.toks[i].kind == tokMinus) {
+		i++
+	}
+	if i >= len(p.toks) || p.toks[i].kind != tokNum || p.toks[i].name != "" {
+		return false
+	}
+	return i+1 == len(p.toks) || p.toks[i+1].pos.Line > p.toks[i].pos.Line
+}
+
+func (p *parser) unexpected(want string) error {
+	if p.done() {
+		return p.errorf("expected %s, found end of section", want)
+	}
+	t := p.peek()
+	if t.name != "" && strings.Contains(want, "name") {
+		return t.nameError()
+	}
+	if p.i > 0 && p.toks[p.i-1].name != "" && t.kind == tokIdent {
+		// The name is the end of one that starts with a digit.
+		return p.toks[p.i-1].nameError()
+	}
+	return p.errorf("expected %s, found %s %q", want, t.kind, t.text)
+}
+
+// sec returns the section that records variable uses,
+// or nil if uses should not be recorded.
+func (p *parser) sec() *Section {
+	switch p.kind {
+	case secObjective, secPWLObj:
+		return &p.lp.Objective
+	case secConstraints, secGenCons:
+		return &p.lp.Constraints
+	case secBounds:
+		return &p.lp.Bounds
+	}
+	return nil
+}
+
+// use records a use of sym in the current section.
+func (p *parser) use(sym Symbol) {
+	if s := p.sec(); s != nil {
+		s.AddSym(sym)
+	}
+}
+
+// sym validates the variable name in t and returns its symbol.
+func (p *parser) sym(t token) (Symbol, error) {
+	if p.maxVarLen > 0 && len(t.text) > p.maxVarLen {
+		return Symbol{}, errorFor(checkNameTooLong, t.pos, "variable too long: %q (%d > %d)", t.text, len(t.text), p.maxVarLen)
+	}
+	if c, ok := p.invalidChar(t.text); ok {
+		return Symbol{}, errorFor(checkInvalidName, t.pos, "invalid variable name: %q (%q is not allowed)", t.text, c)
+	}
+	return Symbol{Value: t.text, Pos: t.pos}, nil
+}
+
+// label consumes a "name:" prefix if present.
+// Invalid names are reported, but the statement is still parsed.
+func (p *parser) label() string {
+	if p.i+2 < len(p.toks) && p.toks[p.i].name != "" && p.toks[p.i+1].kind == tokIdent && p.toks[p.i+2].kind == tokColon {
+		// A label starting with a digit, such as 1c, lexed as 1 c.
+		t := p.toks[p.i]
+		p.i += 3
+		p.errs.add(t.nameError())
+		return t.name
+	}
+	if p.i+1 < len(p.toks) && p.toks[p.i].kind == tokIdent && p.toks[p.i+1].kind == tokColon {
+		t := p.toks[p.i]
+		p.i += 2
+		if err := p.checkLabel(t); err != nil {
+			p.errs.add(err)
+		}
+		if p.kind != secSOS {
+			p.lp.RowNames.AddSym(Symbol{Value: t.text, Pos: t.pos})
+		}
+		return t.text
+	}
+	return ""
+}
+
+// checkLabel checks the name of an objective or constraint
+// against the rules for variable names, and that it is not a keyword.
+func (p *parser) checkLabel(t token) error {
+	what := "constraint"
+	if p.kind == secObjective {
+		what = "objective"
+	}
+	if p.maxVarLen > 0 && len(t.text) > p.maxVarLen {
+		return errorFor(checkNameTooLong, t.pos, "%s name too long: %q (%d > %d)", what, t.text, len(t.text), p.maxVarLen)
+	}
+	if c, ok := p.invalidChar(t.text); ok {
+		valid := func(s string) bool { _, bad := p.invalidChar(s); return !bad }
+		return errorFor(checkInvalidName, t.pos, "invalid %s name: %q (%q is not allowed; use %q)", what, t.text, c, sanitizeName(t.text, valid))
+	}
+	if p.validName == nil && isKeyword(t.text) {
+		return errorFor(checkInvalidName, t.pos, "%s name %q is a keyword (use %q)", what, t.text, t.text+"_")
+	}
+	return nil
+}
+
+// invalidChar returns the first character of name that names cannot contain.
+func (p *parser) invalidChar(name string) (rune, bool) {
+	valid := validVarName
+	if p.validName != nil {
+		valid = p.validName
+	}
+	for _, c := range name {
+		if !valid(string(c)) && !strings.ContainsRune(p.nameChars, c) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// isKeyword reports whether name reads as a section header
+// or a word with a meaning of its own in LP files.
+func isKeyword(name string) bool {
+	if _, ok := sectionHeader(name); ok {
+		return true
+	}
+	switch strings.ToLower(name) {
+	case "free", "inf", "infinity":
+		return true
+	}
+	return false
+}
+
+// sanitizeName returns name with the characters that valid rejects
+// replaced by underscores.
+func sanitizeName(name string, valid func(string) bool) string {
+	return strings.Map(func(r rune) rune {
+		if !valid(string(r)) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func (p *parser) parseObjective(sense Sense, at Pos) {
+	obj := &Objective{Sense: sense, Pos: at}
+	if !p.done() {
+		obj.Pos = p.peek().pos
+	}
+	obj.Name = p.label()
+	p.stmts(func() error {
+		if err := p.addExpr(&obj.Expr); err != nil {
+			return err
+		}
+		if !p.done() {
+			return p.unexpected("'+' or '-'")
+		}
+		return nil
+	})
+	p.lp.Obj = obj
+}
+
+// parseMultiObjective parses a Gurobi multi-objective section.
+// Each objective starts with a name and its parameters,
+// e.g. "Obj1: Priority=2 Weight=1", followed by its expression.
+func (p *parser) parseMultiObjective(sense Sense, at Pos) {
+	p.stmts(func() error {
+		obj := &Objective{Sense: sense, Pos: p.peek().pos}
+		if obj.Name = p.label(); obj.Name == "" {
+			return p.unexpected("objective name")
+		}
+		for p.i+1 < len(p.toks) && p.peekKind(tokIdent) && p.toks[p.i+1].kind == tokRel && p.toks[p.i+1].rel == RelEQ {
+			param := p.next().text
+			p.next()
+			v, err := p.parseNum()
+			if err != nil {
+				return err
+			}
+			if obj.Params == nil {
+				obj.Params = make(map[string]float64)
+			}
+			obj.Params[param] = v
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		obj.Expr = e
+		p.lp.MultiObj = append(p.lp.MultiObj, obj)
+		return nil
+	})
+	if len(p.lp.MultiObj) == 0 {
+		p.lp.MultiObj = append(p.lp.MultiObj, &Objective{Sense: sense, Pos: at})
+	}
+	p.lp.Obj = p.lp.MultiObj[0]
+}
+
+// parseConstraint parses a single constraint.
+func (p *parser) parseConstraint() error {
+	c := &Constraint{Pos: p.peek().pos}
+	c.Name = p.label()
+	ind, err := p.parseIndicator()
+	if err != nil {
+		return err
+	}
+	c.Indicator = ind
+	lhs, err := p.parseExpr()
+	if err != nil {
+		return err
+	}
+	if !p.peekKind(tokRel) {
+		return p.unexpected("relation")
+	}
+	c.LHS = lhs
+	c.Rel = p.next().rel
+	if len(lhs.Terms) == 0 && len(lhs.Quad) == 0 {
+		if p.numEndsLine() {
+			// Two constants, as in "4 >= 7", which trivialRows reports.
+			rhs, err := p.parseNum()
+			if err != nil {
+				return err
+			}
+			c.RHS = Expr{Constant: rhs}
+			p.lp.Rows = append(p.lp.Rows, c)
+			return nil
+		}
+		// Either "lo <= expr <= hi" or a constant on the left.
+		mid, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		if p.peekKind(tokRel) {
+			rel2 := p.next().rel
+			hi, err := p.parseNum()
+			if err != nil {
+				return err
+			}
+			if err := c.setRange(lhs.Constant, mid, rel2, hi); err != nil {
+				return err
+			}
+		} else {
+			c.RHS = mid
+		}
+		p.lp.Rows = append(p.lp.Rows, c)
+		return nil
+	}
+	rhs, err := p.parseNum()
+	if err != nil {
+		return err
+	}
+	c.RHS = Expr{Constant: rhs}
+	p.lp.Rows = append(p.lp.Rows, c)
+	return nil
+}
+
+// setRange makes c the ranged row "a c.Rel e rel2 b".
+// Both relations must be <= or both must be >=.
+func (c *Constraint) setRange(a float64, e Expr, rel2 Rel, b float64) error {
+	if c.Rel != rel2 || c.Rel == RelEQ {
+		return errorAt(c.Pos, "ranged constraint must use two <= or two >= relations")
+	}
+	lo, hi := a, b
+	if c.Rel == RelGE {
+		lo, hi = b, a
+	}
+	c.LHS = e
+	c.Rel = RelLE
+	c.RHS = Expr{Constant: hi - e.Constant}
+	c.LHS.Constant = 0
+	c.Ranged = true
+	c.RangeLo = lo - e.Constant
+	return nil
+}
+
+// parseIndicator parses the "b = 1 ->" prefix of an indicator constraint,
+// returning nil if there is none.
+func (p *parser) parseIndicator() (*Indicator, error) {
+	if p.i+3 >= len(p.toks) || p.toks[p.i].kind != tokIdent || p.toks[p.i+1].kind != tokRel ||
+		p.toks[p.i+1].rel != RelEQ || p.toks[p.i+2].kind != tokNum || p.toks[p.i+3].kind != tokArrow {
+		return nil, nil
+	}
+	sym, err := p.sym(p.next())
+	if err != nil {
+		return nil, err
+	}
+	p.next()
+	v := p.next()
+	if v.num != 0 && v.num != 1 {
+		return nil, errorAt(v.pos, "indicator value must be 0 or 1, not %s", v.text)
+	}
+	arrow := p.next()
+	p.use(sym)
+	return &Indicator{Var: sym, Value: int(v.num), Equiv: arrow.text == "<->"}, nil
+}
+
+// parseGenConstraint parses a Gurobi general constraint
+// of the form "[name:] r = FUNC ( args )".
+func (p *parser) parseGenConstraint() error {
+	g := &GenConstraint{Pos: p.peek().pos}
+	g.Name = p.label()
+	if !p.peekKind(tokIdent) {
+		return p.unexpected("variable name")
+	}
+	var err error
+	if g.Result, err = p.sym(p.next()); err != nil {
+		return err
+	}
+	p.use(g.Result)
+	if t := p.peek(); t == nil || t.kind != tokRel || t.rel != RelEQ {
+		return p.unexpected("'='")
+	}
+	p.next()
+	if !p.peekKind(tokIdent) {
+		return p.unexpected("function name")
+	}
+	g.Func = strings.ToUpper(p.peek().text)
+	switch g.Func {
+	case "MAX", "MIN", "ABS", "AND", "OR":
+	default:
+		return p.errorf("unknown general constraint function %q", p.peek().text)
+	}
+	p.next()
+	if !p.peekKind(tokLParen) {
+		return p.unexpected("'('")
+	}
+	p.next()
+	for {
+		switch {
+		case p.peekKind(tokIdent):
+			sym, err := p.sym(p.next())
+			if err != nil {
+				return err
+			}
+			p.use(sym)
+			g.Args = append(g.Args, sym)
+		case g.Func == "MAX" || g.Func == "MIN":
+			v, err := p.parseNum()
+			if err != nil {
+				return err
+			}
+			g.Constants = append(g.Constants, v)
+		default:
+			return p.unexpected("variable name")
+		}
+		if p.peekKind(tokComma) {
+			p.next()
+			continue
+		}
+		if !p.peekKind(tokRParen) {
+			return p.unexpected("',' or ')'")
+		}
+		p.next()
+		break
+	}
+	if g.Func == "ABS" && (len(g.Args) != 1 || len(g.Constants) != 0) {
+		return errorAt(g.Pos, "ABS takes exactly one variable")
+	}
+	p.lp.GenCons = append(p.lp.GenCons, g)
+	return nil
+}
+
+// parsePWLObj parses a Gurobi piecewise-linear objective term
+// of the form "x: (x1, y1) (x2, y2) ...".
+func (p *parser) parsePWLObj() error {
+	pw := &PWLObj{Pos: p.peek().pos}
+	if !p.peekKind(tokIdent) {
+		return p.unexpected("variable name")
+	}
+	var err error
+	if pw.Var, err = p.sym(p.next()); err != nil {
+		return err
+	}
+	p.use(pw.Var)
+	if !p.peekKind(tokColon) {
+		return p.unexpected("':'")
+	}
+	p.next()
+	for p.peekKind(tokLParen) {
+		p.next()
+		var pt [2]float64
+		if pt[0], err = p.parseNum(); err != nil {
+			return err
+		}
+		if !p.peekKind(tokComma) {
+			return p.unexpected("','")
+		}
+		p.next()
+		if pt[1], err = p.parseNum(); err != nil {
+			return err
+		}
+		if !p.peekKind(tokRParen) {
+			return p.unexpected("')'")
+		}
+		p.next()
+		pw.Points = append(pw.Points, pt)
+	}
+	if len(pw.Points) == 0 {
+		return p.unexpected("'('")
+	}
+	p.lp.PWLObjs = append(p.lp.PWLObjs, pw)
+	return nil
+}
+
+// isSOSMember reports whether the next tokens are "x:weight",
+// where the weight may be signed.
+func (p *parser) isSOSMember() bool {
+	if p.i+2 >= len(p.toks) || p.toks[p.i].kind != tokIdent || p.toks[p.i+1].kind != tokColon {
+		return false
+	}
+	i := p.i + 2
+	for i < len(p.toks) && (p.toks[i].kind == tokPlus || p.toks[i].kind == tokMinus) {
+		i++
+	}
+	return i < len(p.toks) && p.toks[i].kind == tokNum
+}
+
+// parseSOS parses a set of the form "[name:] S1:: x1:1 x2:2 ...".
+// If typ is nonzero, the section only has sets of that type
+// and the "S1::" marker is omitted.
+func (p *parser) parseSOS(typ int) error {
+	s := &SOS{Type: typ, Pos: p.peek().pos}
+	isType := func() bool {
+		return p.i+2 < len(p.toks) && p.toks[p.i].kind == tokIdent &&
+			p.toks[p.i+1].kind == tokColon && p.toks[p.i+2].kind == tokColon
+	}
+	if !p.isSOSMember() && !isType() {
+		s.Name = p.label()
+	}
+	if typ == 0 {
+		if !isType() {
+			return p.unexpected("S1:: or S2::")
+		}
+		switch strings.ToUpper(p.peek().text) {
+		case "S1":
+			s.Type = 1
+		case "S2":
+			s.Type = 2
+		default:
+			return p.errorf("unknown SOS type %q", p.peek().text)
+		}
+		p.i += 3
+	}
+	for p.isSOSMember() {
+		sym, err := p.sym(p.next())
+		if err != nil {
+			return err
+		}
+		p.next()
+		w, err := p.parseNum()
+		if err != nil {
+			return err
+		}
+		p.lp.SOSVars.AddSym(sym)
+		s.Members = append(s.Members, SOSMember{Var: sym, Weight: w})
+	}
+	if len(s.Members) == 0 {
+		return p.unexpected("variable:weight")
+	}
+	p.lp.SOS = append(p.lp.SOS, s)
+	return nil
+}
+
+// parseBound parses a single bound.
+func (p *parser) parseBound() error {
+	b := &Bound{Pos: p.peek().pos}
+	if !p.peekKind(tokIdent) || isInf(p.peek().text) {
+		// lo <= x ...
+		v, err := p.parseNum()
+		if err != nil {
+			return err
+		}
+		if !p.peekKind(tokRel) {
+			return p.unexpected("relation")
+		}
+		b.set(p.next().rel.Flip(), v)
+	}
+	if !p.peekKind(tokIdent) {
+		return p.unexpected("variable name")
+	}
+	sym, err := p.sym(p.next())
+	if err != nil {
+		return err
+	}
+	b.Var = sym
+	p.lp.Bounds.AddSym(sym)
+	if !b.HasLower && !b.HasUpper && p.peekKind(tokIdent) && strings.EqualFold(p.peek().text, "free") {
+		p.next()
+		b.setFree()
+		p.lp.FreeVars.AddSym(sym)
+	} else if p.peekKind(tokRel) {
+		rel := p.next().rel
+		v, err := p.parseNum()
+		if err != nil {
+			return err
+		}
+		b.set(rel, v)
+	} else if !b.HasLower && !b.HasUpper {
+		return p.unexpected("relation")
+	}
+	p.lp.VarBounds = append(p.lp.VarBounds, b)
+	return nil
+}
+
+// setFree makes b a free bound.
+func (b *Bound) setFree() {
+	b.set(RelGE, math.Inf(-1))
+	b.set(RelLE, math.Inf(1))
+	b.Free = true
+}
+
+// set applies "x rel v" to b.
+func (b *Bound) set(rel Rel, v float64) {
+	switch rel {
+	case RelLE:
+		b.Upper, b.HasUpper = v, true
+	case RelGE:
+		b.Lower, b.HasLower = v, true
+	case RelEQ:
+		b.Lower, b.HasLower = v, true
+		b.Upper, b.HasUpper = v, true
+	}
+}
+
+func isInf(s string) bool {
+	switch strings.ToLower(s) {
+	case "inf", "infinity":
+		return true
+	}
+	return false
+}
+
+// parseNum parses a signed number, which may be infinite.
+func (p *parser) parseNum() (float64, error) {
+	sign := p.parseSign()
+	switch {
+	case p.peekKind(tokNum):
+		return sign * p.next().num, nil
+	case p.peekKind(tokIdent) && isInf(p.peek().text):
+		p.next()
+		return sign * math.Inf(1), nil
+	}
+	return 0, p.unexpected("number")
+}
+
+// parseSign consumes any number of '+' and '-' tokens
+// and returns the resulting sign.
+func (p *parser) parseSign() float64 {
+	sign := 1.0
+	for !p.done() {
+		switch p.peek().kind {
+		case tokPlus:
+		case tokMinus:
+			sign = -sign
+		default:
+			return sign
+		}
+		p.next()
+	}
+	return sign
+}
+
+// parseExpr parses a linear expression.
+// The leading sign of the first term is optional.
+func (p *parser) parseExpr() (Expr, error) {
+	var e Expr
+	err := p.addExpr(&e)
+	return e, err
+}
+
+// addExpr parses a linear expression and adds it to e.
+func (p *parser) addExpr(e *Expr) error {
+	first := true
+	for !p.done() {
+		if !first && !p.peekKind(tokPlus) && !p.peekKind(tokMinus) {
+			break
+		}
+		first = false
+		if err := p.parseTerm(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTerm parses a single signed term and adds it to e.
+func (p *parser) parseTerm(e *Expr) error {
+	sign := p.parseSign()
+	if p.peekKind(tokLBrack) {
+		return p.parseQuad(sign, e)
+	}
+	coef, haveCoef := sign, false
+	if p.peekKind(tokNum) {
+		coef *= p.next().num
+		haveCoef = true
+		if p.peekKind(tokNum) && p.peek().name != "" {
+			// A coefficient followed by what looks like another, as in 2 1y.
+			return p.peek().nameError()
+		}
+		if p.peekKind(tokStar) {
+			p.next()
+			if !p.peekKind(tokIdent) {
+				return p.unexpected("variable name")
+			}
+		}
+	}
+	if !p.peekKind(tokIdent) {
+		if !haveCoef {
+			return p.unexpected("number or variable name")
+		}
+		e.Constant += coef
+		return nil
+	}
+	sym, err := p.sym(p.next())
+	if err != nil {
+		return err
+	}
+	p.use(sym)
+	e.Terms = append(e.Terms, Term{Coef: coef, Var: sym})
+	return nil
+}
+
+// parseQuad parses a bracketed block of quadratic terms, such as
+// "[ x ^ 2 + 2 x * y ]", multiplies it by sign, and adds it to e.
+// In the objective, the block must be followed by "/ 2",
+// which is applied to the coefficients.
+// Constraints take the block as is.
+func (p *parser) parseQuad(sign float64, e *Expr) error {
+	open := p.next()
+	var quad []QuadTerm
+	for first := true; !p.peekKind(tokRBrack); first = false {
+		if p.done() {
+			return errorAt(open.pos, "unclosed '['")
+		}
+		if !first && !p.peekKind(tokPlus) && !p.peekKind(tokMinus) {
+			return p.unexpected("'+', '-', or ']'")
+		}
+		q := QuadTerm{Coef: sign * p.parseSign()}
+		if p.peekKind(tokNum) {
+			q.Coef *= p.next().num
+		}
+		if !p.peekKind(tokIdent) {
+			return p.unexpected("variable name")
+		}
+		var err error
+		if q.Var1, err = p.sym(p.next()); err != nil {
+			return err
+		}
+		switch {
+		case p.peekKind(tokCaret):
+			p.next()
+			if !p.peekKind(tokNum) {
+				return p.unexpected("exponent")
+			}
+			if t := p.next(); t.num != 2 {
+				return errorAt(t.pos, "exponent must be 2, not %s", t.text)
+			}
+			q.Var2 = q.Var1
+		case p.peekKind(tokStar):
+			p.next()
+			if !p.peekKind(tokIdent) {
+				return p.unexpected("variable name")
+			}
+			if q.Var2, err = p.sym(p.next()); err != nil {
+				return err
+			}
+		default:
+			return errorAt(q.Var1.Pos, "linear term %s inside quadratic brackets", q.Var1.Value)
+		}
+		p.use(q.Var1)
+		p.use(q.Var2)
+		quad = append(quad, q)
+	}
+	p.next()
+	if p.kind != secObjective {
+		if p.peekKind(tokSlash) {
+			return p.errorf("'/ 2' is only allowed after quadratic objective terms")
+		}
+		e.Quad = append(e.Quad, quad...)
+		return nil
+	}
+	if !p.peekKind(tokSlash) {
+		return p.unexpected("'/ 2' after quadratic objective terms")
+	}
+	p.next()
+	if !p.peekKind(tokNum) || p.peek().num != 2 {
+		return p.unexpected("2 after '/'")
+	}
+	p.next()
+	for i := range quad {
+		quad[i].Coef /= 2
+	}
+	e.Quad = append(e.Quad, quad...)
+	return nil
+}
+
+// parseDecls adds the declared variables to sec.
+// Invalid names are reported and skipped.
+func (p *parser) parseDecls(sec *Section) {
+	for !p.done() {
+		if !p.peekKind(tokIdent) {
+			p.errs.add(p.unexpected("variable name"))
+			p.next()
+			continue
+		}
+		sym, err := p.sym(p.next())
+		if err != nil {
+			p.errs.add(err)
+			continue
+		}
+		sec.AddSym(sym)
+	}
+}
@@ -0,0 +1,199 @@
+package lp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// summary returns the objective, rows, bounds, and integer variables of lp,
+// one per line.
+func summary(lp *LP) string {
+	var b strings.Builder
+	if lp.Obj != nil {
+		fmt.Fprintf(&b, "%s %s: %s\n", lp.Obj.Sense, lp.Obj.Name, lp.Obj.Expr)
+	}
+	for _, c := range lp.Rows {
+		if c.Ranged {
+			fmt.Fprintf(&b, "%s: %s <= %s <= %s\n", c.Name, formatNum(c.RangeLo), c.LHS, c.RHS)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s %s %s\n", c.Name, c.LHS, c.Rel, c.RHS)
+	}
+	for _, bd := range lp.VarBounds {
+		switch {
+		case bd.Free:
+			fmt.Fprintf(&b, "%s free\n", bd.Var.Value)
+		case bd.HasLower && bd.HasUpper:
+			fmt.Fprintf(&b, "%s <= %s <= %s\n", formatNum(bd.Lower), bd.Var.Value, formatNum(bd.Upper))
+		case bd.HasLower:
+			fmt.Fprintf(&b, "%s >= %s\n", bd.Var.Value, formatNum(bd.Lower))
+		case bd.HasUpper:
+			fmt.Fprintf(&b, "%s <= %s\n", bd.Var.Value, formatNum(bd.Upper))
+		}
+	}
+	for _, sec := range []struct {
+		name string
+		sec  *Section
+	}{{"general", &lp.GeneralVars}, {"binary", &lp.BinaryVars}} {
+		if syms := sec.sec.Syms(); len(syms) > 0 {
+			var names []string
+			for _, sym := range syms {
+				names = append(names, sym.Value)
+			}
+			fmt.Fprintf(&b, "%s %s\n", sec.name, strings.Join(names, " "))
+		}
+	}
+	return b.String()
+}
+
+func TestParseLP(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		model string
+		want  string
+	}{
+		{
+			"basic",
+			"Minimize\n obj: 2 x + 3 y\nSubject To\n c1: x + y >= 1\n c2: x - y <= 4\nEnd\n",
+			"minimize obj: 2 x + 3 y\nc1: x + y >= 1\nc2: x - y <= 4\n",
+		},
+		{
+			"abbreviated headers",
+			"max\n x\nst\n x <= 3\nend\n",
+			"maximize : x\n: x <= 3\n",
+		},
+		{
+			"multi-line constraint",
+			"Minimize\n obj: x\nSubject To\n c1: x\n + y\n >= 2\nEnd\n",
+			"minimize obj: x\nc1: x + y >= 2\n",
+		},
+		{
+			"constant in objective",
+			"Minimize\n obj: x + 5\nSubject To\n c1: x >= 1\nEnd\n",
+			"minimize obj: x + 5\nc1: x >= 1\n",
+		},
+		{
+			"range constraint",
+			"Minimize\n obj: x\nSubject To\n r1: -2 <= x + y <= 3\nEnd\n",
+			"minimize obj: x\nr1: -2 <= x + y <= 3\n",
+		},
+		{
+			"bounds",
+			"Minimize\n obj: x + y + z + w\nSubject To\n c1: x + y + z + w >= 1\nBounds\n 0 <= x <= 4\n y >= -1\n z free\n w <= inf\nEnd\n",
+			"minimize obj: x + y + z + w\nc1: x + y + z + w >= 1\n0 <= x <= 4\ny >= -1\nz free\nw <= inf\n",
+		},
+		{
+			"declarations",
+			"Minimize\n obj: x + y\nSubject To\n c1: x + y >= 1\nGeneral\n x\nBinary\n y\nEnd\n",
+			"minimize obj: x + y\nc1: x + y >= 1\ngeneral x\nbinary y\n",
+		},
+		{
+			"coefficient without a space",
+			"Minimize\n obj: x\nSubject To\n c1: x + 2y3 >= 1\nEnd\n",
+			"minimize obj: x\nc1: x + 2 y3 >= 1\n",
+		},
+		{
+			"comments",
+			"\\ a model\nMinimize\n obj: x \\ the cost\nSubject To\n c1: x >= 1\nEnd\n",
+			"minimize obj: x\nc1: x >= 1\n",
+		},
+	} {
+		lp, err := ParseReader("m.lp", strings.NewReader(tt.model))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := summary(lp); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseLPErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		model string
+		want  []string // errors as line:col: message [check]
+	}{
+		{
+			"missing relation",
+			"Minimize\n obj: x\nSubject To\n c1: x + y\n c2: x >= 1\nEnd\n",
+			[]string{`5:2: expected relation, found name "c2" [LP011]`},
+		},
+		{
+			"not in a section",
+			"x + y\nMinimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n",
+			[]string{"1:1: not in a section (LP files start with Minimize or Maximize) [LP011]"},
+		},
+		{
+			"name starting with a digit",
+			"Minimize\n obj: x\nSubject To\n 1c: x >= 1\nEnd\n",
+			[]string{`4:2: name cannot start with a digit or period: "1c" (use "_1c") [LP012]`},
+		},
+		{
+			"misspelled header",
+			"Minimize\n obj: x\nSubjet To\n c1: x >= 1\nEnd\n",
+			[]string{`3:1: misspelled section header "Subjet To" (did you mean "Subject To"?) [LP014]`},
+		},
+		{
+			"missing right-hand sides",
+			"Minimize\n obj: x\nSubject To\n c1: x >=\n c2: x >= 1\n c3: >= 2\nEnd\n",
+			[]string{
+				`5:2: expected number, found name "c2" [LP011]`,
+				`6:6: expected number or variable name, found relation ">=" [LP011]`,
+			},
+		},
+	} {
+		_, err := ParseReader("m.lp", strings.NewReader(tt.model))
+		var got []string
+		if errs, ok := err.(ErrorList); ok {
+			for _, e := range errs {
+				got = append(got, strings.TrimPrefix(e.Error(), "m.lp:"))
+			}
+		} else if err != nil {
+			t.Errorf("%s: got %T error %v, want an ErrorList", tt.name, err, err)
+			continue
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got errors\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestParseSOSSignedWeights(t *testing.T) {
+	for _, tt := range []struct {
+		set  string
+		want []float64
+		diag string // message of the LP006 diagnostic, if any
+	}{
+		{"s1: S1:: x:-1 y:+2 z:3", []float64{-1, 2, 3}, ""},
+		{"s1: S2:: x:-2 y:-1", []float64{-2, -1}, ""},
+		{"s1: S2:: x:-1 y:-3", []float64{-1, -3}, "SOS weights must be strictly increasing: y has weight -3 after -1"},
+	} {
+		model := "Minimize\n obj: x + y + z\nSubject To\n c1: x + y + z >= 1\nSOS\n " + tt.set + "\nEnd\n"
+		lp, err := ParseReader("m.lp", strings.NewReader(model))
+		if err != nil {
+			t.Errorf("%s: %v", tt.set, err)
+			continue
+		}
+		if len(lp.SOS) != 1 || len(lp.SOS[0].Members) != len(tt.want) {
+			t.Errorf("%s: got %d sets, want 1 with %d members", tt.set, len(lp.SOS), len(tt.want))
+			continue
+		}
+		for i, m := range lp.SOS[0].Members {
+			if m.Weight != tt.want[i] {
+				t.Errorf("%s: weight of %s = %v, want %v", tt.set, m.Var.Value, m.Weight, tt.want[i])
+			}
+		}
+		diag := ""
+		for _, d := range Vet(lp, Options{}) {
+			if d.Check == checkSOSWeights {
+				diag = d.Message
+			}
+		}
+		if diag != tt.diag {
+			t.Errorf("%s: got LP006 %q, want %q", tt.set, diag, tt.diag)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package lp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A Profile describes the limits and extensions of a particular solver.
+// Vet uses it to report constructs that the solver would reject.
+type Profile struct {
+	Name    string
+	Dialect string // dialect of .lp files: cplex, glpk, or lpsolve
+
+	MaxLineLen int // 0 for no limit
+	MaxVarLen  int // 0 for no limit
+
+	QuadObj      bool
+	QuadCons     bool
+	Indicators   bool
+	Equivalences bool // "<->" indicators
+	GenCons      bool
+	MultiObj     bool
+	PWLObj       bool
+	SOS          bool
+	SemiCont     bool
+	SemiInt      bool
+	Ranged       bool
+	OptionalObj  bool // files may leave out the objective section
+}
+
+var profiles = map[string]*Profile{
+	"cplex": {
+		Name:         "cplex",
+		Dialect:      "cplex",
+		MaxLineLen:   510,
+		MaxVarLen:    255,
+		QuadObj:      true,
+		QuadCons:     true,
+		Indicators:   true,
+		Equivalences: true,
+		MultiObj:     true,
+		SOS:          true,
+		SemiCont:     true,
+		Ranged:       true,
+	},
+	"gurobi": {
+		Name:       "gurobi",
+		Dialect:    "cplex",
+		MaxVarLen:  255,
+		QuadObj:    true,
+		QuadCons:   true,
+		Indicators: true,
+		GenCons:    true,
+		MultiObj:   true,
+		PWLObj:     true,
+		SOS:        true,
+		SemiCont:   true,
+		SemiInt:    true,
+	},
+	"lpsolve": {
+		Name:     "lpsolve",
+		Dialect:  "lpsolve",
+		SemiCont: true,
+		SemiInt:  true,
+		Ranged:   true,
+	},
+	"glpk": {
+		Name:       "glpk",
+		Dialect:    "glpk",
+		MaxLineLen: 255,
+		MaxVarLen:  255,
+		Ranged:     true,
+	},
+	"scip": {
+		Name:       "scip",
+		Dialect:    "cplex",
+		QuadObj:    true,
+		QuadCons:   true,
+		Indicators: true,
+		SOS:        true,
+		SemiCont:   true,
+		Ranged:     true,
+	},
+	"highs": {
+		Name:        "highs",
+		Dialect:     "cplex",
+		QuadObj:     true,
+		SOS:         true,
+		SemiCont:    true,
+		SemiInt:     true,
+		Ranged:      true,
+		OptionalObj: true,
+	},
+}
+
+// LookupProfile returns the profile of the named solver.
+func LookupProfile(name string) (*Profile, error) {
+	if p, ok := profiles[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown solver %q", name)
+}
+
+// ProfileNames returns the names of all known solvers.
+func ProfileNames() []string {
+	var names []string
+	for n := range profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkProfile reports constructs in lp that prof does not support.
+// Vet checks prof.MaxVarLen itself.
+// Each unsupported feature is reported once, at its first use.
+func checkProfile(lp *LP, prof *Profile) []Diagnostic {
+	var diags []Diagnostic
+	unsupported := func(ok bool, pos Pos, what string) {
+		if !ok {
+			diags = append(diags, Diagnostic{
+				Pos:      pos,
+				Severity: Error,
+				Check:    checkUnsupported,
+				Message:  fmt.Sprintf("%s not supported by %s", what, prof.Name),
+			})
+		}
+	}
+
+	if lp.Obj != nil && len(lp.Obj.Expr.Quad) > 0 {
+		unsupported(prof.QuadObj, lp.Obj.Expr.Quad[0].Var1.Pos, "quadratic objective")
+	}
+	if len(lp.MultiObj) > 0 {
+		unsupported(prof.MultiObj, lp.MultiObj[0].Pos, "multiple objectives")
+	}
+	if len(lp.GenCons) > 0 {
+		unsupported(prof.GenCons, lp.GenCons[0].Pos, "general constraints")
+	}
+	if len(lp.PWLObjs) > 0 {
+		unsupported(prof.PWLObj, lp.PWLObjs[0].Pos, "piecewise-linear objectives")
+	}
+	if len(lp.SOS) > 0 {
+		unsupported(prof.SOS, lp.SOS[0].Pos, "SOS constraints")
+	}
+	if syms := lp.SemiContVars.Syms(); len(syms) > 0 {
+		unsupported(prof.SemiCont, syms[0].Pos, "semi-continuous variables")
+	}
+	if syms := lp.SemiIntVars.Syms(); len(syms) > 0 {
+		unsupported(prof.SemiInt, syms[0].Pos, "semi-integer variables")
+	}
+
+	var sawQuad, sawInd, sawEquiv, sawRange bool
+	for _, c := range lp.Rows {
+		if len(c.LHS.Quad) > 0 && !sawQuad {
+			sawQuad = true
+			unsupported(prof.QuadCons, c.Pos, "quadratic constraints")
+		}
+		if c.Indicator != nil && !sawInd {
+			sawInd = true
+			unsupported(prof.Indicators, c.Pos, "indicator constraints")
+		}
+		if c.Indicator != nil && c.Indicator.Equiv && !sawEquiv {
+			sawEquiv = true
+			unsupported(prof.Equivalences, c.Pos, "'<->' indicator constraints")
+		}
+		if c.Ranged && !sawRange {
+			sawRange = true
+			unsupported(prof.Ranged, c.Pos, "ranged constraints")
+		}
+	}
+	return diags
+}
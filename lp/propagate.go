@@ -0,0 +1,422 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxPropagationPasses limits how often propagate goes over the constraints.
+// Each pass after the first only finds bounds through variables
+// that the one before fixed, which rarely goes on for long.
+const maxPropagationPasses = 20
+
+// A reason is what set a limit of a variable's domain:
+// a constraint, a bound, or neither for the default.
+type reason struct {
+	row   *Constraint
+	bound *Bound
+}
+
+func (r reason) String() string {
+	switch {
+	case r.row != nil && r.row.Name != "":
+		return "constraint " + r.row.Name
+	case r.row != nil:
+		return fmt.Sprintf("the constraint at line %d", r.row.Pos.Line)
+	case r.bound != nil:
+		return fmt.Sprintf("the bound at line %d", r.bound.Pos.Line)
+	}
+	return "the default bound"
+}
+
+// A domain is the range of values that a variable can take
+// given its bounds and the constraints on it alone.
+type domain struct {
+	lo, hi     float64
+	loBy, hiBy reason
+	integer    bool
+	empty      bool // lo > hi, which stops propagation
+	emptiedBy  *Constraint
+}
+
+func (d *domain) fixed() bool {
+	return !d.empty && !math.IsInf(d.lo, 0) && d.hi-d.lo <= tolerance(d.lo)
+}
+
+// tolerance returns the amount by which limits near v may differ
+// and still count as equal, which is 0 for infinite limits.
+func tolerance(v float64) float64 {
+	if math.IsInf(v, 0) {
+		return 0
+	}
+	return 1e-9 * math.Max(1, math.Abs(v))
+}
+
+// propagate returns the domains of the variables of lp
+// that have bounds or are in constraints with a single variable,
+// directly or once the other variables of the constraint are fixed.
+// Semi-continuous variables, indicator constraints, and quadratic
+// constraints are left out, since they do not limit single variables.
+func propagate(lp *LP) map[string]*domain {
+	_, kinds := lp.columns()
+	sites := lp.boundSites(kinds)
+	doms := make(map[string]*domain)
+	dom := func(name string) *domain {
+		if d := doms[name]; d != nil {
+			return d
+		}
+		d := &domain{lo: 0, hi: math.Inf(1)}
+		if kinds[name] == kindBinary {
+			d.hi = 1
+		}
+		if s, ok := sites[name]; ok {
+			d.lo, d.loBy = s[0].val, reason{bound: s[0].b}
+			d.hi, d.hiBy = s[1].val, reason{bound: s[1].b}
+			if s[0].b == nil && d.hi < 0 {
+				// As in CPLEX, a negative upper bound alone makes the lower bound -inf.
+				d.lo = math.Inf(-1)
+			}
+		}
+		if lp.FreeVars.HasSym(Symbol{Value: name}) && d.loBy.bound == nil {
+			d.lo = math.Inf(-1)
+		}
+		k := kinds[name]
+		d.integer = k == kindGeneral || k == kindBinary
+		d.empty = d.lo > d.hi+tolerance(d.hi)
+		doms[name] = d
+		return d
+	}
+	for name := range sites {
+		if k := kinds[name]; k != kindSemiCont && k != kindSemiInt {
+			dom(name)
+		}
+	}
+
+	for pass, changed := 0, true; changed && pass < maxPropagationPasses; pass++ {
+		changed = false
+		for _, c := range lp.Rows {
+			if c.Indicator != nil || len(c.LHS.Quad) > 0 || len(c.RHS.Quad) > 0 {
+				continue
+			}
+			e, lo, hi := rowForm(c)
+			var only Term
+			n := 0
+			for _, t := range e.Terms {
+				if t.Coef == 0 {
+					continue
+				}
+				if k := kinds[t.Var.Value]; k == kindSemiCont || k == kindSemiInt {
+					n = 2
+					break
+				}
+				if d := doms[t.Var.Value]; d != nil && d.fixed() {
+					lo -= t.Coef * d.lo
+					hi -= t.Coef * d.lo
+					continue
+				}
+				only = t
+				n++
+			}
+			if n != 1 {
+				continue
+			}
+			d := dom(only.Var.Value)
+			if d.empty {
+				continue
+			}
+			lo, hi = lo/only.Coef, hi/only.Coef
+			if only.Coef < 0 {
+				lo, hi = hi, lo
+			}
+			if d.integer {
+				lo = math.Ceil(lo - tolerance(lo))
+				hi = math.Floor(hi + tolerance(hi))
+			}
+			if lo > d.lo+tolerance(d.lo) {
+				d.lo, d.loBy = lo, reason{row: c}
+				changed = true
+			}
+			if hi < d.hi-tolerance(d.hi) {
+				d.hi, d.hiBy = hi, reason{row: c}
+				changed = true
+			}
+			if d.lo > d.hi+tolerance(d.hi) {
+				d.empty, d.emptiedBy = true, c
+			}
+		}
+	}
+	return doms
+}
+
+// fixedByEquality reports whether d is fixed by a single equality constraint,
+// which is deliberate.
+func fixedByEquality(d *domain) bool {
+	c := d.loBy.row
+	return c != nil && d.hiBy.row == c && c.Rel == RelEQ && !c.Ranged
+}
+
+// propagatedBounds reports the variables that their domains, doms,
+// found from their bounds and the constraints on them alone leave without values, as errors,
+// and with a single value, as warnings, if warnings is set.
+// Both are reported only if a constraint takes part,
+// since bounds alone are checked by emptyBounds and fixing
+// a variable with a bound, or an equality, is deliberate.
+func propagatedBounds(lp *LP, doms map[string]*domain, warnings bool) []Diagnostic {
+	var diags []Diagnostic
+	cols, _ := lp.columns()
+	for _, sym := range cols {
+		d := doms[sym.Value]
+		if d == nil {
+			continue
+		}
+		switch {
+		case d.empty && d.emptiedBy != nil:
+			diags = append(diags, Diagnostic{
+				Pos:      d.emptiedBy.Pos,
+				Severity: Error,
+				Check:    checkPropagatedBounds,
+				Symbol:   sym.Value,
+				Message: fmt.Sprintf("%s cannot take any value: %s requires at least %s and %s at most %s",
+					sym.Value, d.loBy, formatNum(d.lo), d.hiBy, formatNum(d.hi)),
+			})
+		case warnings && d.fixed() && (d.loBy.row != nil || d.hiBy.row != nil) && !fixedByEquality(d):
+			pos := d.loBy.row
+			if pos == nil || d.hiBy.row != nil && later(d.hiBy.row.Pos, pos.Pos) {
+				pos = d.hiBy.row
+			}
+			by := d.loBy.String()
+			if d.loBy != d.hiBy {
+				by += " and " + d.hiBy.String()
+			}
+			diags = append(diags, Diagnostic{
+				Pos:      pos.Pos,
+				Severity: Warning,
+				Check:    checkPropagatedBounds,
+				Symbol:   sym.Value,
+				Message:  fmt.Sprintf("%s is fixed to %s by %s", sym.Value, formatNum(d.lo), by),
+			})
+		}
+	}
+	return diags
+}
+
+// infeasibleRows reports the constraints that no values of their variables
+// within their domains, doms, can satisfy, such as those whose variables
+// are all fixed to values that do not meet them.
+// Constraints on a variable without values are left out,
+// since propagatedBounds reports the variable,
+// as are those without variables, which trivialRows reports.
+func infeasibleRows(lp *LP, doms map[string]*domain) []Diagnostic {
+	_, kinds := lp.columns()
+	sites := lp.boundSites(kinds)
+	// limits returns the domain of the variable called name.
+	limits := func(name string) (lo, hi float64, ok bool) {
+		if d := doms[name]; d != nil {
+			return d.lo, d.hi, !d.empty
+		}
+		lo, hi = 0, math.Inf(1)
+		if s, ok := sites[name]; ok {
+			lo, hi = s[0].val, s[1].val
+		} else if kinds[name] == kindBinary {
+			hi = 1
+		}
+		if lp.FreeVars.HasSym(Symbol{Value: name}) {
+			lo = math.Inf(-1)
+		}
+		if k := kinds[name]; (k == kindSemiCont || k == kindSemiInt) && lo > 0 {
+			lo = 0 // or else between its bounds
+		}
+		return lo, hi, true
+	}
+	var diags []Diagnostic
+	for _, c := range lp.Rows {
+		if c.Indicator != nil || len(c.LHS.Quad) > 0 || len(c.RHS.Quad) > 0 {
+			continue
+		}
+		e, lo, hi := rowForm(c)
+		minAct, maxAct := 0.0, 0.0
+		n := 0
+		ok := true
+		for _, t := range e.Terms {
+			if t.Coef == 0 {
+				continue
+			}
+			n++
+			vlo, vhi, vok := limits(t.Var.Value)
+			ok = ok && vok
+			if t.Coef > 0 {
+				minAct += t.Coef * vlo
+				maxAct += t.Coef * vhi
+			} else {
+				minAct += t.Coef * vhi
+				maxAct += t.Coef * vlo
+			}
+		}
+		if n == 0 || !ok {
+			continue
+		}
+		var side, limit string
+		switch {
+		case minAct > hi+tolerance(hi)*float64(n):
+			side, limit = "at least "+formatNum(minAct), "above "+formatNum(hi)
+		case maxAct < lo-tolerance(lo)*float64(n):
+			side, limit = "at most "+formatNum(maxAct), "below "+formatNum(lo)
+		default:
+			continue
+		}
+		msg := fmt.Sprintf("its left side is %s within the bounds of its variables, %s", side, limit)
+		if minAct == maxAct {
+			msg = fmt.Sprintf("its variables are fixed, so its left side is %s, %s", formatNum(minAct), limit)
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      c.Pos,
+			Severity: Error,
+			Check:    checkInfeasibleRow,
+			Message:  fmt.Sprintf("constraint %s cannot be satisfied: %s", rowName(c), msg),
+		})
+	}
+	return diags
+}
+
+// rowName returns the name of c for messages,
+// or where it is if it has none.
+func rowName(c *Constraint) string {
+	if c.Name == "" {
+		return fmt.Sprintf("at line %d", c.Pos.Line)
+	}
+	return c.Name
+}
+
+// trivialRows reports the constraints without variables, once their terms
+// are combined: those that never hold as errors, and those that always do
+// as warnings, if warnings is set.
+// Constraints that only ever had constants, such as "4 >= 7",
+// are usually a bug in the program that wrote them.
+// Indicator constraints are left out, since one that never holds
+// only fixes its indicator variable.
+func trivialRows(lp *LP, warnings bool) []Diagnostic {
+	var diags []Diagnostic
+	for _, c := range lp.Rows {
+		if c.Indicator != nil {
+			continue
+		}
+		e, lo, hi := rowForm(c)
+		n := 0
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				n++
+			}
+		}
+		for _, q := range e.Quad {
+			if q.Coef != 0 {
+				n++
+			}
+		}
+		if n > 0 {
+			continue
+		}
+		what, noop := "compares two constants", "so it can be removed"
+		if len(c.LHS.Terms)+len(c.LHS.Quad)+len(c.RHS.Terms)+len(c.RHS.Quad) > 0 {
+			what, noop = "has no variables once its terms are combined", "so it does nothing"
+		}
+		rel := fmt.Sprintf("%s %s %s", formatNum(c.LHS.Constant), c.Rel, formatNum(c.RHS.Constant))
+		if c.Ranged {
+			rel = fmt.Sprintf("%s <= 0 <= %s", formatNum(c.RangeLo), formatNum(c.RHS.Constant))
+		}
+		d := Diagnostic{Pos: c.Pos, Check: checkTrivialRow}
+		switch {
+		case lo > tolerance(lo) || hi < -tolerance(hi):
+			d.Severity = Error
+			d.Message = fmt.Sprintf("constraint %s %s and never holds: %s", rowName(c), what, rel)
+		case warnings:
+			d.Severity = Warning
+			d.Message = fmt.Sprintf("constraint %s %s and always holds, %s: %s", rowName(c), what, noop, rel)
+		default:
+			continue
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// unboundedObjective reports the variables that can improve the objective
+// without limit: those whose objective coefficient favors a direction
+// in which their domain, in doms, is unbounded, and that are in no
+// constraint with other variables that could limit them.
+// Such a variable usually means that a bound is missing.
+func unboundedObjective(lp *LP, doms map[string]*domain) []Diagnostic {
+	if lp.Obj == nil {
+		return nil
+	}
+	_, kinds := lp.columns()
+	limited := make(map[string]bool)
+	for _, c := range lp.Rows {
+		e, _, _ := rowForm(c)
+		n := 0
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				n++
+			}
+		}
+		if n < 2 && c.Indicator == nil && len(e.Quad) == 0 {
+			continue // propagate has bounded the variable
+		}
+		for _, t := range e.Terms {
+			limited[t.Var.Value] = true
+		}
+		for _, q := range e.Quad {
+			limited[q.Var1.Value], limited[q.Var2.Value] = true, true
+		}
+	}
+	for _, sym := range lp.SOSVars.Syms() {
+		limited[sym.Value] = true
+	}
+	for _, g := range lp.GenCons {
+		limited[g.Result.Value] = true
+		for _, a := range g.Args {
+			limited[a.Value] = true
+		}
+	}
+	for _, o := range lp.PWLObjs {
+		limited[o.Var.Value] = true
+	}
+	for _, q := range lp.Obj.Expr.Quad {
+		limited[q.Var1.Value], limited[q.Var2.Value] = true, true
+	}
+
+	var diags []Diagnostic
+	for _, t := range lp.Obj.Expr.Combined().Terms {
+		name := t.Var.Value
+		if t.Coef == 0 || limited[name] || kinds[name] == kindSemiCont || kinds[name] == kindSemiInt {
+			continue
+		}
+		lo, hi := 0.0, math.Inf(1)
+		if d := doms[name]; d != nil {
+			lo, hi = d.lo, d.hi
+		} else if kinds[name] == kindBinary {
+			hi = 1
+		}
+		if lp.FreeVars.HasSym(t.Var) && doms[name] == nil {
+			lo = math.Inf(-1)
+		}
+		// The variable improves the objective by decreasing if up is false.
+		up := (t.Coef > 0) == (lp.Obj.Sense == Maximize)
+		var msg string
+		switch {
+		case up && math.IsInf(hi, 1):
+			msg = fmt.Sprintf("objective is unbounded: %s can increase without limit, since it has no upper bound and is in no constraint with other variables", name)
+		case !up && math.IsInf(lo, -1):
+			msg = fmt.Sprintf("objective is unbounded: %s can decrease without limit, since it has no lower bound and is in no constraint with other variables", name)
+		default:
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      t.Var.Pos,
+			Severity: Warning,
+			Check:    checkUnboundedObj,
+			Symbol:   name,
+			Message:  msg,
+		})
+	}
+	return diags
+}
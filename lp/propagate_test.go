@@ -0,0 +1,108 @@
+package lp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// parseRows parses a model with the given constraints and trailing sections.
+func parseRows(t *testing.T, rows, rest string) *LP {
+	t.Helper()
+	model := "Minimize\n obj: x\nSubject To\n" + rows + rest + "End\n"
+	lp, err := ParseReader("m.lp", strings.NewReader(model))
+	if err != nil {
+		t.Fatalf("%q: %v", rows, err)
+	}
+	return lp
+}
+
+// domains returns the domains of doms as "name [lo, hi]",
+// sorted by name, with empty ones marked.
+func domains(doms map[string]*domain) string {
+	var out []string
+	for name, d := range doms {
+		s := fmt.Sprintf("%s [%s, %s]", name, formatNum(d.lo), formatNum(d.hi))
+		if d.empty {
+			s += " empty"
+		}
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return strings.Join(out, "; ")
+}
+
+func TestPropagate(t *testing.T) {
+	for _, tt := range []struct {
+		rows, rest string
+		want       string
+	}{
+		{" c1: x >= 2\n c2: x <= 5\n", "", "x [2, 5]"},
+		{" c1: 2 x <= 5\n", "General\n x\n", "x [0, 2]"},
+		{" c1: -x >= -3\n", "", "x [0, 3]"},
+		{" c1: x = 2\n c2: x + y <= 5\n", "", "x [2, 2]; y [0, 3]"},
+		{" c1: x = 2\n c2: 3 y - x >= 4\n", "", "x [2, 2]; y [2, inf]"},
+		{" c1: x >= 2\n c2: x <= 1\n", "", "x [2, 1] empty"},
+		{" c1: x >= 0.5\n c2: x <= 0.7\n", "General\n x\n", "x [1, 0] empty"},
+		{" c1: x <= -1\n", "Bounds\n x free\n", "x [-inf, -1]"},
+		{" c1: x <= 1\n", "Bounds\n -5 <= x <= 10\n", "x [-5, 1]"},
+		{" c1: x + y >= 1\n", "", ""},
+		{" c1: x <= 3\n", "Semi-Continuous\n x\n", ""},
+		{" c1: x + [ x ^ 2 ] <= 3\n", "", ""},
+	} {
+		lp := parseRows(t, tt.rows, tt.rest)
+		if got := domains(propagate(lp)); got != tt.want {
+			t.Errorf("%q %q: got %s, want %s", tt.rows, tt.rest, got, tt.want)
+		}
+	}
+}
+
+func TestPropagatedBounds(t *testing.T) {
+	for _, tt := range []struct {
+		rows, rest string
+		want       string
+	}{
+		{" c1: x >= 2\n c2: x <= 1\n", "", "5: error: x cannot take any value: constraint c1 requires at least 2 and constraint c2 at most 1"},
+		{" c1: x >= 2\n c2: x <= 2\n", "", "5: warning: x is fixed to 2 by constraint c1 and constraint c2"},
+		{" c1: x = 2\n", "", ""},
+		{" c1: x >= 2\n", "Bounds\n x <= 2\n", "4: warning: x is fixed to 2 by constraint c1 and the bound at line 6"},
+		{"", "Bounds\n 2 <= x <= 2\n", ""},
+		{" c1: x = 2\n c2: x + y <= 2\n", "", "5: warning: y is fixed to 0 by the default bound and constraint c2"},
+	} {
+		lp := parseRows(t, tt.rows, tt.rest)
+		var got []string
+		for _, d := range propagatedBounds(lp, propagate(lp), true) {
+			got = append(got, fmt.Sprintf("%d: %s: %s", d.Pos.Line, d.Severity, d.Message))
+		}
+		if s := strings.Join(got, "\n"); s != tt.want {
+			t.Errorf("%q %q: got\n%s\nwant\n%s", tt.rows, tt.rest, s, tt.want)
+		}
+	}
+}
+
+func TestInfeasibleRows(t *testing.T) {
+	for _, tt := range []struct {
+		rows, rest string
+		want       string
+	}{
+		{" c1: x = 2\n c2: y = 3\n c3: x + y >= 6\n", "", "6: constraint c3 cannot be satisfied: its variables are fixed, so its left side is 5, below 6"},
+		{" c1: x + y <= 5\n", "Bounds\n x >= 3\n y >= 3\n", "4: constraint c1 cannot be satisfied: its left side is at least 6 within the bounds of its variables, above 5"},
+		{" c1: x - y >= 5\n", "Bounds\n x <= 2\n", "4: constraint c1 cannot be satisfied: its left side is at most 2 within the bounds of its variables, below 5"},
+		{" c1: x + y >= 5\n", "Bounds\n x <= 2\n", ""},
+		{" c1: x + y <= 1\n", "Binary\n x\n y\n", ""},
+		{" c1: x + y >= 3\n", "Binary\n x\n y\n", "4: constraint c1 cannot be satisfied: its left side is at most 2 within the bounds of its variables, below 3"},
+		{" c1: x + y <= -1\n", "Semi-Continuous\n x\nBounds\n x >= 2\n", "4: constraint c1 cannot be satisfied: its left side is at least 0 within the bounds of its variables, above -1"},
+		{" c1: x >= 2\n c2: x <= 1\n c3: x + y >= 0\n", "", ""},
+		{" c1: 4 >= 7\n", "", ""},
+	} {
+		lp := parseRows(t, tt.rows, tt.rest)
+		var got []string
+		for _, d := range infeasibleRows(lp, propagate(lp)) {
+			got = append(got, fmt.Sprintf("%d: %s", d.Pos.Line, d.Message))
+		}
+		if s := strings.Join(got, "\n"); s != tt.want {
+			t.Errorf("%q %q: got\n%s\nwant\n%s", tt.rows, tt.rest, s, tt.want)
+		}
+	}
+}
@@ -0,0 +1,872 @@
+package lp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// protoVersion is the version of the schema in lpvet.proto.
+const protoVersion = 1
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// A protoBuf encodes protocol buffer fields.
+// Fields with zero values are left out, as in proto3.
+type protoBuf struct {
+	b []byte
+}
+
+func (p *protoBuf) tag(field, wire int) {
+	p.b = binary.AppendUvarint(p.b, uint64(field)<<3|uint64(wire))
+}
+
+func (p *protoBuf) uint(field int, v uint64) {
+	if v != 0 {
+		p.tag(field, wireVarint)
+		p.b = binary.AppendUvarint(p.b, v)
+	}
+}
+
+func (p *protoBuf) int(field int, v int64) { p.uint(field, uint64(v)) }
+
+func (p *protoBuf) bool(field int, v bool) {
+	if v {
+		p.uint(field, 1)
+	}
+}
+
+func (p *protoBuf) double(field int, v float64) {
+	if v != 0 {
+		p.tag(field, wireFixed64)
+		p.b = binary.LittleEndian.AppendUint64(p.b, math.Float64bits(v))
+	}
+}
+
+func (p *protoBuf) bytes(field int, b []byte) {
+	p.tag(field, wireBytes)
+	p.b = binary.AppendUvarint(p.b, uint64(len(b)))
+	p.b = append(p.b, b...)
+}
+
+func (p *protoBuf) string(field int, s string) {
+	if s != "" {
+		p.bytes(field, []byte(s))
+	}
+}
+
+// msg encodes the message written by f as field,
+// even if it is empty.
+func (p *protoBuf) msg(field int, f func(m *protoBuf)) {
+	var m protoBuf
+	f(&m)
+	p.bytes(field, m.b)
+}
+
+func (p *protoBuf) uints(field int, vs []uint32) {
+	if len(vs) > 0 {
+		var m protoBuf
+		for _, v := range vs {
+			m.b = binary.AppendUvarint(m.b, uint64(v))
+		}
+		p.bytes(field, m.b)
+	}
+}
+
+func (p *protoBuf) doubles(field int, vs []float64) {
+	if len(vs) > 0 {
+		b := make([]byte, 0, 8*len(vs))
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+		}
+		p.bytes(field, b)
+	}
+}
+
+// pos encodes pos, leaving out its file if it is file.
+func (p *protoBuf) pos(field int, pos Pos, file string) {
+	p.msg(field, func(m *protoBuf) {
+		if pos.File != file {
+			m.string(1, pos.File)
+		}
+		m.int(2, int64(pos.Line))
+		m.int(3, int64(pos.Col))
+		m.int(4, int64(pos.EndCol))
+	})
+}
+
+// WriteProto writes lp to w in the binary protocol buffer form
+// described by lpvet.proto, which ReadProto reads back much faster
+// than an LP file can be parsed.
+//
+// Constraints are written with their variables on one side,
+// and each variable with the bounds that all its bound statements give it.
+// Comments, formatting, and the order of bound statements are not kept.
+func WriteProto(w io.Writer, lp *LP) error {
+	var p protoBuf
+	p.uint(1, protoVersion)
+
+	vars := Variables(lp)
+	// Most positions are in the same file, which is written once.
+	file := ""
+	if len(vars) > 0 {
+		file = vars[0].Pos.File
+	}
+	p.string(8, file)
+	index := make(map[string]uint32)
+	for i, v := range vars {
+		index[v.Name] = uint32(i)
+	}
+	for _, v := range vars {
+		p.msg(2, func(m *protoBuf) {
+			m.string(1, v.Name)
+			for t, name := range protoVarTypes {
+				if name == v.Type {
+					m.uint(2, uint64(t))
+				}
+			}
+			m.double(3, v.Lo)
+			m.double(4, v.Hi)
+			m.pos(5, v.Pos, file)
+			m.bool(6, v.Type == "continuous" && lp.CustomContVars.HasSym(Symbol{Value: v.Name}))
+		})
+	}
+
+	expr := func(m *protoBuf, field int, e Expr) {
+		m.msg(field, func(m *protoBuf) {
+			var vs, q1, q2 []uint32
+			var cs, qc []float64
+			for _, t := range e.Terms {
+				vs = append(vs, index[t.Var.Value])
+				cs = append(cs, t.Coef)
+			}
+			for _, q := range e.Quad {
+				q1 = append(q1, index[q.Var1.Value])
+				q2 = append(q2, index[q.Var2.Value])
+				qc = append(qc, q.Coef)
+			}
+			m.uints(1, vs)
+			m.doubles(2, cs)
+			m.uints(3, q1)
+			m.uints(4, q2)
+			m.doubles(5, qc)
+			m.double(6, e.Constant)
+		})
+	}
+	for _, o := range lp.objectives() {
+		p.msg(3, func(m *protoBuf) {
+			m.string(1, o.Name)
+			m.bool(2, o.Sense == Maximize)
+			expr(m, 3, o.Expr.Combined())
+			keys := make([]string, 0, len(o.Params))
+			for k := range o.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				m.msg(4, func(e *protoBuf) {
+					e.string(1, k)
+					e.double(2, o.Params[k])
+				})
+			}
+			m.pos(5, o.Pos, file)
+		})
+	}
+	for _, c := range lp.Rows {
+		p.msg(4, func(m *protoBuf) {
+			e, lo, hi := rowForm(c)
+			m.string(1, c.Name)
+			expr(m, 2, e)
+			m.double(3, lo)
+			m.double(4, hi)
+			m.bool(5, c.Ranged)
+			if ind := c.Indicator; ind != nil {
+				m.msg(6, func(m *protoBuf) {
+					m.uint(1, uint64(index[ind.Var.Value]))
+					m.int(2, int64(ind.Value))
+					m.bool(3, ind.Equiv)
+				})
+			}
+			m.pos(7, c.Pos, file)
+		})
+	}
+	for _, s := range lp.SOS {
+		p.msg(5, func(m *protoBuf) {
+			var vs []uint32
+			var ws []float64
+			for _, mem := range s.Members {
+				vs = append(vs, index[mem.Var.Value])
+				ws = append(ws, mem.Weight)
+			}
+			m.string(1, s.Name)
+			m.int(2, int64(s.Type))
+			m.uints(3, vs)
+			m.doubles(4, ws)
+			m.pos(5, s.Pos, file)
+		})
+	}
+	for _, g := range lp.GenCons {
+		p.msg(6, func(m *protoBuf) {
+			var args []uint32
+			for _, a := range g.Args {
+				args = append(args, index[a.Value])
+			}
+			m.string(1, g.Name)
+			m.uint(2, uint64(index[g.Result.Value]))
+			m.string(3, g.Func)
+			m.uints(4, args)
+			m.doubles(5, g.Constants)
+			m.pos(6, g.Pos, file)
+		})
+	}
+	for _, pw := range lp.PWLObjs {
+		p.msg(7, func(m *protoBuf) {
+			var xs, ys []float64
+			for _, pt := range pw.Points {
+				xs = append(xs, pt[0])
+				ys = append(ys, pt[1])
+			}
+			m.uint(1, uint64(index[pw.Var.Value]))
+			m.doubles(2, xs)
+			m.doubles(3, ys)
+			m.pos(4, pw.Pos, file)
+		})
+	}
+	_, err := w.Write(p.b)
+	return err
+}
+
+// protoVarTypes holds the types of variables by their number in lpvet.proto.
+var protoVarTypes = []string{"continuous", "general", "binary", "semi-continuous", "semi-integer"}
+
+var errProtoTruncated = errors.New("truncated message")
+
+// A protoReader decodes the fields of a protocol buffer message.
+type protoReader struct {
+	b    []byte
+	err  error
+	wire int
+}
+
+// next returns the number of the next field, or 0 at the end of the message.
+func (r *protoReader) next() int {
+	if r.err != nil || len(r.b) == 0 {
+		return 0
+	}
+	tag := r.varint()
+	r.wire = int(tag & 7)
+	if tag>>3 == 0 && r.err == nil {
+		r.err = errors.New("invalid field number 0")
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(tag >> 3)
+}
+
+func (r *protoReader) varint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.fail(errProtoTruncated)
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *protoReader) fixed64() uint64 {
+	if len(r.b) < 8 {
+		r.fail(errProtoTruncated)
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *protoReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+	r.b = nil
+}
+
+// want checks that the current field has the wire type wire.
+func (r *protoReader) want(wire int) bool {
+	if r.wire != wire {
+		r.fail(fmt.Errorf("field has wire type %d, not %d", r.wire, wire))
+		return false
+	}
+	return true
+}
+
+func (r *protoReader) uint() uint64 {
+	if !r.want(wireVarint) {
+		return 0
+	}
+	return r.varint()
+}
+
+func (r *protoReader) double() float64 {
+	if !r.want(wireFixed64) {
+		return 0
+	}
+	return math.Float64frombits(r.fixed64())
+}
+
+func (r *protoReader) bytes() []byte {
+	if !r.want(wireBytes) {
+		return nil
+	}
+	n := r.varint()
+	if n > uint64(len(r.b)) {
+		r.fail(errProtoTruncated)
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *protoReader) string() string { return string(r.bytes()) }
+
+// msg decodes the current field, a message, with f.
+func (r *protoReader) msg(f func(m *protoReader)) {
+	m := &protoReader{b: r.bytes()}
+	if r.err == nil {
+		f(m)
+		if m.err != nil {
+			r.fail(m.err)
+		}
+	}
+}
+
+// uints appends the values of the current field,
+// packed or not, to vs.
+func (r *protoReader) uints(vs []uint32) []uint32 {
+	if r.wire != wireBytes {
+		return append(vs, uint32(r.uint()))
+	}
+	m := &protoReader{b: r.bytes()}
+	for len(m.b) > 0 && m.err == nil {
+		vs = append(vs, uint32(m.varint()))
+	}
+	if m.err != nil {
+		r.fail(m.err)
+	}
+	return vs
+}
+
+func (r *protoReader) doubles(vs []float64) []float64 {
+	if r.wire != wireBytes {
+		return append(vs, r.double())
+	}
+	b := r.bytes()
+	if len(b)%8 != 0 {
+		r.fail(errProtoTruncated)
+		return vs
+	}
+	for i := 0; i < len(b); i += 8 {
+		vs = append(vs, math.Float64frombits(binary.LittleEndian.Uint64(b[i:])))
+	}
+	return vs
+}
+
+// skip skips the current field.
+func (r *protoReader) skip() {
+	switch r.wire {
+	case wireVarint:
+		r.varint()
+	case wireFixed64:
+		r.fixed64()
+	case wireBytes:
+		r.bytes()
+	case 5: // fixed32
+		if len(r.b) < 4 {
+			r.fail(errProtoTruncated)
+			return
+		}
+		r.b = r.b[4:]
+	default:
+		r.fail(fmt.Errorf("unsupported wire type %d", r.wire))
+	}
+}
+
+func (r *protoReader) pos() Pos {
+	var p Pos
+	r.msg(func(m *protoReader) {
+		for f := m.next(); f != 0; f = m.next() {
+			switch f {
+			case 1:
+				p.File = m.string()
+			case 2:
+				p.Line = int32(m.uint())
+			case 3:
+				p.Col = int32(m.uint())
+			case 4:
+				p.EndCol = int32(m.uint())
+			default:
+				m.skip()
+			}
+		}
+	})
+	return p
+}
+
+// A protoExpr is an Expr as in lpvet.proto, referring to variables by index.
+type protoExpr struct {
+	vars, quad1, quad2 []uint32
+	coefs, quadCoefs   []float64
+	constant           float64
+}
+
+func (r *protoReader) expr() protoExpr {
+	var e protoExpr
+	r.msg(func(m *protoReader) {
+		for f := m.next(); f != 0; f = m.next() {
+			switch f {
+			case 1:
+				e.vars = m.uints(e.vars)
+			case 2:
+				e.coefs = m.doubles(e.coefs)
+			case 3:
+				e.quad1 = m.uints(e.quad1)
+			case 4:
+				e.quad2 = m.uints(e.quad2)
+			case 5:
+				e.quadCoefs = m.doubles(e.quadCoefs)
+			case 6:
+				e.constant = m.double()
+			default:
+				m.skip()
+			}
+		}
+	})
+	if len(e.vars) != len(e.coefs) || len(e.quad1) != len(e.quadCoefs) || len(e.quad2) != len(e.quadCoefs) {
+		r.fail(errors.New("expression arrays differ in length"))
+	}
+	return e
+}
+
+// A protoModel is the model read by ReadProto before its variables
+// are resolved, since they need not come first.
+type protoModel struct {
+	version uint64
+	file    string
+	vars    []protoVar
+	objs    []protoObjective
+	rows    []protoConstraint
+	sos     []protoSOS
+	genCons []protoGenConstraint
+	pwlObjs []protoPWLObj
+}
+
+type protoVar struct {
+	Variable
+	declared bool
+}
+
+type protoObjective struct {
+	o    Objective
+	expr protoExpr
+}
+
+type protoConstraint struct {
+	c      Constraint
+	expr   protoExpr
+	lo, hi float64
+	ind    *uint32
+}
+
+type protoSOS struct {
+	s    SOS
+	vars []uint32
+	ws   []float64
+}
+
+type protoGenConstraint struct {
+	g      GenConstraint
+	result uint32
+	args   []uint32
+}
+
+type protoPWLObj struct {
+	p      PWLObj
+	v      uint32
+	xs, ys []float64
+}
+
+func (r *protoReader) model() protoModel {
+	var pm protoModel
+	for f := r.next(); f != 0; f = r.next() {
+		switch f {
+		case 1:
+			pm.version = r.uint()
+		case 8:
+			pm.file = r.string()
+		case 2:
+			v := protoVar{Variable: Variable{Type: protoVarTypes[0]}}
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						v.Name = m.string()
+					case 2:
+						if t := m.uint(); t < uint64(len(protoVarTypes)) {
+							v.Type = protoVarTypes[t]
+						}
+					case 3:
+						v.Lo = m.double()
+					case 4:
+						v.Hi = m.double()
+					case 5:
+						v.Pos = m.pos()
+					case 6:
+						v.declared = m.uint() != 0
+					default:
+						m.skip()
+					}
+				}
+			})
+			pm.vars = append(pm.vars, v)
+		case 3:
+			var o protoObjective
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						o.o.Name = m.string()
+					case 2:
+						if m.uint() != 0 {
+							o.o.Sense = Maximize
+						}
+					case 3:
+						o.expr = m.expr()
+					case 4:
+						var k string
+						var v float64
+						m.msg(func(e *protoReader) {
+							for f := e.next(); f != 0; f = e.next() {
+								switch f {
+								case 1:
+									k = e.string()
+								case 2:
+									v = e.double()
+								default:
+									e.skip()
+								}
+							}
+						})
+						if o.o.Params == nil {
+							o.o.Params = make(map[string]float64)
+						}
+						o.o.Params[k] = v
+					case 5:
+						o.o.Pos = m.pos()
+					default:
+						m.skip()
+					}
+				}
+			})
+			pm.objs = append(pm.objs, o)
+		case 4:
+			var c protoConstraint
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						c.c.Name = m.string()
+					case 2:
+						c.expr = m.expr()
+					case 3:
+						c.lo = m.double()
+					case 4:
+						c.hi = m.double()
+					case 5:
+						c.c.Ranged = m.uint() != 0
+					case 6:
+						ind := new(Indicator)
+						var v uint32
+						m.msg(func(m *protoReader) {
+							for f := m.next(); f != 0; f = m.next() {
+								switch f {
+								case 1:
+									v = uint32(m.uint())
+								case 2:
+									ind.Value = int(int32(m.uint()))
+								case 3:
+									ind.Equiv = m.uint() != 0
+								default:
+									m.skip()
+								}
+							}
+						})
+						c.c.Indicator, c.ind = ind, &v
+					case 7:
+						c.c.Pos = m.pos()
+					default:
+						m.skip()
+					}
+				}
+			})
+			pm.rows = append(pm.rows, c)
+		case 5:
+			var s protoSOS
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						s.s.Name = m.string()
+					case 2:
+						s.s.Type = int(int32(m.uint()))
+					case 3:
+						s.vars = m.uints(s.vars)
+					case 4:
+						s.ws = m.doubles(s.ws)
+					case 5:
+						s.s.Pos = m.pos()
+					default:
+						m.skip()
+					}
+				}
+				if len(s.vars) != len(s.ws) {
+					m.fail(errors.New("SOS arrays differ in length"))
+				}
+			})
+			pm.sos = append(pm.sos, s)
+		case 6:
+			var g protoGenConstraint
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						g.g.Name = m.string()
+					case 2:
+						g.result = uint32(m.uint())
+					case 3:
+						g.g.Func = m.string()
+					case 4:
+						g.args = m.uints(g.args)
+					case 5:
+						g.g.Constants = m.doubles(g.g.Constants)
+					case 6:
+						g.g.Pos = m.pos()
+					default:
+						m.skip()
+					}
+				}
+			})
+			pm.genCons = append(pm.genCons, g)
+		case 7:
+			var p protoPWLObj
+			r.msg(func(m *protoReader) {
+				for f := m.next(); f != 0; f = m.next() {
+					switch f {
+					case 1:
+						p.v = uint32(m.uint())
+					case 2:
+						p.xs = m.doubles(p.xs)
+					case 3:
+						p.ys = m.doubles(p.ys)
+					case 4:
+						p.p.Pos = m.pos()
+					default:
+						m.skip()
+					}
+				}
+				if len(p.xs) != len(p.ys) {
+					m.fail(errors.New("PWLObj arrays differ in length"))
+				}
+			})
+			pm.pwlObjs = append(pm.pwlObjs, p)
+		default:
+			r.skip()
+		}
+	}
+	return pm
+}
+
+// ReadProto reads a model written by WriteProto from r.
+// Positions refer to the file the model was originally read from,
+// or to name if it is not known.
+func ReadProto(name string, r io.Reader) (*LP, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	pr := &protoReader{b: data}
+	pm := pr.model()
+	if pr.err != nil {
+		return nil, fmt.Errorf("%s: %v", name, pr.err)
+	}
+	if pm.version != protoVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d; this lpvet reads version %d", name, pm.version, protoVersion)
+	}
+
+	lp := new(LP)
+	if pm.file == "" {
+		pm.file = name
+	}
+	fixPos := func(p *Pos) {
+		if p.File == "" {
+			p.File = pm.file
+		}
+	}
+	var bad error
+	sym := func(i uint32, pos Pos) Symbol {
+		if int(i) >= len(pm.vars) {
+			if bad == nil {
+				bad = fmt.Errorf("%s: reference to variable %d of %d", name, i, len(pm.vars))
+			}
+			return Symbol{Pos: pos}
+		}
+		return Symbol{Value: pm.vars[i].Name, Pos: pos}
+	}
+	expr := func(e protoExpr, pos Pos) Expr {
+		x := Expr{Constant: e.constant}
+		for i, v := range e.vars {
+			x.Terms = append(x.Terms, Term{Coef: e.coefs[i], Var: sym(v, pos)})
+		}
+		for i, c := range e.quadCoefs {
+			x.Quad = append(x.Quad, QuadTerm{Coef: c, Var1: sym(e.quad1[i], pos), Var2: sym(e.quad2[i], pos)})
+		}
+		return x
+	}
+	use := func(sec *Section, e Expr) {
+		for _, t := range e.Terms {
+			sec.AddSym(t.Var)
+		}
+		for _, q := range e.Quad {
+			sec.AddSym(q.Var1)
+			sec.AddSym(q.Var2)
+		}
+	}
+
+	for i := range pm.vars {
+		v := &pm.vars[i]
+		fixPos(&v.Pos)
+		lp.addVariable(v.Variable, v.declared)
+	}
+	for i := range pm.objs {
+		o := &pm.objs[i].o
+		fixPos(&o.Pos)
+		o.Expr = expr(pm.objs[i].expr, o.Pos)
+		lp.MultiObj = append(lp.MultiObj, o)
+		use(&lp.Objective, o.Expr)
+		if o.Name != "" {
+			lp.RowNames.AddSym(Symbol{Value: o.Name, Pos: o.Pos})
+		}
+	}
+	if len(lp.MultiObj) > 0 {
+		lp.Obj = lp.MultiObj[0]
+	}
+	if len(lp.MultiObj) == 1 {
+		lp.MultiObj = nil
+	}
+	for i := range pm.rows {
+		pc := &pm.rows[i]
+		c := &pc.c
+		fixPos(&c.Pos)
+		c.LHS = expr(pc.expr, c.Pos)
+		c.LHS.Constant = 0
+		lo, hi := pc.lo, pc.hi
+		switch {
+		case c.Ranged:
+			c.Rel, c.RangeLo, c.RHS.Constant = RelLE, lo, hi
+		case lo == hi:
+			c.Rel, c.RHS.Constant = RelEQ, lo
+		case math.IsInf(lo, -1):
+			c.Rel, c.RHS.Constant = RelLE, hi
+		default:
+			c.Rel, c.RHS.Constant = RelGE, lo
+		}
+		use(&lp.Constraints, c.LHS)
+		if pc.ind != nil {
+			c.Indicator.Var = sym(*pc.ind, c.Pos)
+			lp.Constraints.AddSym(c.Indicator.Var)
+		}
+		if c.Name != "" {
+			lp.RowNames.AddSym(Symbol{Value: c.Name, Pos: c.Pos})
+		}
+		lp.Rows = append(lp.Rows, c)
+	}
+	for i := range pm.sos {
+		s := &pm.sos[i].s
+		fixPos(&s.Pos)
+		for j, v := range pm.sos[i].vars {
+			sym := sym(v, s.Pos)
+			s.Members = append(s.Members, SOSMember{Var: sym, Weight: pm.sos[i].ws[j]})
+			lp.SOSVars.AddSym(sym)
+		}
+		lp.SOS = append(lp.SOS, s)
+	}
+	for i := range pm.genCons {
+		g := &pm.genCons[i].g
+		fixPos(&g.Pos)
+		g.Result = sym(pm.genCons[i].result, g.Pos)
+		lp.Constraints.AddSym(g.Result)
+		for _, v := range pm.genCons[i].args {
+			a := sym(v, g.Pos)
+			g.Args = append(g.Args, a)
+			lp.Constraints.AddSym(a)
+		}
+		lp.GenCons = append(lp.GenCons, g)
+	}
+	for i := range pm.pwlObjs {
+		pw := &pm.pwlObjs[i].p
+		fixPos(&pw.Pos)
+		pw.Var = sym(pm.pwlObjs[i].v, pw.Pos)
+		for j, x := range pm.pwlObjs[i].xs {
+			pw.Points = append(pw.Points, [2]float64{x, pm.pwlObjs[i].ys[j]})
+		}
+		lp.Objective.AddSym(pw.Var)
+		lp.PWLObjs = append(lp.PWLObjs, pw)
+	}
+	if bad != nil {
+		return nil, bad
+	}
+	return lp, nil
+}
+
+// addVariable declares v in lp as ReadProto reads it,
+// with a bound statement if it does not have the default bounds.
+// Continuous variables are declared only if declared is set.
+func (lp *LP) addVariable(v Variable, declared bool) {
+	s := Symbol{Value: v.Name, Pos: v.Pos}
+	switch v.Type {
+	case "general":
+		lp.GeneralVars.AddSym(s)
+	case "binary":
+		lp.BinaryVars.AddSym(s)
+	case "semi-continuous":
+		lp.SemiContVars.AddSym(s)
+	case "semi-integer":
+		lp.SemiIntVars.AddSym(s)
+	default:
+		if declared {
+			lp.CustomContVars.AddSym(s)
+		}
+	}
+	hi := math.Inf(1)
+	if v.Type == "binary" {
+		hi = 1
+	}
+	if v.Lo == 0 && v.Hi == hi {
+		return
+	}
+	b := &Bound{Var: s, Lower: v.Lo, Upper: v.Hi, HasLower: true, HasUpper: true, Pos: v.Pos}
+	if math.IsInf(v.Lo, -1) && math.IsInf(v.Hi, 1) {
+		b = &Bound{Var: s, Free: true, Pos: v.Pos}
+		lp.FreeVars.AddSym(s)
+	}
+	lp.VarBounds = append(lp.VarBounds, b)
+	lp.Bounds.AddSym(s)
+}
@@ -0,0 +1,130 @@
+package lp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		model string
+	}{
+		{
+			"basic",
+			"Minimize\n obj: 2 x + 3 y + 4\nSubject To\n c1: x + y >= 1\n c2: x - y <= 4\n c3: x + 2 y = 3\nEnd\n",
+		},
+		{
+			"maximize",
+			"Maximize\n profit: x\nSubject To\n c1: x <= 10\nEnd\n",
+		},
+		{
+			"ranges and bounds",
+			"Minimize\n obj: x + y + z\nSubject To\n r1: -2 <= x + y <= 3\nBounds\n 0 <= x <= 4\n y >= -1\n z free\nEnd\n",
+		},
+		{
+			"declarations",
+			"Minimize\n obj: x + y + z\nSubject To\n c1: x + y + z >= 1\nGeneral\n x\nBinary\n y\nSemi-Continuous\n z\nBounds\n z <= 5\nEnd\n",
+		},
+		{
+			"SOS",
+			"Minimize\n obj: x + y + z\nSubject To\n c1: x + y + z >= 1\nSOS\n s1: S1:: x:1 y:2 z:3\n s2: S2:: x:-1 z:4\nEnd\n",
+		},
+		{
+			"quadratic objective",
+			"Minimize\n obj: x + [ 2 x ^ 2 + 4 x * y ] / 2\nSubject To\n c1: x + y >= 1\nEnd\n",
+		},
+		{
+			"problems",
+			"Minimize\n obj: x\nSubject To\n c1: x >= 2\n c2: x <= 1\n c3: 0 x + y >= 1\n c4: x + y + z >= 1\nEnd\n",
+		},
+	} {
+		m, err := ParseReader("m.lp", strings.NewReader(tt.model))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := WriteProto(&buf, m); err != nil {
+			t.Errorf("%s: WriteProto: %v", tt.name, err)
+			continue
+		}
+		m2, err := ReadProto("m.pb", &buf)
+		if err != nil {
+			t.Errorf("%s: ReadProto: %v", tt.name, err)
+			continue
+		}
+		// Bound statements are written as the limits they give.
+		if got, want := protoSummary(m2), protoSummary(m); got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, want)
+		}
+		if got, want := sosSets(m2.SOS), sosSets(m.SOS); got != want {
+			t.Errorf("%s: got SOS %s, want %s", tt.name, got, want)
+		}
+		if got, want := diagText(Vet(m2, Options{Warnings: true})), diagText(Vet(m, Options{Warnings: true})); got != want {
+			t.Errorf("%s: got diagnostics\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+// protoSummary is like summary, but with the limits of
+// each variable in place of its bound statements.
+func protoSummary(m *LP) string {
+	c := *m
+	c.VarBounds = nil
+	s := summary(&c)
+	for _, v := range Variables(m) {
+		s += fmt.Sprintf("%s %s [%s, %s]\n", v.Type, v.Name, formatNum(v.Lo), formatNum(v.Hi))
+	}
+	return s
+}
+
+func sosSets(sets []*SOS) string {
+	var b strings.Builder
+	for _, s := range sets {
+		b.WriteString(sosText(s) + "\n")
+	}
+	return b.String()
+}
+
+func diagText(diags []Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		// Objective terms keep no positions of their own.
+		fmt.Fprintf(&b, "line %d: %s: %s [%s]\n", d.Pos.Line, d.Severity, d.Message, d.Check)
+	}
+	return b.String()
+}
+
+func TestReadProtoErrors(t *testing.T) {
+	m, err := ParseReader("m.lp", strings.NewReader("Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteProto(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"truncated", good[:len(good)-3], "m.pb: truncated message"},
+		{"wrong version", append([]byte{0x08, 0x63}, good[2:]...), "m.pb: unsupported version 99; this lpvet reads version 1"},
+		{"bad wire type", []byte{0x0f}, "m.pb: field has wire type 7, not 0"},
+		{"empty", nil, "m.pb: unsupported version 0; this lpvet reads version 1"},
+	} {
+		_, err := ReadProto("m.pb", bytes.NewReader(tt.data))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got error %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+)
+
+// A Match is a variable or row found by RunQuery.
+type Match struct {
+	Kind   string // variable, objective, or constraint
+	Name   string // as in WriteMPS for unnamed rows
+	Detail string // the type and bounds of variables, or the definition of rows
+	Pos    Pos
+}
+
+// queryFuncs lists the functions of the query language,
+// and whether each returns rows rather than variables.
+var queryFuncs = map[string]bool{
+	"var":  false,
+	"row":  true,
+	"uses": true,
+	"vars": false,
+	"type": false,
+	"obj":  false,
+	"free": false,
+}
+
+// QueryHelp describes the query language of RunQuery.
+func QueryHelp() string {
+	return `A query is a function call, or calls combined with & (and), | (or),
+and - (but not), which apply from left to right unless parenthesized.
+Each function returns variables or rows, which are objectives and constraints,
+and only results of the same kind can be combined:
+
+	var(pattern)    variables named pattern
+	row(pattern)    objectives and constraints named pattern
+	uses(pattern)   rows using variables named pattern
+	vars(pattern)   variables in rows named pattern
+	type(t)         variables of type t: continuous, general, binary,
+	                semi-continuous, semi-integer, or integer (any of the others)
+	obj()           variables with a nonzero objective coefficient
+	free()          variables without bounds
+
+Patterns may use the wildcards * and ?; without an argument, they match everything.
+Unnamed rows are named R1, R2, ... by position.
+
+Examples:
+
+	uses(x12)                  constraints containing x12
+	vars(cap_*)                variables in the constraints named cap_...
+	type(binary) & obj()       binary variables in the objective
+	uses(x) - uses(y)          rows with x but not y`
+}
+
+// queryModel holds what queries are evaluated against.
+type queryModel struct {
+	vars     []Variable
+	varIndex map[string]int
+	rows     []Match
+	rowVars  [][]int // variables of each row
+	objVars  map[int]bool
+}
+
+func newQueryModel(lp *LP) *queryModel {
+	qm := &queryModel{vars: Variables(lp), varIndex: make(map[string]int), objVars: make(map[int]bool)}
+	for i, v := range qm.vars {
+		qm.varIndex[v.Name] = i
+	}
+	indices := func(e Expr) []int {
+		var is []int
+		seen := make(map[int]bool)
+		add := func(name string) {
+			if i, ok := qm.varIndex[name]; ok && !seen[i] {
+				seen[i] = true
+				is = append(is, i)
+			}
+		}
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				add(t.Var.Value)
+			}
+		}
+		for _, q := range e.Quad {
+			if q.Coef != 0 {
+				add(q.Var1.Value)
+				add(q.Var2.Value)
+			}
+		}
+		return is
+	}
+
+	objName, rowNames := lp.rowNames()
+	objs := lp.objectives()
+	for i, o := range objs {
+		name := o.Name
+		if name == "" {
+			name = objName
+			if len(objs) > 1 {
+				name = fmt.Sprintf("%s%d", objName, i+1)
+			}
+		}
+		e := combine(o.Expr, Expr{})
+		e.Constant = o.Expr.Constant
+		qm.rows = append(qm.rows, Match{Kind: "objective", Name: name, Detail: o.Sense.String() + " " + exprText(e, true), Pos: o.Pos})
+		is := indices(e)
+		qm.rowVars = append(qm.rowVars, is)
+		for _, v := range is {
+			qm.objVars[v] = true
+		}
+	}
+	for i, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		qm.rows = append(qm.rows, Match{Kind: "constraint", Name: rowNames[i], Detail: rowText(c, e, lo, hi), Pos: c.Pos})
+		is := indices(e)
+		if c.Indicator != nil {
+			if v, ok := qm.varIndex[c.Indicator.Var.Value]; ok {
+				is = append(is, v)
+			}
+		}
+		qm.rowVars = append(qm.rowVars, is)
+	}
+	return qm
+}
+
+// A querySet is the result of a query: variables or rows, by index.
+type querySet struct {
+	rows bool
+	has  []bool
+}
+
+// RunQuery returns the variables or rows of lp that match query,
+// in the order they appear in the model.
+// See QueryHelp for the query language.
+func RunQuery(lp *LP, query string) ([]Match, error) {
+	qp := &queryParser{s: query, qm: newQueryModel(lp)}
+	set, err := qp.expr()
+	if err == nil && qp.skipSpace() < len(qp.s) {
+		err = qp.errorf("unexpected %q", qp.s[qp.i:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	qm := qp.qm
+	var ms []Match
+	for i, ok := range set.has {
+		if !ok {
+			continue
+		}
+		if set.rows {
+			ms = append(ms, qm.rows[i])
+			continue
+		}
+		v := qm.vars[i]
+		ms = append(ms, Match{Kind: "variable", Name: v.Name, Detail: v.Type + " in " + rangeText(v.Lo, v.Hi), Pos: v.Pos})
+	}
+	return ms, nil
+}
+
+type queryParser struct {
+	s  string
+	i  int
+	qm *queryModel
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("query:%d: %s", p.i+1, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips spaces and returns the new offset.
+func (p *queryParser) skipSpace() int {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n') {
+		p.i++
+	}
+	return p.i
+}
+
+// expr parses calls combined with &, |, and -.
+func (p *queryParser) expr() (querySet, error) {
+	x, err := p.operand()
+	if err != nil {
+		return x, err
+	}
+	for p.skipSpace() < len(p.s) {
+		op := p.s[p.i]
+		if op != '&' && op != '|' && op != '-' {
+			break
+		}
+		at := p.i
+		p.i++
+		y, err := p.operand()
+		if err != nil {
+			return x, err
+		}
+		if x.rows != y.rows {
+			p.i = at
+			return x, p.errorf("cannot combine %s with %s", kindName(x.rows), kindName(y.rows))
+		}
+		for i := range x.has {
+			switch op {
+			case '&':
+				x.has[i] = x.has[i] && y.has[i]
+			case '|':
+				x.has[i] = x.has[i] || y.has[i]
+			default:
+				x.has[i] = x.has[i] && !y.has[i]
+			}
+		}
+	}
+	return x, nil
+}
+
+func kindName(rows bool) string {
+	if rows {
+		return "rows"
+	}
+	return "variables"
+}
+
+// operand parses a parenthesized expression or a call.
+func (p *queryParser) operand() (querySet, error) {
+	if p.skipSpace() < len(p.s) && p.s[p.i] == '(' {
+		p.i++
+		x, err := p.expr()
+		if err != nil {
+			return x, err
+		}
+		if p.skipSpace() >= len(p.s) || p.s[p.i] != ')' {
+			return x, p.errorf("expected )")
+		}
+		p.i++
+		return x, nil
+	}
+	start := p.i
+	for p.i < len(p.s) && ('a' <= p.s[p.i] && p.s[p.i] <= 'z') {
+		p.i++
+	}
+	name := p.s[start:p.i]
+	if name == "" {
+		if p.i == len(p.s) {
+			return querySet{}, p.errorf("expected a function")
+		}
+		return querySet{}, p.errorf("expected a function, found %q", p.s[p.i:])
+	}
+	rows, ok := queryFuncs[name]
+	if !ok {
+		p.i = start
+		return querySet{}, p.errorf("unknown function %s", name)
+	}
+	// The argument extends to the matching parenthesis,
+	// since names may contain parentheses, as in x(1,2).
+	arg := ""
+	if p.skipSpace() < len(p.s) && p.s[p.i] == '(' {
+		depth := 0
+		argStart := p.i + 1
+		for ; p.i < len(p.s); p.i++ {
+			if p.s[p.i] == '(' {
+				depth++
+			} else if p.s[p.i] == ')' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if p.i == len(p.s) {
+			p.i = argStart - 1
+			return querySet{}, p.errorf("missing ) after %s(", name)
+		}
+		arg = strings.TrimSpace(p.s[argStart:p.i])
+		p.i++
+	}
+	set, err := p.call(name, arg)
+	if err != nil {
+		p.i = start
+		return set, p.errorf("%v", err)
+	}
+	set.rows = rows
+	return set, nil
+}
+
+// call evaluates the function name with the argument arg.
+func (p *queryParser) call(name, arg string) (querySet, error) {
+	qm := p.qm
+	vars := querySet{has: make([]bool, len(qm.vars))}
+	rows := querySet{rows: true, has: make([]bool, len(qm.rows))}
+	match := func(s string) bool {
+		if arg == "" || arg == s {
+			return true
+		}
+		ok, err := path.Match(arg, s)
+		return ok && err == nil
+	}
+	switch name {
+	case "var":
+		for i, v := range qm.vars {
+			vars.has[i] = match(v.Name)
+		}
+		return vars, nil
+	case "row":
+		for i, r := range qm.rows {
+			rows.has[i] = match(r.Name)
+		}
+		return rows, nil
+	case "uses":
+		for i, vs := range qm.rowVars {
+			for _, v := range vs {
+				if match(qm.vars[v].Name) {
+					rows.has[i] = true
+					break
+				}
+			}
+		}
+		return rows, nil
+	case "vars":
+		for i, r := range qm.rows {
+			if match(r.Name) {
+				for _, v := range qm.rowVars[i] {
+					vars.has[v] = true
+				}
+			}
+		}
+		return vars, nil
+	case "type":
+		switch arg {
+		case "continuous", "general", "binary", "semi-continuous", "semi-integer", "integer":
+		default:
+			return vars, fmt.Errorf("unknown type %q", arg)
+		}
+		for i, v := range qm.vars {
+			vars.has[i] = v.Type == arg || (arg == "integer" && v.Type != "continuous" && v.Type != "semi-continuous")
+		}
+		return vars, nil
+	}
+	if arg != "" {
+		return vars, fmt.Errorf("%s takes no argument", name)
+	}
+	for i, v := range qm.vars {
+		if name == "obj" {
+			vars.has[i] = qm.objVars[i]
+		} else {
+			vars.has[i] = math.IsInf(v.Lo, -1) && math.IsInf(v.Hi, 1)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,112 @@
+package lp
+
+import "fmt"
+
+// Anonymize renames the variables of lp to x1, x2, and so on
+// in the order in which they first appear, and its constraints to
+// c1, c2, and so on by position. Objectives are renamed obj
+// (obj1, obj2, and so on for multiple objectives), SOS constraints
+// s1, s2, and so on, and general constraints g1, g2, and so on.
+// The model is otherwise unchanged.
+//
+// Anonymize returns maps from the old names of variables and of
+// named rows to their new names.
+func Anonymize(lp *LP) (vars, rows map[string]string) {
+	vars = make(map[string]string)
+	cols, _ := lp.columns()
+	for i, sym := range cols {
+		vars[sym.Value] = fmt.Sprintf("x%d", i+1)
+	}
+	rows = make(map[string]string)
+	rename := func(name *string, format string, i int) {
+		s := fmt.Sprintf(format, i+1)
+		if *name != "" {
+			rows[*name] = s
+		}
+		*name = s
+	}
+	if len(lp.MultiObj) > 0 {
+		for i, o := range lp.MultiObj {
+			rename(&o.Name, "obj%d", i)
+		}
+	} else if lp.Obj != nil {
+		if lp.Obj.Name != "" {
+			rows[lp.Obj.Name] = "obj"
+		}
+		lp.Obj.Name = "obj"
+	}
+	for i, c := range lp.Rows {
+		rename(&c.Name, "c%d", i)
+	}
+	for i, s := range lp.SOS {
+		rename(&s.Name, "s%d", i)
+	}
+	for i, g := range lp.GenCons {
+		rename(&g.Name, "g%d", i)
+	}
+	lp.rename(vars, rows)
+	return vars, rows
+}
+
+// rename replaces the names of variables and rows of lp
+// wherever they appear in its symbols.
+// Names missing from the maps are kept.
+func (lp *LP) rename(vars, rows map[string]string) {
+	v := func(sym *Symbol) {
+		if s, ok := vars[sym.Value]; ok {
+			sym.Value = s
+		}
+	}
+	expr := func(e *Expr) {
+		for i := range e.Terms {
+			v(&e.Terms[i].Var)
+		}
+		for i := range e.Quad {
+			v(&e.Quad[i].Var1)
+			v(&e.Quad[i].Var2)
+		}
+	}
+	for _, o := range lp.objectives() {
+		expr(&o.Expr)
+	}
+	for _, c := range lp.Rows {
+		expr(&c.LHS)
+		expr(&c.RHS)
+		if c.Indicator != nil {
+			v(&c.Indicator.Var)
+		}
+	}
+	for _, b := range lp.VarBounds {
+		v(&b.Var)
+	}
+	for _, s := range lp.SOS {
+		for i := range s.Members {
+			v(&s.Members[i].Var)
+		}
+	}
+	for _, g := range lp.GenCons {
+		v(&g.Result)
+		for i := range g.Args {
+			v(&g.Args[i])
+		}
+	}
+	for _, pw := range lp.PWLObjs {
+		v(&pw.Var)
+	}
+	for _, sec := range lp.sections() {
+		sec.rename(vars)
+	}
+	lp.RowNames.rename(rows)
+}
+
+// rename replaces the names of the symbols in s according to names.
+func (s *Section) rename(names map[string]string) {
+	syms := s.syms
+	*s = Section{}
+	for _, sym := range syms {
+		if n, ok := names[sym.Value]; ok {
+			sym.Value = n
+		}
+		s.AddSym(sym)
+	}
+}
@@ -0,0 +1,174 @@
+package lp
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Solution assigns values to the variables of a model,
+// as written by a solver.
+type Solution struct {
+	Values map[string]float64
+
+	// Objective is the objective value the solver reported,
+	// if HasObjective is set.
+	Objective    float64
+	HasObjective bool
+
+	// Sparse is set if variables without a value are 0,
+	// as in Gurobi's JSON solutions, which only list nonzero values.
+	Sparse bool
+}
+
+// cplexSolution is a solution in CPLEX's XML format.
+type cplexSolution struct {
+	Header struct {
+		ObjectiveValue string `xml:"objectiveValue,attr"`
+	} `xml:"header"`
+	Variables []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"variables>variable"`
+}
+
+// ReadSolution reads a solution from r, detecting its format:
+// CPLEX's XML format, Gurobi's JSON format, or lines of names and values
+// as in Gurobi's .sol files.
+// Of files with several solutions, the first is read.
+// Name is used in errors.
+func ReadSolution(name string, r io.Reader) (*Solution, error) {
+	br := bufio.NewReader(r)
+	// Look past leading space without consuming it,
+	// so that line numbers in errors stay right.
+	for n := 1; ; n++ {
+		b, err := br.Peek(n)
+		if len(b) < n {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%s: empty solution", name)
+			}
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		switch c := b[n-1]; {
+		case c == '<':
+			return readCPLEXSolution(name, br)
+		case c == '{':
+			return readGurobiSolution(name, br)
+		case c != ' ' && c != '\t' && c != '\r' && c != '\n':
+			return readPlainSolution(name, br)
+		}
+	}
+}
+
+// readCPLEXSolution reads a solution in CPLEX's XML format.
+func readCPLEXSolution(name string, r io.Reader) (*Solution, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s: no CPLEXSolution element", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "CPLEXSolution" {
+			continue
+		}
+		var cs cplexSolution
+		if err := dec.DecodeElement(&cs, &start); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		sol := &Solution{Values: make(map[string]float64)}
+		if v := cs.Header.ObjectiveValue; v != "" {
+			if sol.Objective, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("%s: objective value %q is not a number", name, v)
+			}
+			sol.HasObjective = true
+		}
+		for _, v := range cs.Variables {
+			x, err := strconv.ParseFloat(v.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: value %q of %s is not a number", name, v.Value, v.Name)
+			}
+			sol.Values[v.Name] = x
+		}
+		return sol, nil
+	}
+}
+
+// gurobiSolution is a solution in Gurobi's JSON format.
+type gurobiSolution struct {
+	SolutionInfo struct {
+		ObjVal *float64
+	}
+	Vars []struct {
+		VarName string
+		X       *float64
+	}
+}
+
+// readGurobiSolution reads a solution in Gurobi's JSON format.
+func readGurobiSolution(name string, r io.Reader) (*Solution, error) {
+	var gs gurobiSolution
+	if err := json.NewDecoder(r).Decode(&gs); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	sol := &Solution{Values: make(map[string]float64), Sparse: true}
+	if gs.SolutionInfo.ObjVal != nil {
+		sol.Objective, sol.HasObjective = *gs.SolutionInfo.ObjVal, true
+	}
+	for _, v := range gs.Vars {
+		if v.VarName == "" {
+			return nil, fmt.Errorf("%s: variables have no VarName (set JSONSolDetail=1)", name)
+		}
+		if v.X == nil {
+			return nil, fmt.Errorf("%s: no value for %s", name, v.VarName)
+		}
+		sol.Values[v.VarName] = *v.X
+	}
+	return sol, nil
+}
+
+// readPlainSolution reads lines of variable names and values.
+// Lines starting with # are comments, except for one with the objective value,
+// such as "# Objective value = 3".
+func readPlainSolution(name string, r io.Reader) (*Solution, error) {
+	sol := &Solution{Values: make(map[string]float64)}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(text, "#") {
+			comment := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "#")))
+			if strings.HasPrefix(comment, "objective value") {
+				if i := strings.IndexByte(comment, '='); i >= 0 {
+					if v, err := strconv.ParseFloat(strings.TrimSpace(comment[i+1:]), 64); err == nil {
+						sol.Objective, sol.HasObjective = v, true
+					}
+				}
+			}
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a variable name and its value", name, line)
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: value %q of %s is not a number", name, line, fields[1], fields[0])
+		}
+		sol.Values[fields[0]] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return sol, nil
+}
@@ -0,0 +1,143 @@
+package lp
+
+import "math"
+
+// Stats summarizes the size of a model.
+type Stats struct {
+	Variables     int            `json:"variables"`
+	VariableTypes map[string]int `json:"variableTypes"` // continuous, general, binary, semi-continuous, semi-integer
+	FreeVariables int            `json:"freeVariables"`
+
+	Constraints          int            `json:"constraints"`
+	Senses               map[string]int `json:"senses"` // <=, >=, =, and ranged
+	Indicators           int            `json:"indicators"`
+	QuadraticConstraints int            `json:"quadraticConstraints"`
+	Nonzeros             int            `json:"nonzeros"` // linear coefficients in constraints
+
+	// MinCoef and MaxCoef are the smallest and largest absolute values
+	// of the nonzero coefficients of the constraints, or 0 if there are none.
+	MinCoef float64 `json:"minCoef"`
+	MaxCoef float64 `json:"maxCoef"`
+
+	Objectives              int `json:"objectives"`
+	ObjectiveTerms          int `json:"objectiveTerms"`
+	QuadraticObjectiveTerms int `json:"quadraticObjectiveTerms"`
+
+	Bounds             int `json:"bounds"` // bound statements
+	SOS                int `json:"sos"`
+	GeneralConstraints int `json:"generalConstraints"`
+	PWLObjectives      int `json:"pwlObjectives"`
+
+	Sections []SectionStats `json:"sections"`
+}
+
+// SectionStats gives the size of a section of a model file.
+type SectionStats struct {
+	Header string `json:"header"`
+	Lines  int    `json:"lines"` // after the header, including blank lines and comments
+}
+
+// ComputeStats returns the statistics of lp.
+func ComputeStats(lp *LP) Stats {
+	s := Stats{
+		VariableTypes: make(map[string]int),
+		Senses:        make(map[string]int),
+	}
+	cols, kinds := lp.columns()
+	bounds := lp.varBounds(kinds)
+	s.Variables = len(cols)
+	for _, sym := range cols {
+		s.VariableTypes[kinds[sym.Value].String()]++
+		if lo, hi := boundsOf(sym.Value, bounds, kinds); math.IsInf(lo, -1) && math.IsInf(hi, 1) {
+			s.FreeVariables++
+		}
+	}
+
+	s.Constraints = len(lp.Rows)
+	for _, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		switch {
+		case c.Ranged:
+			s.Senses["ranged"]++
+		case lo == hi:
+			s.Senses["="]++
+		case math.IsInf(lo, -1):
+			s.Senses["<="]++
+		default:
+			s.Senses[">="]++
+		}
+		if c.Indicator != nil {
+			s.Indicators++
+		}
+		if len(e.Quad) > 0 {
+			s.QuadraticConstraints++
+		}
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				s.Nonzeros++
+			}
+		}
+	}
+
+	for i, r := range CoefficientRanges(lp) {
+		if i == 0 || r.Min < s.MinCoef {
+			s.MinCoef = r.Min
+		}
+		if r.Max > s.MaxCoef {
+			s.MaxCoef = r.Max
+		}
+	}
+
+	for _, o := range lp.objectives() {
+		s.Objectives++
+		e := combine(o.Expr, Expr{})
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				s.ObjectiveTerms++
+			}
+		}
+		s.QuadraticObjectiveTerms += len(e.Quad)
+	}
+
+	s.Bounds = len(lp.VarBounds)
+	s.SOS = len(lp.SOS)
+	s.GeneralConstraints = len(lp.GenCons)
+	s.PWLObjectives = len(lp.PWLObjs)
+
+	last := lp.lastLine()
+	for i, h := range lp.Headers {
+		end := last
+		if i+1 < len(lp.Headers) {
+			end = lp.Headers[i+1].Pos.Line - 1
+		}
+		n := int(end - h.Pos.Line)
+		if n < 0 {
+			n = 0
+		}
+		s.Sections = append(s.Sections, SectionStats{Header: h.Value, Lines: n})
+	}
+	return s
+}
+
+// lastLine returns the last line of lp that holds a statement, header,
+// or declaration.
+func (lp *LP) lastLine() int32 {
+	var last int32
+	see := func(p Pos) {
+		if p.Line > last {
+			last = p.Line
+		}
+	}
+	for _, h := range lp.Headers {
+		see(h.Pos)
+	}
+	for _, sec := range lp.sections() {
+		for _, sym := range sec.Syms() {
+			see(sym.Pos)
+		}
+	}
+	for _, sym := range lp.RowNames.Syms() {
+		see(sym.Pos)
+	}
+	return last
+}
@@ -0,0 +1,135 @@
+package lp
+
+// Parts of a model that Subset can drop, by index.
+const (
+	PartConstraint = "constraint" // in Rows
+	PartSOS        = "sos"
+	PartGeneral    = "general" // in GenCons
+	PartPWL        = "pwl"     // in PWLObjs
+)
+
+// Subset returns a copy of lp that keeps only the parts
+// for which keep(kind, i) returns true, with kind one of the Part constants,
+// and only the variables for which keepVar returns true.
+// The terms, bounds, and declarations of dropped variables are removed,
+// as are the parts left without variables and indicator constraints
+// whose variable is dropped.
+// Constraints that never had variables, such as "4 >= 7", are kept.
+// The objectives are kept even if they are left empty.
+func Subset(lp *LP, keep func(kind string, i int) bool, keepVar func(name string) bool) *LP {
+	expr := func(e Expr) Expr {
+		out := Expr{Constant: e.Constant}
+		for _, t := range e.Terms {
+			if keepVar(t.Var.Value) {
+				out.Terms = append(out.Terms, t)
+			}
+		}
+		for _, q := range e.Quad {
+			if keepVar(q.Var1.Value) && keepVar(q.Var2.Value) {
+				out.Quad = append(out.Quad, q)
+			}
+		}
+		return out
+	}
+	section := func(s *Section) Section {
+		var out Section
+		for _, sym := range s.Syms() {
+			if keepVar(sym.Value) {
+				out.AddSym(sym)
+			}
+		}
+		return out
+	}
+
+	sub := &LP{
+		Objective:      section(&lp.Objective),
+		Constraints:    section(&lp.Constraints),
+		Bounds:         section(&lp.Bounds),
+		GeneralVars:    section(&lp.GeneralVars),
+		BinaryVars:     section(&lp.BinaryVars),
+		SemiContVars:   section(&lp.SemiContVars),
+		SemiIntVars:    section(&lp.SemiIntVars),
+		CustomContVars: section(&lp.CustomContVars),
+		SOSVars:        section(&lp.SOSVars),
+		FreeVars:       section(&lp.FreeVars),
+		Headers:        lp.Headers,
+		Ignores:        lp.Ignores,
+	}
+	objective := func(o *Objective) *Objective {
+		o2 := *o
+		o2.Expr = expr(o.Expr)
+		return &o2
+	}
+	for _, o := range lp.MultiObj {
+		sub.MultiObj = append(sub.MultiObj, objective(o))
+	}
+	if len(sub.MultiObj) > 0 {
+		sub.Obj = sub.MultiObj[0]
+	} else if lp.Obj != nil {
+		sub.Obj = objective(lp.Obj)
+	}
+
+	kept := make(map[string]bool) // names of the kept rows
+	for i, c := range lp.Rows {
+		if !keep(PartConstraint, i) || c.Indicator != nil && !keepVar(c.Indicator.Var.Value) {
+			continue
+		}
+		c2 := *c
+		c2.LHS, c2.RHS = expr(c.LHS), expr(c.RHS)
+		had := len(c.LHS.Terms) + len(c.LHS.Quad) + len(c.RHS.Terms) + len(c.RHS.Quad)
+		if had > 0 && len(c2.LHS.Terms)+len(c2.LHS.Quad)+len(c2.RHS.Terms)+len(c2.RHS.Quad) == 0 {
+			continue
+		}
+		sub.Rows = append(sub.Rows, &c2)
+		kept[c.Name] = true
+	}
+	for _, o := range lp.objectives() {
+		kept[o.Name] = true
+	}
+	for _, sym := range lp.RowNames.Syms() {
+		if kept[sym.Value] {
+			sub.RowNames.AddSym(sym)
+		}
+	}
+	for _, b := range lp.VarBounds {
+		if keepVar(b.Var.Value) {
+			sub.VarBounds = append(sub.VarBounds, b)
+		}
+	}
+	for i, s := range lp.SOS {
+		if !keep(PartSOS, i) {
+			continue
+		}
+		s2 := *s
+		s2.Members = nil
+		for _, m := range s.Members {
+			if keepVar(m.Var.Value) {
+				s2.Members = append(s2.Members, m)
+			}
+		}
+		if len(s2.Members) > 0 {
+			sub.SOS = append(sub.SOS, &s2)
+		}
+	}
+	for i, g := range lp.GenCons {
+		if !keep(PartGeneral, i) || !keepVar(g.Result.Value) {
+			continue
+		}
+		g2 := *g
+		g2.Args = nil
+		for _, a := range g.Args {
+			if keepVar(a.Value) {
+				g2.Args = append(g2.Args, a)
+			}
+		}
+		if len(g2.Args)+len(g2.Constants) > 0 {
+			sub.GenCons = append(sub.GenCons, &g2)
+		}
+	}
+	for i, pw := range lp.PWLObjs {
+		if keep(PartPWL, i) && keepVar(pw.Var.Value) {
+			sub.PWLObjs = append(sub.PWLObjs, pw)
+		}
+	}
+	return sub
+}
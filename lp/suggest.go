@@ -0,0 +1,57 @@
+package lp
+
+import "strings"
+
+// maxSuggestions limits how many names a suggester looks for,
+// since each look compares the name to all others of similar length.
+const maxSuggestions = 100
+
+// A suggester finds the names that a misspelled name may have been
+// meant to be, as in the "did you mean" of compiler errors.
+type suggester struct {
+	byLen map[int][]string // in order of appearance
+	left  int              // suggestions that may still be looked for
+}
+
+// newSuggester returns a suggester for the names in secs
+// for which keep reports true.
+func newSuggester(keep func(Symbol) bool, secs ...*Section) *suggester {
+	s := &suggester{byLen: make(map[int][]string), left: maxSuggestions}
+	seen := make(map[string]bool)
+	for _, sec := range secs {
+		for _, sym := range sec.Syms() {
+			if !seen[sym.Value] && keep(sym) {
+				seen[sym.Value] = true
+				s.byLen[len(sym.Value)] = append(s.byLen[len(sym.Value)], sym.Value)
+			}
+		}
+	}
+	return s
+}
+
+// suggest returns "; did you mean NAME?" for the closest other name to name,
+// or "" if none is close enough to be a likely typo.
+// The result is escaped for use in a format string.
+func (s *suggester) suggest(name string) string {
+	// Names of one or two characters are too close to too many others.
+	if len(name) < 3 || s.left <= 0 {
+		return ""
+	}
+	s.left--
+	maxDist := (len(name) + 2) / 4
+	best, bestDist := "", maxDist+1
+	for n := len(name) - maxDist; n <= len(name)+maxDist; n++ {
+		for _, c := range s.byLen[n] {
+			if c == name {
+				continue
+			}
+			if d := editDistance(name, c); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "; did you mean " + strings.ReplaceAll(best, "%", "%%") + "?"
+}
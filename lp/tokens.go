@@ -0,0 +1,90 @@
+package lp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A TokenKind classifies a token for syntax highlighting.
+type TokenKind int
+
+const (
+	TokenKeyword  TokenKind = iota // section headers and words like "free"
+	TokenName                      // names of variables, constraints, and objectives
+	TokenNumber                    // numbers
+	TokenOperator                  // signs, relations, and punctuation
+	TokenComment                   // comments, and the "\lpvet:" prefix
+)
+
+// A Token is a piece of an LP file, as the parser splits it.
+type Token struct {
+	Kind TokenKind
+	Pos  Pos // only Line, Col, and EndCol are set
+}
+
+// Tokens splits the lines of an LP file into tokens, in order.
+// Lines that fail to tokenize have tokens only up to the problem.
+func Tokens(lines []string) []Token {
+	var toks []Token
+	add := func(k TokenKind, line, col, endCol int) {
+		if endCol > col {
+			toks = append(toks, Token{k, Pos{Line: int32(line), Col: int32(col), EndCol: int32(endCol)}})
+		}
+	}
+	kind := secNone
+	for i, raw := range lines {
+		n := i + 1
+		end := len(strings.TrimRightFunc(raw, unicode.IsSpace))
+		// col returns the column of t, which is always a suffix
+		// of the line up to its trailing space.
+		col := func(t string) int { return end - len(t) + 1 }
+
+		t := strings.TrimSpace(raw)
+		if strings.HasPrefix(t, "\\") {
+			if _, ok, _ := ignoreDirective(t[1:], Pos{}); ok || !strings.HasPrefix(t, "\\lpvet:") {
+				add(TokenComment, n, col(t), end+1)
+				continue
+			}
+			add(TokenComment, n, col(t), col(t)+len("\\lpvet:"))
+			t = strings.TrimSpace(t[len("\\lpvet:"):])
+		}
+		if h, ok := sectionHeader(t); ok {
+			name := strings.TrimSpace(t[:len(t)-len(h.rest)])
+			add(TokenKeyword, n, col(t), col(t)+len(name))
+			kind, t = h.kind, h.rest
+		}
+		at := Pos{Line: int32(n), Col: int32(col(t))}
+		comment := end + 1 // start of a trailing comment, if any
+		if j := strings.IndexByte(t, '\\'); j >= 0 {
+			comment = col(t[j:])
+			t = t[:j]
+		}
+		punct := ""
+		switch kind {
+		case secObjective, secConstraints:
+			punct = "[]^*/"
+		case secGenCons, secPWLObj:
+			punct = "(),"
+		}
+		lineToks, err := lex(t, at, punct)
+		if e, ok := err.(*SyntaxError); ok {
+			// Keep the tokens before the problem.
+			lineToks, _ = lex(t[:e.Pos.Col-at.Col], at, punct)
+		}
+		for _, tok := range lineToks {
+			k := TokenOperator
+			switch tok.kind {
+			case tokNum:
+				k = TokenNumber
+			case tokIdent:
+				k = TokenName
+				if kind == secBounds && (isInf(tok.text) || strings.EqualFold(tok.text, "free")) {
+					k = TokenKeyword
+				}
+			}
+			add(k, n, int(tok.pos.Col), int(tok.pos.EndCol))
+		}
+		add(TokenComment, n, comment, end+1)
+	}
+	return toks
+}
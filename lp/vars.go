@@ -0,0 +1,24 @@
+package lp
+
+// A Variable describes a variable of a model.
+type Variable struct {
+	Name string
+	Type string // continuous, general, binary, semi-continuous, or semi-integer
+	Pos  Pos    // of its first appearance
+
+	// Lo and Hi are the limits that all of its bound statements give it,
+	// or its default bounds.
+	Lo, Hi float64
+}
+
+// Variables returns the variables of lp in order of first appearance.
+func Variables(lp *LP) []Variable {
+	cols, kinds := lp.columns()
+	bounds := lp.varBounds(kinds)
+	vars := make([]Variable, len(cols))
+	for i, sym := range cols {
+		lo, hi := boundsOf(sym.Value, bounds, kinds)
+		vars[i] = Variable{Name: sym.Value, Type: kinds[sym.Value].String(), Pos: sym.Pos, Lo: lo, Hi: hi}
+	}
+	return vars
+}
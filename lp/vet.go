@@ -0,0 +1,696 @@
+package lp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// A Diagnostic is a single problem found in an LP.
+type Diagnostic struct {
+	Pos      Pos
+	Severity Severity
+	Check    string // ID of the check, see LookupCheck
+	Symbol   string // offending symbol, if any
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Pos, d.Severity, d.Message, d.Check)
+}
+
+// Options control which checks Vet runs.
+type Options struct {
+	Warnings bool     // report warnings in addition to errors
+	Profile  *Profile // if set, report constructs the solver does not support
+
+	// MaxVarLen limits the length of variable names if positive.
+	// Otherwise, the limit of Profile applies, if any.
+	MaxVarLen int
+
+	// TruncateLen is the length that names are truncated to
+	// by the tools that read the model, if positive.
+	// Names that are the same up to it are reported.
+	// Otherwise, names are compared up to the limit on their length.
+	TruncateLen int
+
+	// MaxCoefRange is the largest ratio between the absolute values
+	// of the coefficients of a constraint that is not warned about.
+	// If zero, DefaultMaxCoefRange applies.
+	MaxCoefRange float64
+
+	// StrictDecls requires every variable to be declared,
+	// including continuous ones (in a CONTINUOUS section).
+	// Otherwise, undeclared variables are continuous as in CPLEX.
+	StrictDecls bool
+
+	// Disabled holds the IDs of the checks not to report.
+	// Their problems do not keep the problems of other checks
+	// with the same symbol from being reported.
+	Disabled map[string]bool
+}
+
+// Vet checks lp for misused variables.
+// At most one diagnostic is reported per symbol.
+func Vet(lp *LP, opts Options) []Diagnostic {
+	var diags []Diagnostic
+
+	issuedFor := make(map[string]bool)
+
+	// issueOnce adds d unless its check is disabled
+	// or its symbol already has a diagnostic.
+	issueOnce := func(d Diagnostic) {
+		if !issuedFor[d.Symbol] && !opts.Disabled[d.Check] {
+			diags = append(diags, d)
+			issuedFor[d.Symbol] = true
+		}
+	}
+	issue := func(sev Severity, check, format string, s Symbol) {
+		issueOnce(Diagnostic{
+			Pos:      s.Pos,
+			Severity: sev,
+			Check:    check,
+			Symbol:   s.Value,
+			Message:  fmt.Sprintf(format, s.Value),
+		})
+	}
+
+	haveDecl := func(sym Symbol) bool {
+		if lp.GeneralVars.HasSym(sym) {
+			return true
+		}
+		if lp.BinaryVars.HasSym(sym) {
+			return true
+		}
+		if lp.SemiContVars.HasSym(sym) {
+			return true
+		}
+		if lp.SemiIntVars.HasSym(sym) {
+			return true
+		}
+		if lp.CustomContVars.HasSym(sym) {
+			return true
+		}
+		return false
+	}
+
+	// A typo makes a variable that is used but not declared and one that is
+	// declared but not used, so each is suggested for the other.
+	isUsed := func(sym Symbol) bool { return lp.Objective.HasSym(sym) || lp.Constraints.HasSym(sym) }
+	declared := newSuggester(func(sym Symbol) bool { return !isUsed(sym) },
+		&lp.GeneralVars, &lp.BinaryVars, &lp.SemiContVars, &lp.SemiIntVars, &lp.CustomContVars)
+	usedNames := newSuggester(func(sym Symbol) bool { return !haveDecl(sym) }, &lp.Objective, &lp.Constraints)
+
+	if opts.StrictDecls {
+		for _, sec := range []*Section{&lp.Objective, &lp.Constraints, &lp.Bounds, &lp.SOSVars} {
+			for _, sym := range sec.Syms() {
+				if !haveDecl(sym) && !issuedFor[sym.Value] {
+					issue(Error, checkUndeclared, "no var declaration for %s"+declared.suggest(sym.Value), sym)
+				}
+			}
+		}
+	} else if opts.Warnings {
+		for _, sym := range lp.Bounds.Syms() {
+			if !haveDecl(sym) && !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedBound, "no use of bounded var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+	}
+
+	for _, c := range lp.Rows {
+		if ind := c.Indicator; ind != nil && (haveDecl(ind.Var) || !opts.StrictDecls) && !lp.BinaryVars.HasSym(ind.Var) {
+			diags = append(diags, Diagnostic{
+				Pos:      ind.Var.Pos,
+				Severity: Error,
+				Check:    checkIndicator,
+				Symbol:   ind.Var.Value,
+				Message:  fmt.Sprintf("indicator var %s is not declared binary", ind.Var.Value),
+			})
+		}
+	}
+
+	for _, c := range lp.Rows {
+		if c.Ranged && c.RangeLo > c.RHS.Constant {
+			diags = append(diags, Diagnostic{
+				Pos:      c.Pos,
+				Severity: Error,
+				Check:    checkEmptyRange,
+				Message: fmt.Sprintf("ranged constraint %s has lower limit %s above upper limit %s",
+					c.Name, formatNum(c.RangeLo), formatNum(c.RHS.Constant)),
+			})
+		}
+	}
+
+	for _, s := range lp.SOS {
+		for i := 1; i < len(s.Members); i++ {
+			if m := s.Members[i]; m.Weight <= s.Members[i-1].Weight {
+				diags = append(diags, Diagnostic{
+					Pos:      m.Var.Pos,
+					Severity: Error,
+					Check:    checkSOSWeights,
+					Symbol:   m.Var.Value,
+					Message: fmt.Sprintf("SOS weights must be strictly increasing: %s has weight %s after %s",
+						m.Var.Value, formatNum(m.Weight), formatNum(s.Members[i-1].Weight)),
+				})
+				break
+			}
+		}
+	}
+
+	if n := len(lp.Headers); n > 0 {
+		ended := false
+		for _, h := range lp.Headers {
+			ended = ended || strings.EqualFold(h.Value, "END") || strings.EqualFold(h.Value, "ENDATA")
+		}
+		if !ended {
+			// Point at the last section, which the END should follow.
+			last := lp.Headers[n-1].Pos
+			diags = append(diags, Diagnostic{
+				Pos:      Pos{File: last.File, Line: last.Line},
+				Severity: Warning,
+				Check:    checkMissingEnd,
+				Message:  fmt.Sprintf("missing END after the %s section", lp.Headers[n-1].Value),
+			})
+		}
+	}
+
+	diags = append(diags, emptyBounds(lp)...)
+	diags = append(diags, unboundedSemiCont(lp)...)
+	doms := propagate(lp)
+	diags = append(diags, propagatedBounds(lp, doms, opts.Warnings)...)
+	diags = append(diags, infeasibleRows(lp, doms)...)
+	diags = append(diags, trivialRows(lp, opts.Warnings)...)
+	if opts.Warnings {
+		diags = append(diags, unboundedObjective(lp, doms)...)
+	}
+	diags = append(diags, missingObjective(lp, opts.Profile, opts.Warnings)...)
+	diags = append(diags, repeatedObjectives(lp, opts.Profile)...)
+	diags = append(diags, duplicateRows(lp)...)
+	diags = append(diags, vetAcrossFiles(lp, opts.Warnings)...)
+
+	if opts.Profile != nil {
+		diags = append(diags, checkProfile(lp, opts.Profile)...)
+	}
+
+	maxVarLen, limitFor := opts.MaxVarLen, ""
+	if maxVarLen <= 0 && opts.Profile != nil {
+		maxVarLen, limitFor = opts.Profile.MaxVarLen, " for "+opts.Profile.Name
+	}
+	if maxVarLen > 0 {
+		seen := make(map[string]bool)
+		for _, sec := range lp.sections() {
+			for _, sym := range sec.Syms() {
+				if len(sym.Value) > maxVarLen && !seen[sym.Value] {
+					seen[sym.Value] = true
+					diags = append(diags, Diagnostic{
+						Pos:      sym.Pos,
+						Severity: Error,
+						Check:    checkNameTooLong,
+						Symbol:   sym.Value,
+						Message: fmt.Sprintf("variable %s is too long%s (%d > %d)",
+							sym.Value, limitFor, len(sym.Value), maxVarLen),
+					})
+				}
+			}
+		}
+	}
+	if n := opts.TruncateLen; n > 0 && opts.Warnings {
+		diags = append(diags, truncationCollisions(lp, n, "")...)
+	} else if maxVarLen > 0 && opts.Warnings {
+		diags = append(diags, truncationCollisions(lp, maxVarLen, limitFor)...)
+	}
+
+	if opts.Warnings {
+		freeAt := make(map[string]Pos)
+		for _, sym := range lp.FreeVars.Syms() {
+			if _, ok := freeAt[sym.Value]; !ok {
+				freeAt[sym.Value] = sym.Pos
+			}
+		}
+		for _, b := range lp.VarBounds {
+			if at, ok := freeAt[b.Var.Value]; ok && !b.Free {
+				issueOnce(Diagnostic{
+					Pos:      b.Pos,
+					Severity: Warning,
+					Check:    checkFreeBounded,
+					Symbol:   b.Var.Value,
+					Message:  fmt.Sprintf("%s is declared free at %s but also bounded", b.Var.Value, at),
+				})
+			}
+		}
+
+		for _, d := range conflictingDecls(lp) {
+			issueOnce(d)
+		}
+
+		bounds := append(binaryBounds(lp), fractionalBounds(lp)...)
+		for _, d := range append(bounds, repeatedBounds(lp)...) {
+			issueOnce(d)
+		}
+		diags = append(diags, constantObjectives(lp)...)
+		diags = append(diags, caseDuplicates(lp)...)
+		diags = append(diags, namesLike(lp, isKeyword, checkKeywordName, "%s %s is named like a keyword (use %s_)")...)
+		diags = append(diags, namesLike(lp, isExponentLike, checkExponentName, "%s %s reads as an exponent (use _%s)")...)
+
+		for _, sym := range lp.GeneralVars.Syms() {
+			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedVar, "no use of general var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+
+		for _, sym := range lp.BinaryVars.Syms() {
+			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedVar, "no use of binary var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+
+		for _, sym := range lp.SemiContVars.Syms() {
+			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedVar, "no use of semi-continuous var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+
+		for _, sym := range lp.SemiIntVars.Syms() {
+			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedVar, "no use of semi-integer var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+
+		for _, sym := range lp.CustomContVars.Syms() {
+			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) && !issuedFor[sym.Value] {
+				issue(Warning, checkUnusedVar, "no use of continuous var %s"+usedNames.suggest(sym.Value), sym)
+			}
+		}
+
+		maxRange := opts.MaxCoefRange
+		if maxRange == 0 {
+			maxRange = DefaultMaxCoefRange
+		}
+		for _, r := range CoefficientRanges(lp) {
+			if r.Ratio() > maxRange {
+				diags = append(diags, Diagnostic{
+					Pos:      r.Pos,
+					Severity: Warning,
+					Check:    checkCoefRange,
+					Message: fmt.Sprintf("constraint %s has coefficients from %s to %s (range %.3g > %g)",
+						r.Name, formatNum(r.Min), formatNum(r.Max), r.Ratio(), maxRange),
+				})
+			}
+		}
+	}
+
+	// Drop diagnostics suppressed by lpvet:ignore comments.
+	kept := diags[:0]
+	for _, d := range diags {
+		if !lp.Ignored(d) && !opts.Disabled[d.Check] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// namesLike reports the constraints and variables with names
+// for which like reports true, at their first use.
+// The message is formatted with what is named and the name, twice.
+func namesLike(lp *LP, like func(string) bool, check, format string) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	report := func(what string, sym Symbol) {
+		key := what + "\x00" + sym.Value
+		if seen[key] || !like(sym.Value) {
+			return
+		}
+		seen[key] = true
+		diags = append(diags, Diagnostic{
+			Pos:      sym.Pos,
+			Severity: Warning,
+			Check:    check,
+			Symbol:   sym.Value,
+			Message:  fmt.Sprintf(format, what, sym.Value, sym.Value),
+		})
+	}
+	for _, sym := range lp.RowNames.Syms() {
+		report("constraint", sym)
+	}
+	for _, sec := range lp.sections() {
+		for _, sym := range sec.Syms() {
+			report("variable", sym)
+		}
+	}
+	return diags
+}
+
+// isExponentLike reports whether name is e or E, alone or followed by digits,
+// which CPLEX reserves for the exponents of numbers:
+// 2 e3 may be read as 2000 rather than as 2 times e3.
+func isExponentLike(name string) bool {
+	if name == "" || (name[0] != 'e' && name[0] != 'E') {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if name[i] < '0' || name[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// truncationCollisions reports the variables whose names are the same
+// in their first n characters as the name of a variable that appears before them,
+// which solvers that truncate long names rather than reject them would merge.
+func truncationCollisions(lp *LP, n int, limitFor string) []Diagnostic {
+	var diags []Diagnostic
+	first := make(map[string]Symbol) // by truncated name
+	seen := make(map[string]bool)
+	for _, sec := range lp.sections() {
+		for _, sym := range sec.Syms() {
+			if len(sym.Value) <= n || seen[sym.Value] {
+				continue
+			}
+			seen[sym.Value] = true
+			prefix := sym.Value[:n]
+			other, ok := first[prefix]
+			if !ok {
+				first[prefix] = sym
+				continue
+			}
+			diags = append(diags, Diagnostic{
+				Pos:      sym.Pos,
+				Severity: Warning,
+				Check:    checkTruncation,
+				Symbol:   sym.Value,
+				Message: fmt.Sprintf("variable %s is the same as %s at %s in its first %d characters%s",
+					sym.Value, other.Value, other.Pos, n, limitFor),
+			})
+		}
+	}
+	return diags
+}
+
+// caseDuplicates reports the variables whose names differ only in case
+// from the name of a variable that appears before them.
+func caseDuplicates(lp *LP) []Diagnostic {
+	first := make(map[string]Pos)
+	file := make(map[string]int) // order of the files
+	before := func(a, b Pos) bool {
+		if a.File != b.File {
+			return file[a.File] < file[b.File]
+		}
+		return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+	}
+	groups := make(map[string][]string) // by lowercase name
+	var order []string
+	for _, sec := range lp.sections() {
+		for _, sym := range sec.Syms() {
+			if _, ok := file[sym.Pos.File]; !ok {
+				file[sym.Pos.File] = len(file)
+			}
+			at, ok := first[sym.Value]
+			if !ok {
+				key := strings.ToLower(sym.Value)
+				if len(groups[key]) == 0 {
+					order = append(order, key)
+				}
+				groups[key] = append(groups[key], sym.Value)
+			}
+			if !ok || before(sym.Pos, at) {
+				first[sym.Value] = sym.Pos
+			}
+		}
+	}
+	var diags []Diagnostic
+	for _, key := range order {
+		names := groups[key]
+		if len(names) < 2 {
+			continue
+		}
+		sort.SliceStable(names, func(i, j int) bool { return before(first[names[i]], first[names[j]]) })
+		for _, name := range names[1:] {
+			diags = append(diags, Diagnostic{
+				Pos:      first[name],
+				Severity: Warning,
+				Check:    checkCaseDuplicate,
+				Symbol:   name,
+				Message:  fmt.Sprintf("variable %s differs only in case from %s at %s", name, names[0], first[names[0]]),
+			})
+		}
+	}
+	return diags
+}
+
+// A decl is where a variable is declared to be of a type,
+// or free.
+type decl struct {
+	kind string
+	pos  Pos
+}
+
+// conflictingDecls reports the variables declared with types that
+// contradict each other, such as both general and binary,
+// or declared free but also binary or semi-continuous,
+// at their first contradicting declaration.
+// General and semi-continuous together declare a semi-integer variable,
+// as in CPLEX, and do not contradict each other.
+func conflictingDecls(lp *LP) []Diagnostic {
+	sites := make(map[string][]decl)
+	var order []string
+	// lp_solve's free sections declare variables both continuous and free,
+	// which only means free.
+	freeAt := make(map[Pos]bool)
+	for _, sym := range lp.FreeVars.Syms() {
+		freeAt[sym.Pos] = true
+	}
+	for _, d := range []struct {
+		sec  *Section
+		kind string
+	}{
+		{&lp.GeneralVars, "general"},
+		{&lp.BinaryVars, "binary"},
+		{&lp.SemiContVars, "semi-continuous"},
+		{&lp.SemiIntVars, "semi-integer"},
+		{&lp.CustomContVars, "continuous"},
+		{&lp.FreeVars, "free"},
+	} {
+		for _, sym := range d.sec.Syms() {
+			if d.kind == "continuous" && freeAt[sym.Pos] {
+				continue
+			}
+			ds := sites[sym.Value]
+			if len(ds) == 0 {
+				order = append(order, sym.Value)
+			}
+			dup := false
+			for _, o := range ds {
+				dup = dup || o.kind == d.kind
+			}
+			if !dup {
+				sites[sym.Value] = append(ds, decl{d.kind, sym.Pos})
+			}
+		}
+	}
+	compatible := func(a, b string) bool {
+		if a > b {
+			a, b = b, a
+		}
+		switch a + " " + b {
+		case "general semi-continuous", "general semi-integer", "free general", "continuous free":
+			return true
+		}
+		return false
+	}
+	var diags []Diagnostic
+	for _, name := range order {
+		ds := sites[name]
+		// In the order of the files, rather than of the sections above.
+		file := make(map[string]int)
+		for _, d := range ds {
+			if _, ok := file[d.pos.File]; !ok {
+				file[d.pos.File] = len(file)
+			}
+		}
+		sort.SliceStable(ds, func(i, j int) bool {
+			a, b := ds[i].pos, ds[j].pos
+			if a.File != b.File {
+				return file[a.File] < file[b.File]
+			}
+			return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+		})
+		at := -1
+		for i := 1; i < len(ds) && at < 0; i++ {
+			for _, o := range ds[:i] {
+				if !compatible(o.kind, ds[i].kind) {
+					at = i
+					break
+				}
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		var where []string
+		for _, d := range ds {
+			where = append(where, fmt.Sprintf("%s at %s", d.kind, d.pos))
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      ds[at].pos,
+			Severity: Warning,
+			Check:    checkConflictingDecls,
+			Symbol:   name,
+			Message:  fmt.Sprintf("%s is declared %s", name, strings.Join(where, " and ")),
+		})
+	}
+	return diags
+}
+
+// constantObjectives reports the objectives without variables,
+// once their terms are combined, which are the same for every solution.
+// This usually means that the costs were dropped, so models that only
+// need a feasible solution are expected to suppress it.
+// Models with a Gurobi piecewise-linear objective are left out.
+func constantObjectives(lp *LP) []Diagnostic {
+	if len(lp.PWLObjs) > 0 {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, o := range lp.objectives() {
+		e := o.Expr.Combined()
+		n := 0
+		for _, t := range e.Terms {
+			if t.Coef != 0 {
+				n++
+			}
+		}
+		for _, q := range e.Quad {
+			if q.Coef != 0 {
+				n++
+			}
+		}
+		if n > 0 {
+			continue
+		}
+		name := "objective"
+		if o.Name != "" {
+			name += " " + o.Name
+		}
+		what := "has no variables"
+		switch {
+		case len(o.Expr.Terms)+len(o.Expr.Quad) > 0:
+			what = "has no variables once its terms are combined"
+		case o.Expr.Constant != 0:
+			what = "is the constant " + formatNum(o.Expr.Constant)
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      o.Pos,
+			Severity: Warning,
+			Check:    checkConstantObj,
+			Message: fmt.Sprintf("%s %s (if the model only needs a feasible solution, say so with an lpvet:ignore %s comment)",
+				name, what, checkConstantObj),
+		})
+	}
+	return diags
+}
+
+// missingObjective reports an LP file with a constraints section
+// but no objective section, at the constraints section.
+// Most solvers reject such files, so it is an error
+// unless prof accepts them, when it is a warning, if warnings is set.
+func missingObjective(lp *LP, prof *Profile, warnings bool) []Diagnostic {
+	if lp.Obj != nil {
+		return nil
+	}
+	for _, h := range lp.Headers {
+		if hdr, ok := sectionHeader(h.Value); !ok || hdr.kind != secConstraints {
+			continue
+		}
+		d := Diagnostic{
+			Pos:      h.Pos,
+			Severity: Error,
+			Check:    checkMissingObj,
+			Message:  fmt.Sprintf("no objective section before %s (add Minimize or Maximize)", h.Value),
+		}
+		if prof != nil && prof.OptionalObj {
+			if !warnings {
+				return nil
+			}
+			d.Severity = Warning
+			d.Message = fmt.Sprintf("no objective section before %s, which %s reads as minimizing 0 but other solvers reject",
+				h.Value, prof.Name)
+		}
+		return []Diagnostic{d}
+	}
+	return nil
+}
+
+// repeatedObjectives reports the objective sections of an LP file
+// after its first, of which solvers keep only one.
+// Several objectives are given in a single multi-objective section instead,
+// which is suggested unless prof does not support it.
+func repeatedObjectives(lp *LP, prof *Profile) []Diagnostic {
+	var diags []Diagnostic
+	first := make(map[string]Symbol) // by file
+	for _, h := range lp.Headers {
+		if hdr, ok := sectionHeader(h.Value); !ok || hdr.kind != secObjective {
+			continue
+		}
+		at, ok := first[h.Pos.File]
+		if !ok {
+			first[h.Pos.File] = h
+			continue
+		}
+		hint := ""
+		if prof == nil || prof.MultiObj {
+			hint = fmt.Sprintf(" (for several objectives, use a single %q section)", at.Value+" multi-objectives")
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      h.Pos,
+			Severity: Error,
+			Check:    checkRepeatedObj,
+			Message:  fmt.Sprintf("another objective section after the %s at line %d%s", at.Value, at.Pos.Line, hint),
+		})
+	}
+	return diags
+}
+
+// duplicateRows reports the constraints named like an earlier
+// constraint or objective in the same file.
+func duplicateRows(lp *LP) []Diagnostic {
+	var diags []Diagnostic
+	first := make(map[string]Pos) // by name and file
+	for _, o := range lp.objectives() {
+		if o.Name != "" {
+			first[o.Name+"\x00"+o.Pos.File] = o.Pos
+		}
+	}
+	for _, c := range lp.Rows {
+		if c.Name == "" {
+			continue
+		}
+		key := c.Name + "\x00" + c.Pos.File
+		at, ok := first[key]
+		if !ok {
+			first[key] = c.Pos
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      c.Pos,
+			Severity: Error,
+			Check:    checkDuplicateRow,
+			Message:  fmt.Sprintf("constraint name %s is already used at line %d", c.Name, at.Line),
+		})
+	}
+	return diags
+}
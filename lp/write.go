@@ -0,0 +1,814 @@
+package lp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A varKind is how a variable is declared.
+type varKind int
+
+const (
+	kindCont varKind = iota
+	kindGeneral
+	kindBinary
+	kindSemiCont
+	kindSemiInt
+)
+
+func (k varKind) String() string {
+	switch k {
+	case kindGeneral:
+		return "general"
+	case kindBinary:
+		return "binary"
+	case kindSemiCont:
+		return "semi-continuous"
+	case kindSemiInt:
+		return "semi-integer"
+	}
+	return "continuous"
+}
+
+// columns returns the variables of lp at their first appearance,
+// in order, with their kinds.
+func (lp *LP) columns() ([]Symbol, map[string]varKind) {
+	var syms []Symbol
+	kinds := make(map[string]varKind)
+	seen := make(map[string]bool)
+	add := func(sym Symbol) {
+		if !seen[sym.Value] {
+			seen[sym.Value] = true
+			syms = append(syms, sym)
+		}
+	}
+	if lp.Obj != nil {
+		for _, t := range lp.Obj.Expr.Terms {
+			add(t.Var)
+		}
+		for _, q := range lp.Obj.Expr.Quad {
+			add(q.Var1)
+			add(q.Var2)
+		}
+	}
+	for _, c := range lp.Rows {
+		for _, e := range []Expr{c.LHS, c.RHS} {
+			for _, t := range e.Terms {
+				add(t.Var)
+			}
+			for _, q := range e.Quad {
+				add(q.Var1)
+				add(q.Var2)
+			}
+		}
+	}
+	for _, sec := range lp.sections() {
+		for _, sym := range sec.Syms() {
+			add(sym)
+		}
+	}
+	for _, d := range []struct {
+		sec  *Section
+		kind varKind
+	}{
+		{&lp.GeneralVars, kindGeneral},
+		{&lp.SemiContVars, kindSemiCont},
+		{&lp.SemiIntVars, kindSemiInt},
+		{&lp.BinaryVars, kindBinary},
+	} {
+		for _, sym := range d.sec.Syms() {
+			if d.kind == kindSemiCont && kinds[sym.Value] == kindGeneral {
+				kinds[sym.Value] = kindSemiInt
+			} else {
+				kinds[sym.Value] = d.kind
+			}
+		}
+	}
+	return syms, kinds
+}
+
+// varBounds returns the bounds of each variable with bound statements,
+// applied in order to the default bounds of [0, inf),
+// or [0, 1] for binary variables.
+func (lp *LP) varBounds(kinds map[string]varKind) map[string][2]float64 {
+	bounds := make(map[string][2]float64)
+	for _, b := range lp.VarBounds {
+		name := b.Var.Value
+		r, ok := bounds[name]
+		if !ok {
+			r = [2]float64{0, math.Inf(1)}
+			if kinds[name] == kindBinary {
+				r[1] = 1
+			}
+		}
+		if b.Free {
+			r = [2]float64{math.Inf(-1), math.Inf(1)}
+		}
+		if b.HasLower {
+			r[0] = b.Lower
+		}
+		if b.HasUpper {
+			r[1] = b.Upper
+		}
+		bounds[name] = r
+	}
+	return bounds
+}
+
+// boundsOf returns the limits of the variable name,
+// given the results of columns and varBounds.
+func boundsOf(name string, bounds map[string][2]float64, kinds map[string]varKind) (lo, hi float64) {
+	if r, ok := bounds[name]; ok {
+		return r[0], r[1]
+	}
+	if kinds[name] == kindBinary {
+		return 0, 1
+	}
+	return 0, math.Inf(1)
+}
+
+// rowForm returns the expression of the constraint c with all variables
+// on one side, combining terms of the same variables,
+// and the limits lo <= e <= hi that it imposes.
+// Variables go on the left unless only the right side has any.
+func rowForm(c *Constraint) (e Expr, lo, hi float64) {
+	left, right, rel := c.LHS, c.RHS, c.Rel
+	if len(left.Terms) == 0 && len(left.Quad) == 0 && !c.Ranged {
+		left, right, rel = right, left, rel.Flip()
+	}
+	e = combine(left, right)
+	rhs := right.Constant - left.Constant
+	lo, hi = math.Inf(-1), math.Inf(1)
+	switch {
+	case c.Ranged:
+		lo, hi = c.RangeLo, rhs
+	case rel == RelLE:
+		hi = rhs
+	case rel == RelGE:
+		lo = rhs
+	default:
+		lo, hi = rhs, rhs
+	}
+	return e, lo, hi
+}
+
+// combine returns the terms of a - b, in order of first appearance,
+// combining the terms of each variable and each pair of variables.
+// The result has no constant.
+func combine(a, b Expr) Expr {
+	var e Expr
+	index := make(map[string]int)
+	add := func(t Term, sign float64) {
+		if i, ok := index[t.Var.Value]; ok {
+			e.Terms[i].Coef += sign * t.Coef
+			return
+		}
+		index[t.Var.Value] = len(e.Terms)
+		e.Terms = append(e.Terms, Term{Coef: sign * t.Coef, Var: t.Var})
+	}
+	qindex := make(map[[2]string]int)
+	addQuad := func(q QuadTerm, sign float64) {
+		if q.Var1.Value > q.Var2.Value {
+			q.Var1, q.Var2 = q.Var2, q.Var1
+		}
+		key := [2]string{q.Var1.Value, q.Var2.Value}
+		if i, ok := qindex[key]; ok {
+			e.Quad[i].Coef += sign * q.Coef
+			return
+		}
+		qindex[key] = len(e.Quad)
+		e.Quad = append(e.Quad, QuadTerm{Coef: sign * q.Coef, Var1: q.Var1, Var2: q.Var2})
+	}
+	for _, t := range a.Terms {
+		add(t, 1)
+	}
+	for _, t := range b.Terms {
+		add(t, -1)
+	}
+	for _, q := range a.Quad {
+		addQuad(q, 1)
+	}
+	for _, q := range b.Quad {
+		addQuad(q, -1)
+	}
+	return e
+}
+
+// rowNames returns the names of the objective and rows of lp,
+// naming those without one R1, R2, and so on by position.
+func (lp *LP) rowNames() (obj string, rows []string) {
+	taken := make(map[string]bool)
+	for _, c := range lp.Rows {
+		taken[c.Name] = true
+	}
+	unique := func(name string) string {
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s_%d", strings.TrimRight(name, "_0123456789"), n)
+		}
+		taken[name] = true
+		return name
+	}
+	if lp.Obj != nil && lp.Obj.Name != "" {
+		obj = lp.Obj.Name
+	} else {
+		obj = unique("obj")
+	}
+	for i, c := range lp.Rows {
+		name := c.Name
+		if name == "" {
+			name = unique(fmt.Sprintf("R%d", i+1))
+		}
+		rows = append(rows, name)
+	}
+	return obj, rows
+}
+
+// unsupported returns an error, at its position, for the first construct
+// of lp that the files described by what cannot hold.
+// They hold quadratic constraints if quadRows is set,
+// and multiple objectives if multiObj is.
+func (lp *LP) unsupported(what string, quadRows, multiObj bool) error {
+	switch {
+	case len(lp.MultiObj) > 1 && !multiObj:
+		return fmt.Errorf("%v: %s cannot hold multiple objectives", lp.MultiObj[1].Pos, what)
+	case len(lp.GenCons) > 0:
+		return fmt.Errorf("%v: %s cannot hold general constraints", lp.GenCons[0].Pos, what)
+	case len(lp.PWLObjs) > 0:
+		return fmt.Errorf("%v: %s cannot hold piecewise-linear objectives", lp.PWLObjs[0].Pos, what)
+	}
+	for _, c := range lp.Rows {
+		if c.Indicator != nil {
+			return fmt.Errorf("%v: %s cannot hold indicator constraints", c.Pos, what)
+		}
+		if !quadRows && (len(c.LHS.Quad) > 0 || len(c.RHS.Quad) > 0) {
+			return fmt.Errorf("%v: %s cannot hold quadratic constraints", c.Pos, what)
+		}
+	}
+	if len(lp.SOS) > 0 {
+		return fmt.Errorf("%v: %s cannot hold SOS constraints", lp.SOS[0].Pos, what)
+	}
+	return nil
+}
+
+// WriteMPS writes lp to w as an MPS file in format f,
+// FormatMPS or FormatFreeMPS, with name on its NAME line.
+//
+// Rows without names are named R1, R2, and so on by position,
+// and the objective is named obj if it has no name.
+// Fixed-format files hold names of up to 8 characters without spaces,
+// and numbers of up to 12 characters, which are rounded to fit.
+// Quadratic objectives are written in a QUADOBJ section.
+// WriteMPS returns an error for constructs that MPS files cannot hold,
+// such as indicator and general constraints.
+func WriteMPS(w io.Writer, lp *LP, name string, f Format) error {
+	if f != FormatMPS && f != FormatFreeMPS {
+		return fmt.Errorf("WriteMPS: %v is not an MPS format", f)
+	}
+	if err := lp.unsupported("MPS files", false, false); err != nil {
+		return err
+	}
+	fixed := f == FormatMPS
+	objName, rowNames := lp.rowNames()
+	colSyms, kinds := lp.columns()
+	cols := make([]string, len(colSyms))
+	for i, sym := range colSyms {
+		cols[i] = sym.Value
+	}
+	if fixed {
+		names := colSyms
+		if lp.Obj != nil {
+			names = append(names, Symbol{Value: objName, Pos: lp.Obj.Pos})
+		}
+		for i, c := range lp.Rows {
+			names = append(names, Symbol{Value: rowNames[i], Pos: c.Pos})
+		}
+		for _, n := range names {
+			if len(n.Value) > 8 || strings.ContainsAny(n.Value, " \t") {
+				return fmt.Errorf("%v: name %s does not fit in a fixed-format MPS file; write free-format MPS instead", n.Pos, n.Value)
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	num := func(v float64) string {
+		s := formatNum(v)
+		for prec := 12; fixed && len(s) > 12 && prec > 0; prec-- {
+			s = strconv.FormatFloat(v, 'g', prec, 64)
+		}
+		return s
+	}
+	// line writes a data line with a type and up to three other fields.
+	line := func(typ string, fields ...string) {
+		if !fixed {
+			bw.WriteString(" " + strings.TrimSpace(typ+" "+strings.Join(fields, " ")) + "\n")
+			return
+		}
+		s := fmt.Sprintf(" %-2s %-8s", typ, fields[0])
+		if len(fields) > 1 {
+			s += fmt.Sprintf("  %-8s", fields[1])
+		}
+		if len(fields) > 2 {
+			s += fmt.Sprintf("  %-12s", fields[2])
+		}
+		bw.WriteString(strings.TrimRight(s, " ") + "\n")
+	}
+
+	fmt.Fprintf(bw, "NAME          %s\n", name)
+	if lp.Obj != nil && lp.Obj.Sense == Maximize {
+		bw.WriteString("OBJSENSE\n    MAX\n")
+	}
+	bw.WriteString("ROWS\n")
+	line("N", objName)
+	type entry struct {
+		row  string
+		coef float64
+	}
+	entries := make(map[string][]entry)
+	if lp.Obj != nil {
+		for _, t := range lp.Obj.Expr.Terms {
+			entries[t.Var.Value] = append(entries[t.Var.Value], entry{objName, t.Coef})
+		}
+	}
+	type limits struct{ lo, hi float64 }
+	rowLimits := make([]limits, len(lp.Rows))
+	for i, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		if lo > hi {
+			return fmt.Errorf("%v: the range of %s is empty", c.Pos, rowNames[i])
+		}
+		rowLimits[i] = limits{lo, hi}
+		typ := "E"
+		switch {
+		case math.IsInf(lo, -1):
+			typ = "L"
+		case math.IsInf(hi, 1) || lo != hi:
+			typ = "G"
+		}
+		line(typ, rowNames[i])
+		for _, t := range e.Terms {
+			entries[t.Var.Value] = append(entries[t.Var.Value], entry{rowNames[i], t.Coef})
+		}
+	}
+
+	// writeMarker writes a marker line, whose type is in the fifth field.
+	writeMarker := func(n int, typ string) {
+		name := fmt.Sprintf("MARKER%d", n)
+		if fixed {
+			fmt.Fprintf(bw, "    %-8s  'MARKER'                 %s\n", name, typ)
+		} else {
+			line("", name, "'MARKER'", typ)
+		}
+	}
+	bw.WriteString("COLUMNS\n")
+	inInt := false
+	marker := 0
+	for _, col := range cols {
+		isInt := kinds[col] == kindGeneral || kinds[col] == kindBinary || kinds[col] == kindSemiInt
+		if isInt != inInt {
+			typ := "'INTORG'"
+			if !isInt {
+				typ = "'INTEND'"
+			}
+			writeMarker(marker, typ)
+			marker++
+			inInt = isInt
+		}
+		es := entries[col]
+		if len(es) == 0 {
+			// Columns only exist through their entries.
+			es = []entry{{objName, 0}}
+		}
+		for _, e := range es {
+			line("", col, e.row, num(e.coef))
+		}
+	}
+	if inInt {
+		writeMarker(marker, "'INTEND'")
+	}
+
+	var rhs, ranges []string // lines
+	if lp.Obj != nil && lp.Obj.Expr.Constant != 0 {
+		rhs = append(rhs, objName, num(-lp.Obj.Expr.Constant))
+	}
+	for i, l := range rowLimits {
+		switch {
+		case math.IsInf(l.lo, -1):
+			if l.hi != 0 {
+				rhs = append(rhs, rowNames[i], num(l.hi))
+			}
+		default:
+			if l.lo != 0 {
+				rhs = append(rhs, rowNames[i], num(l.lo))
+			}
+			if !math.IsInf(l.hi, 1) && l.hi != l.lo {
+				ranges = append(ranges, rowNames[i], num(l.hi-l.lo))
+			}
+		}
+	}
+	if len(rhs) > 0 {
+		bw.WriteString("RHS\n")
+		for i := 0; i < len(rhs); i += 2 {
+			line("", "RHS", rhs[i], rhs[i+1])
+		}
+	}
+	if len(ranges) > 0 {
+		bw.WriteString("RANGES\n")
+		for i := 0; i < len(ranges); i += 2 {
+			line("", "RNG", ranges[i], ranges[i+1])
+		}
+	}
+
+	bounds := lp.varBounds(kinds)
+	wroteHeader := false
+	bound := func(typ, col string, v ...float64) {
+		if !wroteHeader {
+			bw.WriteString("BOUNDS\n")
+			wroteHeader = true
+		}
+		if len(v) == 0 {
+			line(typ, "BND", col)
+		} else {
+			line(typ, "BND", col, num(v[0]))
+		}
+	}
+	for _, col := range cols {
+		lo, hi := boundsOf(col, bounds, kinds)
+		switch kind := kinds[col]; {
+		case kind == kindBinary && lo == 0 && hi == 1:
+			bound("BV", col)
+		case kind == kindSemiCont || kind == kindSemiInt:
+			if lo != 0 {
+				bound("LO", col, lo)
+			}
+			if math.IsInf(hi, 1) {
+				bound("SC", col)
+			} else {
+				bound("SC", col, hi)
+			}
+		case lo == hi:
+			bound("FX", col, lo)
+		case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+			bound("FR", col)
+		default:
+			if math.IsInf(lo, -1) {
+				bound("MI", col)
+			} else if lo != 0 || hi < 0 {
+				// Some readers make a negative upper bound imply
+				// a lower bound of -inf unless one is given.
+				bound("LO", col, lo)
+			}
+			if !math.IsInf(hi, 1) {
+				bound("UP", col, hi)
+			}
+		}
+	}
+
+	if lp.Obj != nil && len(lp.Obj.Expr.Quad) > 0 {
+		// QUADOBJ holds one triangle of Q in the objective's x'Qx/2.
+		colIndex := make(map[string]int)
+		for i, c := range cols {
+			colIndex[c] = i
+		}
+		type pair struct{ i, j int }
+		q := make(map[pair]float64)
+		var order []pair
+		for _, t := range lp.Obj.Expr.Quad {
+			p := pair{colIndex[t.Var1.Value], colIndex[t.Var2.Value]}
+			if p.i > p.j {
+				p.i, p.j = p.j, p.i
+			}
+			if _, ok := q[p]; !ok {
+				order = append(order, p)
+			}
+			if p.i == p.j {
+				q[p] += 2 * t.Coef
+			} else {
+				q[p] += t.Coef
+			}
+		}
+		sort.Slice(order, func(a, b int) bool {
+			if order[a].i != order[b].i {
+				return order[a].i < order[b].i
+			}
+			return order[a].j < order[b].j
+		})
+		bw.WriteString("QUADOBJ\n")
+		for _, p := range order {
+			line("", cols[p.i], cols[p.j], num(q[p]))
+		}
+	}
+	bw.WriteString("ENDATA\n")
+	return bw.Flush()
+}
+
+// WriteLP writes lp to w as a CPLEX LP file.
+//
+// Rows keep their names, and constants are moved to the right-hand side.
+// Bounds are written once per variable, with the limits that
+// the model's bound statements give it, and variables declared
+// CONTINUOUS, as in models read from MPS files, are listed in
+// a \lpvet:CONTINUOUS section. The Gurobi extensions are written too.
+// WriteLP returns an error if a name cannot be used in LP files.
+func WriteLP(w io.Writer, lp *LP) error {
+	cols, kinds := lp.columns()
+	for _, sym := range cols {
+		if err := CheckName(FormatLP, sym.Value); err != nil {
+			return fmt.Errorf("%v: %v", sym.Pos, err)
+		}
+	}
+	for _, c := range lp.Rows {
+		if c.Name == "" {
+			continue
+		}
+		if err := CheckName(FormatLP, c.Name); err != nil {
+			return fmt.Errorf("%v: %v", c.Pos, err)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	// stmt writes a statement, breaking lines that are too long.
+	stmt := func(label, text string) {
+		if label != "" {
+			text = label + ": " + text
+		}
+		for _, l := range wrapLine(" "+text, "   ", MaxLineLen) {
+			bw.WriteString(l + "\n")
+		}
+	}
+
+	sense := Minimize
+	if lp.Obj != nil {
+		sense = lp.Obj.Sense
+	}
+	header := "Minimize"
+	if sense == Maximize {
+		header = "Maximize"
+	}
+	if len(lp.MultiObj) > 1 {
+		bw.WriteString(header + " multi-objectives\n")
+		for _, o := range lp.MultiObj {
+			bw.WriteString(" " + o.Name + ":" + objParams(o.Params) + "\n")
+			for _, l := range wrapLine("  "+exprText(o.Expr, true), "   ", MaxLineLen) {
+				bw.WriteString(l + "\n")
+			}
+		}
+	} else {
+		bw.WriteString(header + "\n")
+		if lp.Obj != nil {
+			stmt(lp.Obj.Name, exprText(lp.Obj.Expr, true))
+		}
+	}
+
+	bw.WriteString("Subject To\n")
+	for _, c := range lp.Rows {
+		e, lo, hi := rowForm(c)
+		if len(e.Terms) == 0 && len(e.Quad) == 0 && len(cols) > 0 {
+			// Rows need a variable to be read as rows.
+			e.Terms = []Term{{Coef: 0, Var: cols[0]}}
+		}
+		stmt(c.Name, rowText(c, e, lo, hi))
+	}
+
+	bounds := lp.varBounds(kinds)
+	var bs []string
+	for _, sym := range cols {
+		r, ok := bounds[sym.Value]
+		if !ok {
+			continue
+		}
+		lo, hi := r[0], r[1]
+		name := sym.Value
+		switch {
+		case kinds[name] == kindBinary && lo == 0 && hi == 1:
+		case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+			bs = append(bs, name+" free")
+		case lo == hi:
+			bs = append(bs, name+" = "+formatNum(lo))
+		case lo == 0 && math.IsInf(hi, 1):
+		case lo == 0 && hi >= 0:
+			bs = append(bs, name+" <= "+formatNum(hi))
+		case math.IsInf(hi, 1):
+			bs = append(bs, name+" >= "+formatNum(lo))
+		default:
+			bs = append(bs, formatNum(lo)+" <= "+name+" <= "+formatNum(hi))
+		}
+	}
+	if len(bs) > 0 {
+		bw.WriteString("Bounds\n")
+		for _, b := range bs {
+			stmt("", b)
+		}
+	}
+
+	for _, d := range []struct {
+		header string
+		kind   varKind
+	}{
+		{"General", kindGeneral},
+		{"Binary", kindBinary},
+		{"Semi-Continuous", kindSemiCont},
+		{"Semi-Integer", kindSemiInt},
+	} {
+		var names []string
+		for _, sym := range cols {
+			if kinds[sym.Value] == d.kind {
+				names = append(names, sym.Value)
+			}
+		}
+		if len(names) > 0 {
+			bw.WriteString(d.header + "\n")
+			for _, l := range wrapLine(" "+strings.Join(names, " "), " ", MaxLineLen) {
+				bw.WriteString(l + "\n")
+			}
+		}
+	}
+	var conts []string
+	for _, sym := range cols {
+		if kinds[sym.Value] == kindCont && lp.CustomContVars.HasSym(sym) {
+			conts = append(conts, sym.Value)
+		}
+	}
+	if len(conts) > 0 {
+		bw.WriteString("\\lpvet:CONTINUOUS\n")
+		for _, l := range wrapLine("\\lpvet: "+strings.Join(conts, " "), "\\lpvet: ", MaxLineLen) {
+			bw.WriteString(l + "\n")
+		}
+	}
+
+	if len(lp.SOS) > 0 {
+		bw.WriteString("SOS\n")
+		for _, s := range lp.SOS {
+			stmt(s.Name, sosText(s))
+		}
+	}
+	if len(lp.PWLObjs) > 0 {
+		bw.WriteString("PWLObj\n")
+		for _, pw := range lp.PWLObjs {
+			stmt(pw.Var.Value, pwlText(pw))
+		}
+	}
+	if len(lp.GenCons) > 0 {
+		bw.WriteString("General Constraints\n")
+		for _, g := range lp.GenCons {
+			stmt(g.Name, genConText(g))
+		}
+	}
+	bw.WriteString("End\n")
+	return bw.Flush()
+}
+
+// rowText returns the constraint c, whose expression and limits
+// are as returned by rowForm, as it is written in an LP file.
+func rowText(c *Constraint, e Expr, lo, hi float64) string {
+	var text string
+	if c.Ranged {
+		text = formatNum(lo) + " <= " + exprText(e, false) + " <= " + formatNum(hi)
+	} else {
+		text = exprText(e, false) + " " + limitsText(lo, hi, false)
+	}
+	if ind := c.Indicator; ind != nil {
+		text = indicatorText(ind) + " " + text
+	}
+	return text
+}
+
+// limitsText returns the relation and right-hand side of
+// a row with limits lo and hi, such as "<= 4", or "in [lo, hi]"
+// for ranged rows.
+func limitsText(lo, hi float64, ranged bool) string {
+	switch {
+	case ranged:
+		return "in " + rangeText(lo, hi)
+	case math.IsInf(lo, -1):
+		return "<= " + formatNum(hi)
+	case math.IsInf(hi, 1):
+		return ">= " + formatNum(lo)
+	}
+	return "= " + formatNum(lo)
+}
+
+// rangeText returns the interval [lo, hi].
+func rangeText(lo, hi float64) string {
+	return "[" + formatNum(lo) + ", " + formatNum(hi) + "]"
+}
+
+func indicatorText(ind *Indicator) string {
+	arrow := "->"
+	if ind.Equiv {
+		arrow = "<->"
+	}
+	return ind.Var.Value + " = " + strconv.Itoa(ind.Value) + " " + arrow
+}
+
+func sosText(s *SOS) string {
+	text := fmt.Sprintf("S%d::", s.Type)
+	for _, m := range s.Members {
+		text += " " + m.Var.Value + ":" + formatNum(m.Weight)
+	}
+	return text
+}
+
+func pwlText(pw *PWLObj) string {
+	var pts []string
+	for _, pt := range pw.Points {
+		pts = append(pts, "("+formatNum(pt[0])+", "+formatNum(pt[1])+")")
+	}
+	return strings.Join(pts, " ")
+}
+
+func genConText(g *GenConstraint) string {
+	var args []string
+	for _, a := range g.Args {
+		args = append(args, a.Value)
+	}
+	for _, c := range g.Constants {
+		args = append(args, formatNum(c))
+	}
+	return g.Result.Value + " = " + g.Func + " ( " + strings.Join(args, " , ") + " )"
+}
+
+// objParams returns the parameters of a Gurobi multi-objective
+// as they are written after its name, Gurobi's own ones first.
+func objParams(params map[string]float64) string {
+	keys := []string{"Priority", "Weight", "AbsTol", "RelTol"}
+	var rest []string
+	for k := range params {
+		switch k {
+		case "Priority", "Weight", "AbsTol", "RelTol":
+		default:
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	var b strings.Builder
+	for _, k := range append(keys, rest...) {
+		if v, ok := params[k]; ok {
+			fmt.Fprintf(&b, " %s=%s", k, formatNum(v))
+		}
+	}
+	return b.String()
+}
+
+// exprText returns e as it is written in an LP file.
+// In objectives, quadratic terms are written as "[ ... ] / 2".
+func exprText(e Expr, objective bool) string {
+	var b strings.Builder
+	n := 0
+	term := func(coef float64, name string) {
+		switch {
+		case n > 0 && coef < 0:
+			b.WriteString(" - ")
+			coef = -coef
+		case n > 0:
+			b.WriteString(" + ")
+		case coef < 0:
+			b.WriteString("- ")
+			coef = -coef
+		}
+		if coef != 1 {
+			b.WriteString(formatNum(coef) + " ")
+		}
+		b.WriteString(name)
+		n++
+	}
+	for _, t := range e.Terms {
+		term(t.Coef, t.Var.Value)
+	}
+	if len(e.Quad) > 0 {
+		if n > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString("[ ")
+		n = 0
+		for _, q := range e.Quad {
+			c := q.Coef
+			if objective {
+				c *= 2
+			}
+			if q.Var1.Value == q.Var2.Value {
+				term(c, q.Var1.Value+" ^ 2")
+			} else {
+				term(c, q.Var1.Value+" * "+q.Var2.Value)
+			}
+		}
+		b.WriteString(" ]")
+		if objective {
+			b.WriteString(" / 2")
+		}
+		n++
+	}
+	switch c := e.Constant; {
+	case n == 0:
+		b.WriteString(formatNum(c))
+	case c < 0:
+		b.WriteString(" - " + formatNum(-c))
+	case c > 0:
+		b.WriteString(" + " + formatNum(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// fmtCmd implements "lpvet fmt", which formats LP files.
+func fmtCmd(args []string) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the result to the file instead of stdout")
+	list := fs.Bool("l", false, "list the files whose formatting differs")
+	recursive := fs.Bool("r", false, "format the models in directories recursively")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet fmt [-w] [-l] [-r] f.lp|dir/...|glob|- [...]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+	}
+	files, _, err := expandArgs(fs.Args(), *recursive)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	failed := false
+	for _, p := range files {
+		if err := fmtFile(p, *write, *list); err != nil {
+			if errs, ok := err.(lp.ErrorList); ok {
+				for _, e := range errs {
+					log.Print(e)
+				}
+			} else {
+				log.Print(err)
+			}
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// fmtFile formats the LP file at p, or stdin if p is "-".
+func fmtFile(p string, write, list bool) error {
+	name := p
+	var src []byte
+	var err error
+	if p == "-" {
+		name = *cmdStdinName
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(p)
+	}
+	if err != nil {
+		return err
+	}
+	if f := formatOf(name); f != lp.FormatLP && f != lp.FormatGLPK {
+		return fmt.Errorf("%s: only LP files can be formatted, not %v", name, f)
+	}
+	out, err := lp.FormatSource(name, src)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(src, out) {
+		if !list && !write {
+			os.Stdout.Write(out)
+		}
+		return nil
+	}
+	if list {
+		fmt.Println(name)
+	}
+	if write && p != "-" {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(p, out, fi.Mode().Perm())
+	}
+	if !list {
+		os.Stdout.Write(out)
+	}
+	return nil
+}
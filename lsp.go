@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// This file implements a Language Server Protocol server over stdio,
+// started with -lsp, that publishes diagnostics as documents are edited.
+
+// An lspMessage is a JSON-RPC 2.0 request, response, or notification.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes.
+const (
+	lspInvalidParams  = -32602
+	lspMethodNotFound = -32601
+	lspInvalidRequest = -32600
+	lspRequestFailed  = -32803
+)
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"` // in UTF-16 code units
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"` // 1 for errors, 2 for warnings
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspTextDocument struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// A document is a file open in the editor.
+type document struct {
+	uri   string
+	name  string // path used in diagnostics and to choose the format
+	doc   *lp.Document
+	lines []string
+
+	model *lp.LP // nil if the text could not be read at all
+	diags []lp.Diagnostic
+}
+
+func newDocument(uri, text string) *document {
+	name := uriPath(uri)
+	d := &document{uri: uri, name: name, doc: lp.NewDocument(name, formatOf(name), text)}
+	d.doc.SetLimits(limits())
+	d.update()
+	return d
+}
+
+// setText replaces the text of d and analyzes it again.
+func (d *document) setText(text string) {
+	d.doc.SetText(text)
+	d.update()
+}
+
+// edit replaces the text in r with text.
+// Call update once all edits are applied.
+func (d *document) edit(r lspRange, text string) {
+	pos := func(p lspPosition) lp.Pos {
+		return lp.Pos{Line: int32(p.Line) + 1, Col: int32(byteCol(d.line(p.Line), p.Character)) + 1}
+	}
+	d.doc.Edit(pos(r.Start), pos(r.End), text)
+	d.lines = d.doc.Lines()
+}
+
+// update analyzes the text of d,
+// parsing again only what changed since the last update.
+func (d *document) update() {
+	d.lines = d.doc.Lines()
+	var err error
+	d.model, d.diags, err = analyzeModel(d.doc.Model())
+	if err != nil {
+		d.diags = []lp.Diagnostic{{
+			Pos:      lp.Pos{File: d.name},
+			Severity: lp.Error,
+			Message:  err.Error(),
+		}}
+	}
+}
+
+func (d *document) line(n int) string {
+	if n < 0 || n >= len(d.lines) {
+		return ""
+	}
+	return d.lines[n]
+}
+
+// rangeOf converts pos to an LSP range.
+// Positions without a column cover their whole line.
+func (d *document) rangeOf(pos lp.Pos) lspRange {
+	n := int(pos.Line) - 1
+	if n < 0 {
+		n = 0
+	}
+	line := d.line(n)
+	start, end := int(pos.Col)-1, int(pos.EndCol)-1
+	if pos.Col == 0 {
+		start, end = 0, len(line)
+	}
+	if end < start {
+		end = start
+	}
+	return lspRange{
+		Start: lspPosition{n, utf16Len(line, start)},
+		End:   lspPosition{n, utf16Len(line, end)},
+	}
+}
+
+// utf16Len returns the length in UTF-16 code units of line[:n].
+func utf16Len(line string, n int) int {
+	if n > len(line) {
+		// Past the end, as for missing tokens; count the excess as bytes.
+		return utf16Len(line, len(line)) + n - len(line)
+	}
+	units := 0
+	for _, r := range line[:n] {
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
+
+// uriPath returns the file path of a file: URI,
+// or the URI itself for other schemes.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// An lspServer serves one client.
+type lspServer struct {
+	r        *bufio.Reader
+	w        io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+// serveLSP runs a language server on r and w until the client exits.
+// It returns an error if the connection fails
+// or the client exits without shutting the server down first.
+func serveLSP(r io.Reader, w io.Writer) error {
+	s := &lspServer{
+		r:    bufio.NewReader(r),
+		w:    w,
+		docs: make(map[string]*document),
+	}
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return fmt.Errorf("lsp: client closed the connection")
+		}
+		if err != nil {
+			return fmt.Errorf("lsp: %v", err)
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("lsp: exit without shutdown")
+			}
+			return nil
+		}
+		result, rerr := s.handle(msg)
+		if msg.ID == nil {
+			continue // notification
+		}
+		resp := &lspMessage{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rerr}
+		if result == nil && rerr == nil {
+			// The result of a successful request must be present, even if null.
+			resp.Result = json.RawMessage("null")
+		}
+		if err := s.write(resp); err != nil {
+			return fmt.Errorf("lsp: %v", err)
+		}
+	}
+}
+
+// read reads a message framed by a Content-Length header.
+func (s *lspServer) read() (*lspMessage, error) {
+	hdr, err := textproto.NewReader(s.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(hdr.Get("Content-Length"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", hdr.Get("Content-Length"))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(s.r, body); err != nil {
+		return nil, err
+	}
+	msg := new(lspMessage)
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *lspServer) write(msg *lspMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.w.Write(body)
+	return err
+}
+
+func (s *lspServer) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(&lspMessage{Method: method, Params: data})
+}
+
+// handle handles a request or notification
+// and returns the result for requests.
+func (s *lspServer) handle(msg *lspMessage) (interface{}, *lspError) {
+	if s.shutdown && msg.Method != "exit" {
+		return nil, &lspError{lspInvalidRequest, "server is shut down"}
+	}
+	var params struct {
+		TextDocument   lspTextDocument `json:"textDocument"`
+		ContentChanges []struct {
+			Range *lspRange `json:"range"`
+			Text  string    `json:"text"`
+		} `json:"contentChanges"`
+		Position lspPosition `json:"position"`
+		Range    lspRange    `json:"range"`
+		NewName  string      `json:"newName"`
+		Context  struct {
+			IncludeDeclaration bool `json:"includeDeclaration"`
+		} `json:"context"`
+	}
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+	d := s.docs[uri]
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    2, // incremental
+					"save":      map[string]bool{"includeText": false},
+				},
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"renameProvider":         map[string]bool{"prepareProvider": true},
+				"documentSymbolProvider": true,
+				"hoverProvider":          true,
+				"codeActionProvider":     map[string][]string{"codeActionKinds": {"quickfix"}},
+				"semanticTokensProvider": map[string]interface{}{
+					"legend": map[string][]string{"tokenTypes": lspTokenTypes, "tokenModifiers": lspTokenModifiers},
+					"full":   true,
+				},
+			},
+			"serverInfo": map[string]string{"name": "lpvet"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		s.docs[uri] = newDocument(uri, params.TextDocument.Text)
+		s.publish(s.docs[uri])
+	case "textDocument/didChange":
+		if d == nil || len(params.ContentChanges) == 0 {
+			break
+		}
+		for _, ch := range params.ContentChanges {
+			if ch.Range == nil {
+				d.doc.SetText(ch.Text)
+				d.lines = d.doc.Lines()
+			} else {
+				d.edit(*ch.Range, ch.Text)
+			}
+		}
+		d.update()
+		s.publish(d)
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         uri,
+			"diagnostics": []lspDiagnostic{},
+		})
+	case "textDocument/definition":
+		if d == nil {
+			return nil, nil
+		}
+		return d.definition(params.Position), nil
+	case "textDocument/references":
+		if d == nil {
+			return nil, nil
+		}
+		return d.references(params.Position, params.Context.IncludeDeclaration), nil
+	case "textDocument/codeAction":
+		if d == nil {
+			return nil, nil
+		}
+		return d.codeActions(params.Range), nil
+	case "textDocument/semanticTokens/full":
+		if d == nil {
+			return nil, nil
+		}
+		return d.semanticTokens(), nil
+	case "textDocument/hover":
+		if d == nil {
+			return nil, nil
+		}
+		if h := d.hover(params.Position); h != nil {
+			return h, nil
+		}
+		return nil, nil
+	case "textDocument/documentSymbol":
+		if d == nil {
+			return nil, nil
+		}
+		return d.outline(), nil
+	case "textDocument/prepareRename":
+		if d == nil {
+			return nil, nil
+		}
+		if r := d.prepareRename(params.Position); r != nil {
+			return r, nil
+		}
+		return nil, nil
+	case "textDocument/rename":
+		if d == nil {
+			return nil, &lspError{lspRequestFailed, "unknown document " + uri}
+		}
+		edit, err := d.rename(params.Position, params.NewName)
+		if err != nil {
+			return nil, &lspError{lspRequestFailed, err.Error()}
+		}
+		return edit, nil
+	case "initialized", "textDocument/didSave", "$/cancelRequest", "$/setTrace":
+	default:
+		if msg.ID != nil {
+			return nil, &lspError{lspMethodNotFound, "unsupported method " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+func (d *document) lspDiagnostic(fd lp.Diagnostic) lspDiagnostic {
+	sev := 1
+	if fd.Severity == lp.Warning {
+		sev = 2
+	}
+	return lspDiagnostic{
+		Range:    d.rangeOf(fd.Pos),
+		Severity: sev,
+		Code:     fd.Check,
+		Source:   "lpvet",
+		Message:  fd.Message,
+	}
+}
+
+// publish sends the problems in d to the client.
+// Warnings are always included, since editors show them unobtrusively.
+func (s *lspServer) publish(d *document) {
+	diags := []lspDiagnostic{}
+	var found []lp.Diagnostic
+	if !cfg.ignored(d.name) {
+		found = applyPolicy(d.diags, true)
+	}
+	for _, fd := range found {
+		diags = append(diags, d.lspDiagnostic(fd))
+	}
+	s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         d.uri,
+		"diagnostics": diags,
+	})
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+// codeActions returns the fixes for the problems in d on the lines of r.
+func (d *document) codeActions(r lspRange) []lspCodeAction {
+	actions := []lspCodeAction{}
+	if d.model == nil || cfg.ignored(d.name) {
+		return actions
+	}
+	for _, fd := range applyPolicy(d.diags, true) {
+		line := int(fd.Pos.Line) - 1
+		if line < r.Start.Line || line > r.End.Line {
+			continue
+		}
+		for _, fx := range fixesFor(fd, d.lines, d.model, formatOf(d.name)) {
+			var edits []lspTextEdit
+			for _, e := range fx.edits {
+				edits = append(edits, lspTextEdit{Range: d.rangeOf(e.pos), NewText: e.text})
+			}
+			actions = append(actions, lspCodeAction{
+				Title:       fx.title,
+				Kind:        "quickfix",
+				Diagnostics: []lspDiagnostic{d.lspDiagnostic(fd)},
+				Edit:        lspWorkspaceEdit{Changes: map[string][]lspTextEdit{d.uri: edits}},
+			})
+		}
+	}
+	return actions
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type lspHover struct {
+	Contents lspMarkup `json:"contents"`
+	Range    lspRange  `json:"range"`
+}
+
+type lspMarkup struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// varType describes how name is declared in m.
+func varType(m *lp.LP, name string) string {
+	sym := lp.Symbol{Value: name}
+	switch {
+	case m.BinaryVars.HasSym(sym):
+		return "binary"
+	case m.GeneralVars.HasSym(sym):
+		return "general integer"
+	case m.SemiIntVars.HasSym(sym):
+		return "semi-integer"
+	case m.SemiContVars.HasSym(sym):
+		return "semi-continuous"
+	case m.CustomContVars.HasSym(sym):
+		return "continuous"
+	}
+	return "continuous (not declared)"
+}
+
+// varBounds returns the bounds of name in m,
+// applying its bound statements in order to the default of [0, inf).
+func varBounds(m *lp.LP, name string) (lo, hi float64) {
+	lo, hi = 0, math.Inf(1)
+	if m.BinaryVars.HasSym(lp.Symbol{Value: name}) {
+		hi = 1
+	}
+	for _, b := range m.VarBounds {
+		if b.Var.Value != name {
+			continue
+		}
+		if b.Free {
+			lo, hi = math.Inf(-1), math.Inf(1)
+		}
+		if b.HasLower {
+			lo = b.Lower
+		}
+		if b.HasUpper {
+			hi = b.Upper
+		}
+	}
+	return lo, hi
+}
+
+func formatBound(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func exprUses(e lp.Expr, name string) int {
+	n := 0
+	for _, t := range e.Terms {
+		if t.Var.Value == name {
+			n++
+		}
+	}
+	for _, q := range e.Quad {
+		if q.Var1.Value == name || q.Var2.Value == name {
+			n++
+		}
+	}
+	return n
+}
+
+// varUses counts the constraints that use name in m
+// and the objective terms that contain it.
+func varUses(m *lp.LP, name string) (cons, objTerms int) {
+	for _, c := range m.Rows {
+		if exprUses(c.LHS, name)+exprUses(c.RHS, name) > 0 || c.Indicator != nil && c.Indicator.Var.Value == name {
+			cons++
+		}
+	}
+	for _, g := range m.GenCons {
+		used := g.Result.Value == name
+		for _, a := range g.Args {
+			used = used || a.Value == name
+		}
+		if used {
+			cons++
+		}
+	}
+	objs := m.MultiObj
+	if len(objs) == 0 && m.Obj != nil {
+		objs = []*lp.Objective{m.Obj}
+	}
+	for _, o := range objs {
+		objTerms += exprUses(o.Expr, name)
+	}
+	for _, pw := range m.PWLObjs {
+		if pw.Var.Value == name {
+			objTerms++
+		}
+	}
+	return cons, objTerms
+}
+
+// hover describes the variable at pos.
+func (d *document) hover(pos lspPosition) *lspHover {
+	sym, ok := d.symbolAt(pos)
+	if !ok {
+		return nil
+	}
+	name := sym.Value
+	lo, hi := varBounds(d.model, name)
+	cons, objTerms := varUses(d.model, name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s`: %s\n\n", name, varType(d.model, name))
+	fmt.Fprintf(&b, "bounds: %s <= %s <= %s\n\n", formatBound(lo), name, formatBound(hi))
+	fmt.Fprintf(&b, "used in %s and %s", plural(cons, "constraint"), plural(objTerms, "objective term"))
+	return &lspHover{
+		Contents: lspMarkup{Kind: "markdown", Value: b.String()},
+		Range:    d.rangeOf(sym.Pos),
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+// byteCol returns the byte offset in line of the UTF-16 offset char.
+func byteCol(line string, char int) int {
+	units := 0
+	for i, r := range line {
+		if units >= char {
+			return i
+		}
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return len(line)
+}
+
+// declSections returns the sections of m that declare variable types.
+func declSections(m *lp.LP) []*lp.Section {
+	return []*lp.Section{
+		&m.GeneralVars, &m.BinaryVars, &m.SemiContVars, &m.SemiIntVars, &m.CustomContVars,
+	}
+}
+
+// useSections returns the sections of m where variables are used.
+func useSections(m *lp.LP) []*lp.Section {
+	return []*lp.Section{&m.Objective, &m.Constraints, &m.Bounds, &m.SOSVars, &m.FreeVars}
+}
+
+// occurrences returns the variables in d named name, in order of position.
+// With decls set, they include declarations.
+func (d *document) occurrences(name string, decls bool) []lp.Symbol {
+	if d.model == nil {
+		return nil
+	}
+	secs := useSections(d.model)
+	if decls {
+		secs = append(secs, declSections(d.model)...)
+	}
+	return d.collect(name, secs)
+}
+
+// collect returns the symbols in secs named name, in order of position.
+func (d *document) collect(name string, secs []*lp.Section) []lp.Symbol {
+	// A symbol can be recorded in several sections,
+	// such as a free bound in both Bounds and FreeVars.
+	seen := make(map[lp.Pos]bool)
+	var syms []lp.Symbol
+	for _, sec := range secs {
+		for _, sym := range sec.Syms() {
+			if sym.Value != name || sym.Pos.Col == 0 || sym.Pos.File != d.name || seen[sym.Pos] {
+				continue
+			}
+			seen[sym.Pos] = true
+			syms = append(syms, sym)
+		}
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		a, b := syms[i].Pos, syms[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	return syms
+}
+
+// symbolAt returns the variable at pos, if any.
+func (d *document) symbolAt(pos lspPosition) (lp.Symbol, bool) {
+	if d.model == nil {
+		return lp.Symbol{}, false
+	}
+	return d.find(pos, append(useSections(d.model), declSections(d.model)...))
+}
+
+// rowAt returns the constraint or objective name at pos, if any.
+func (d *document) rowAt(pos lspPosition) (lp.Symbol, bool) {
+	if d.model == nil {
+		return lp.Symbol{}, false
+	}
+	return d.find(pos, []*lp.Section{&d.model.RowNames})
+}
+
+// find returns the symbol in secs at pos.
+func (d *document) find(pos lspPosition, secs []*lp.Section) (lp.Symbol, bool) {
+	line := int32(pos.Line + 1)
+	col := int32(byteCol(d.line(pos.Line), pos.Character) + 1)
+	for _, sec := range secs {
+		for _, sym := range sec.Syms() {
+			p := sym.Pos
+			// The end is included so that a cursor just past a name finds it.
+			if p.Line == line && p.File == d.name && p.Col <= col && col <= p.EndCol {
+				return sym, true
+			}
+		}
+	}
+	return lp.Symbol{}, false
+}
+
+func (d *document) location(sym lp.Symbol) lspLocation {
+	return lspLocation{URI: d.uri, Range: d.rangeOf(sym.Pos)}
+}
+
+// definition returns the declaration of the variable at pos,
+// or its first use if it is not declared.
+func (d *document) definition(pos lspPosition) []lspLocation {
+	sym, ok := d.symbolAt(pos)
+	if !ok {
+		return nil
+	}
+	for _, sec := range declSections(d.model) {
+		for _, decl := range sec.Syms() {
+			if decl.Value == sym.Value && decl.Pos.Col > 0 && decl.Pos.File == d.name {
+				return []lspLocation{d.location(decl)}
+			}
+		}
+	}
+	if syms := d.occurrences(sym.Value, false); len(syms) > 0 {
+		return []lspLocation{d.location(syms[0])}
+	}
+	return nil
+}
+
+// references returns every occurrence of the variable at pos.
+func (d *document) references(pos lspPosition, includeDecl bool) []lspLocation {
+	sym, ok := d.symbolAt(pos)
+	if !ok {
+		return nil
+	}
+	locs := []lspLocation{}
+	for _, s := range d.occurrences(sym.Value, includeDecl) {
+		locs = append(locs, d.location(s))
+	}
+	return locs
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+// renameTarget returns the occurrences of the variable or row name at pos.
+func (d *document) renameTarget(pos lspPosition) (syms []lp.Symbol, row bool, ok bool) {
+	if sym, ok := d.rowAt(pos); ok {
+		return d.collect(sym.Value, []*lp.Section{&d.model.RowNames}), true, true
+	}
+	if sym, ok := d.symbolAt(pos); ok {
+		return d.occurrences(sym.Value, true), false, true
+	}
+	return nil, false, false
+}
+
+// prepareRename returns the range of the name at pos, if it can be renamed.
+func (d *document) prepareRename(pos lspPosition) *lspRange {
+	syms, _, ok := d.renameTarget(pos)
+	if !ok || len(syms) == 0 {
+		return nil
+	}
+	// Return the occurrence under the cursor.
+	for _, sym := range syms {
+		r := d.rangeOf(sym.Pos)
+		if r.Start.Line == pos.Line && r.Start.Character <= pos.Character && pos.Character <= r.End.Character {
+			return &r
+		}
+	}
+	return nil
+}
+
+// rename returns the edits that rename the variable or
+// constraint name at pos to newName.
+func (d *document) rename(pos lspPosition, newName string) (*lspWorkspaceEdit, error) {
+	syms, row, ok := d.renameTarget(pos)
+	if !ok || len(syms) == 0 {
+		return nil, fmt.Errorf("no variable or constraint name at the cursor")
+	}
+	oldName := syms[0].Value
+	if newName == oldName {
+		return &lspWorkspaceEdit{Changes: map[string][]lspTextEdit{}}, nil
+	}
+	format := formatOf(d.name)
+	check := lp.CheckVarName
+	if row {
+		check = lp.CheckRowName
+	}
+	if err := check(format, newName); err != nil {
+		return nil, err
+	}
+	if format == lp.FormatMPS && len(newName) != len(oldName) {
+		// Other lengths would move the fields that follow.
+		return nil, fmt.Errorf("names in fixed-format MPS files can only be renamed to names of the same length")
+	}
+	taken := d.model.RowNames.HasSym(lp.Symbol{Value: newName})
+	if !row {
+		taken = false
+		for _, sec := range append(useSections(d.model), declSections(d.model)...) {
+			taken = taken || sec.HasSym(lp.Symbol{Value: newName})
+		}
+	}
+	if taken {
+		return nil, fmt.Errorf("%s is already used", newName)
+	}
+	var edits []lspTextEdit
+	for _, sym := range syms {
+		edits = append(edits, lspTextEdit{Range: d.rangeOf(sym.Pos), NewText: newName})
+	}
+	return &lspWorkspaceEdit{Changes: map[string][]lspTextEdit{d.uri: edits}}, nil
+}
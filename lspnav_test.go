@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestRenameRowToKeyword(t *testing.T) {
+	d := newDocument("file:///tmp/model.lp", "Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n")
+	at := lspPosition{Line: 3, Character: 2}
+	for _, name := range []string{"Bounds", "st", "free"} {
+		if _, err := d.rename(at, name); err == nil {
+			t.Errorf("renaming c1 to %s: got no error, want one", name)
+		}
+	}
+	edit, err := d.rename(at, "demand")
+	if err != nil {
+		t.Fatalf("renaming c1 to demand: %v", err)
+	}
+	if n := len(edit.Changes[d.uri]); n != 1 {
+		t.Errorf("renaming c1 to demand: got %d edits, want 1", n)
+	}
+}
+
+func TestRenameVarToKeyword(t *testing.T) {
+	d := newDocument("file:///tmp/model.lp", "Minimize\n obj: x\nSubject To\n c1: x >= 1\nEnd\n")
+	at := lspPosition{Line: 3, Character: 5}
+	for _, name := range []string{"end", "st", "free", "inf", "e1", "E"} {
+		if _, err := d.rename(at, name); err == nil {
+			t.Errorf("renaming x to %s: got no error, want one", name)
+		}
+	}
+	edit, err := d.rename(at, "e1x")
+	if err != nil {
+		t.Fatalf("renaming x to e1x: %v", err)
+	}
+	if n := len(edit.Changes[d.uri]); n != 2 {
+		t.Errorf("renaming x to e1x: got %d edits, want 2", n)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// Kinds of document symbols.
+const (
+	lspSymbolNamespace = 3
+	lspSymbolFunction  = 12
+	lspSymbolVariable  = 13
+)
+
+type lspDocumentSymbol struct {
+	Name           string              `json:"name"`
+	Detail         string              `json:"detail,omitempty"`
+	Kind           int                 `json:"kind"`
+	Range          lspRange            `json:"range"`
+	SelectionRange lspRange            `json:"selectionRange"`
+	Children       []lspDocumentSymbol `json:"children,omitempty"`
+}
+
+// outline returns the sections of d with the objectives, named constraints,
+// and declared variables in each.
+// Files without sections, such as lp_solve models, get a flat list.
+func (d *document) outline() []lspDocumentSymbol {
+	syms := []lspDocumentSymbol{}
+	if d.model == nil {
+		return syms
+	}
+	m := d.model
+
+	var items []lspDocumentSymbol
+	item := func(name, detail string, kind int, pos lp.Pos) {
+		if name == "" || pos.Line == 0 || pos.File != d.name {
+			return
+		}
+		r := d.rangeOf(pos)
+		items = append(items, lspDocumentSymbol{
+			Name:           name,
+			Detail:         detail,
+			Kind:           kind,
+			Range:          r,
+			SelectionRange: r,
+		})
+	}
+	objs := m.MultiObj
+	if len(objs) == 0 && m.Obj != nil {
+		objs = []*lp.Objective{m.Obj}
+	}
+	for _, o := range objs {
+		item(o.Name, o.Sense.String(), lspSymbolFunction, o.Pos)
+	}
+	for _, c := range m.Rows {
+		item(c.Name, "constraint", lspSymbolFunction, c.Pos)
+	}
+	for _, g := range m.GenCons {
+		item(g.Name, g.Func, lspSymbolFunction, g.Pos)
+	}
+	for _, decl := range []struct {
+		sec  *lp.Section
+		kind string
+	}{
+		{&m.GeneralVars, "general"},
+		{&m.BinaryVars, "binary"},
+		{&m.SemiContVars, "semi-continuous"},
+		{&m.SemiIntVars, "semi-integer"},
+		{&m.CustomContVars, "continuous"},
+	} {
+		for _, v := range decl.sec.Syms() {
+			item(v.Value, decl.kind, lspSymbolVariable, v.Pos)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Range.Start, items[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+
+	if len(m.Headers) == 0 {
+		return append(syms, items...)
+	}
+	for i, h := range m.Headers {
+		end := len(d.lines) - 1
+		if i+1 < len(m.Headers) {
+			end = int(m.Headers[i+1].Pos.Line) - 2
+		}
+		sel := d.rangeOf(h.Pos)
+		sec := lspDocumentSymbol{
+			Name:           h.Value,
+			Kind:           lspSymbolNamespace,
+			Range:          lspRange{Start: lspPosition{sel.Start.Line, 0}, End: lspPosition{end, utf16Len(d.line(end), len(d.line(end)))}},
+			SelectionRange: sel,
+		}
+		for len(items) > 0 && items[0].Range.Start.Line <= end {
+			sec.Children = append(sec.Children, items[0])
+			items = items[1:]
+		}
+		syms = append(syms, sec)
+	}
+	return syms
+}
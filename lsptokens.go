@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// Semantic token types and modifiers, in the order of the legend.
+var (
+	lspTokenTypes     = []string{"keyword", "function", "variable", "number", "operator", "comment"}
+	lspTokenModifiers = []string{"declaration"}
+)
+
+const (
+	lspTokenKeyword = iota
+	lspTokenFunction
+	lspTokenVariable
+	lspTokenNumber
+	lspTokenOperator
+	lspTokenComment
+)
+
+const lspModDeclaration = 1 << 0
+
+type lspSemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+type semToken struct {
+	pos       lp.Pos
+	typ, mods int
+}
+
+// semanticTokens classifies the text of d for highlighting.
+// LP files are split into tokens as the parser sees them;
+// names are classified by what the model says they are.
+// For other formats, only the section headers and names are classified.
+func (d *document) semanticTokens() *lspSemanticTokens {
+	toks := &lspSemanticTokens{Data: []int{}}
+	if d.model == nil {
+		return toks
+	}
+	m := d.model
+
+	type key struct{ line, col int32 }
+	names := make(map[key]semToken)
+	name := func(sym lp.Symbol, typ, mods int) {
+		k := key{sym.Pos.Line, sym.Pos.Col}
+		if sym.Pos.Col == 0 || sym.Pos.File != d.name {
+			return
+		}
+		if _, ok := names[k]; !ok {
+			names[k] = semToken{sym.Pos, typ, mods}
+		}
+	}
+	for _, sym := range m.RowNames.Syms() {
+		name(sym, lspTokenFunction, 0)
+	}
+	for _, sec := range declSections(m) {
+		for _, sym := range sec.Syms() {
+			name(sym, lspTokenVariable, lspModDeclaration)
+		}
+	}
+	for _, sec := range useSections(m) {
+		for _, sym := range sec.Syms() {
+			name(sym, lspTokenVariable, 0)
+		}
+	}
+
+	var all []semToken
+	switch f := formatOf(d.name); f {
+	case lp.FormatLP, lp.FormatGLPK:
+		for _, t := range lp.Tokens(d.lines) {
+			var typ int
+			switch t.Kind {
+			case lp.TokenKeyword:
+				typ = lspTokenKeyword
+			case lp.TokenName:
+				// Names the model doesn't record, such as SOS set names,
+				// are left to the editor.
+				if n, ok := names[key{t.Pos.Line, t.Pos.Col}]; ok {
+					all = append(all, n)
+				}
+				continue
+			case lp.TokenNumber:
+				typ = lspTokenNumber
+			case lp.TokenOperator:
+				typ = lspTokenOperator
+			case lp.TokenComment:
+				typ = lspTokenComment
+			}
+			all = append(all, semToken{t.Pos, typ, 0})
+		}
+	default:
+		for _, h := range m.Headers {
+			all = append(all, semToken{h.Pos, lspTokenKeyword, 0})
+		}
+		for _, n := range names {
+			all = append(all, n)
+		}
+		sort.Slice(all, func(i, j int) bool {
+			a, b := all[i].pos, all[j].pos
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Col < b.Col
+		})
+	}
+
+	// Positions are relative to the previous token.
+	prevLine, prevChar := 0, 0
+	for _, t := range all {
+		r := d.rangeOf(t.pos)
+		if r.End.Line != r.Start.Line || r.End.Character <= r.Start.Character {
+			continue
+		}
+		if r.Start.Line != prevLine {
+			prevChar = 0
+		}
+		toks.Data = append(toks.Data,
+			r.Start.Line-prevLine, r.Start.Character-prevChar,
+			r.End.Character-r.Start.Character, t.typ, t.mods)
+		prevLine, prevChar = r.Start.Line, r.Start.Character
+	}
+	return toks
+}
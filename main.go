@@ -1,22 +1,82 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strconv"
+	"runtime"
 	"strings"
-	"unicode"
+	"time"
+
+	"github.com/uluyol/lpvet/lp"
 )
 
 var (
 	cmdIssueWarnings = flag.Bool("warn", false, "issue warnings in addition to errors")
+	cmdInput         = flag.String("input", "auto", "input `format`: auto, lp, mps, freemps, or proto")
+	cmdDialect       = flag.String("dialect", "cplex", "`dialect` of LP files: cplex, glpk, or lpsolve")
+	cmdStrictDecls   = flag.Bool("strict-decls", false, "require declarations for continuous variables")
+	cmdFormat        = flag.String("format", "text", "output `format`: text, json, sarif, checkstyle, junit, github, or html")
+	cmdColor         = flag.String("color", "auto", "color messages: `when` is auto, always, or never")
+	cmdBaseline      = flag.String("baseline", "", "only report problems not recorded in baseline `file`")
+	cmdWriteBaseline = flag.String("write-baseline", "", "record all problems in baseline `file` instead of reporting them")
+	cmdEnable        = flag.String("enable", "", "comma-separated `checks` to run even if disabled, or all")
+	cmdDisable       = flag.String("disable", "", "comma-separated `checks` not to run, or all")
+	cmdSeverity      = flag.String("severity", "", "comma-separated `check=severity` pairs overriding severities")
+	cmdWerror        = flag.Bool("Werror", false, "report all warnings as errors")
+	cmdDiffBase      = flag.String("diff-base", "", "only report problems on lines changed since git `revision`")
+	cmdDiff          = flag.String("diff", "", "only report problems on lines added by the unified diff in `file` (- for stdin)")
+	cmdConfig        = flag.String("config", "", "read settings from `file` instead of the nearest "+configName)
+	cmdShowSource    = flag.Bool("show-source", false, "print the offending source line under each message")
+	cmdSolver        = flag.String("solver", "", "check against the limits of `solver`: cplex, glpk, gurobi, highs, lpsolve, or scip")
+	cmdRecursive     = flag.Bool("r", false, "vet the models in directories and their subdirectories")
+	cmdFilesFrom     = flag.String("files-from", "", "also vet the models listed in `file`, one per line (- for stdin)")
+	cmdLSP           = flag.Bool("lsp", false, "run a language server on stdin and stdout")
+	cmdCache         = flag.Bool("cache", true, "reuse the results for models that have not changed")
+	cmdJobs          = flag.Int("j", runtime.NumCPU(), "vet up to `n` files at once")
+	cmdWatch         = flag.Bool("watch", false, "keep running and vet models again when they change")
+	cmdFix           = flag.Bool("fix", false, "rewrite models to fix mechanical problems, such as invalid names and a missing END")
+	cmdFixDryRun     = flag.Bool("fix-dry-run", false, "print the changes -fix would make as a diff instead of making them")
+	cmdMerge         = flag.Bool("merge", false, "vet all the models as one model split across files")
+	cmdProbeSolver   = flag.String("probe-solver", "", "also have `solver` (cplex, gurobi, or highs) read each model and report its errors")
+	cmdStdinName     = flag.String("stdin-name", "<stdin>", "`name` of the model read from stdin (-), which also selects its format")
+)
+
+var (
+	profile *lp.Profile
+	cfg     = new(config)
+
+	disabled   map[string]bool        // IDs of checks not to report
+	severities map[string]lp.Severity // by check ID
+	color      bool
+
+	changes changedLines            // if set, only these lines are reported
+	known   map[baselineFinding]int // problems in the baseline
+	cache   *resultCache            // nil if disabled
 )
 
 func usage() {
-	fmt.Fprintln(os.Stderr, "usage: lpvet f.lp [f.lp...]")
+	fmt.Fprintln(os.Stderr, "usage: lpvet [flags] f.lp|f.mps|dir/...|glob|-|@argsfile [...]")
+	fmt.Fprintln(os.Stderr, "       lpvet -lsp")
+	fmt.Fprintln(os.Stderr, "       lpvet [flags] serve [-addr host:port] [-max-size n]")
+	fmt.Fprintln(os.Stderr, "       lpvet explain [check]")
+	fmt.Fprintln(os.Stderr, "       lpvet fmt [-w] [-l] [-r] f.lp [...]")
+	fmt.Fprintln(os.Stderr, "       lpvet convert -to lp|mps|freemps [-o out] in")
+	fmt.Fprintln(os.Stderr, "       lpvet normalize [-o out] in")
+	fmt.Fprintln(os.Stderr, "       lpvet anonymize [-map map.json] [-o out] in")
+	fmt.Fprintln(os.Stderr, "       lpvet minimize [-match re] [-o out] in -- cmd [arg ...]")
+	fmt.Fprintln(os.Stderr, "       lpvet diff [-iso] [-format=text|json] old new")
+	fmt.Fprintln(os.Stderr, "       lpvet stats [-coef] [-format=text|json] f.lp [...]")
+	fmt.Fprintln(os.Stderr, "       lpvet spy [-size n] -o out.png|out.svg in")
+	fmt.Fprintln(os.Stderr, "       lpvet graph [-var name [-depth n]] [-o out.dot] in")
+	fmt.Fprintln(os.Stderr, "       lpvet export -format json|mm|osil|proto [-o path] in")
+	fmt.Fprintln(os.Stderr, "       lpvet query [-format=text|json] in query")
+	fmt.Fprintln(os.Stderr, "       lpvet browse in")
+	fmt.Fprintln(os.Stderr, "       lpvet gen [-vars n] [-cons m] [-density d] [-seed s] [-int f] [-binary f] [-eq f] [-o out]")
+	fmt.Fprintln(os.Stderr, "       lpvet check-sol [-feastol t] [-inttol t] [-slack n] [-format=text|json] model sol.sol")
 	flag.PrintDefaults()
 	os.Exit(2)
 }
@@ -26,269 +86,621 @@ func main() {
 	log.SetFlags(0)
 
 	flag.Usage = usage
-	flag.Parse()
-	if flag.NArg() < 1 {
+	args, err := expandResponseFiles(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	flag.CommandLine.Parse(args)
+	if flag.NArg() < 1 && *cmdFilesFrom == "" && !*cmdLSP {
 		usage()
 	}
 
-	issuedMesg := false
-	for _, p := range flag.Args() {
-		err, issued := vet(p, *cmdIssueWarnings)
-		issuedMesg = issuedMesg || issued
+	if flag.Arg(0) == "explain" {
+		explain(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "fmt" {
+		fmtCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "convert" {
+		convertCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "normalize" {
+		normalizeCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "anonymize" {
+		anonymizeCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "minimize" {
+		minimizeCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "diff" {
+		diffCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "stats" {
+		statsCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "spy" {
+		spyCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "graph" {
+		graphCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "browse" {
+		browseCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "query" {
+		queryCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "gen" {
+		genCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "export" {
+		exportCmd(flag.Args()[1:])
+		return
+	}
+
+	cfgPath := *cmdConfig
+	if cfgPath == "" {
+		if wd, err := os.Getwd(); err == nil {
+			cfgPath = findConfig(wd)
+		}
+	}
+	if cfgPath != "" {
+		var err error
+		if cfg, err = loadConfig(cfgPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Flags refine the configuration, and enabling wins over disabling,
+	// so "-disable=all -enable=LP001" runs just LP001.
+	disabled = make(map[string]bool)
+	for _, step := range []struct {
+		names  []string
+		enable bool
+	}{
+		{cfg.disable, false},
+		{cfg.enable, true},
+		{splitList(*cmdDisable), false},
+		{splitList(*cmdEnable), true},
+	} {
+		set, err := checkSet(step.names)
 		if err != nil {
-			log.Print(err)
+			log.Fatal(err)
+		}
+		for id := range set {
+			disabled[id] = !step.enable
 		}
 	}
 
-	if issuedMesg {
-		os.Exit(1)
+	severities = cfg.severity
+	if severities == nil {
+		severities = make(map[string]lp.Severity)
+	}
+	for _, kv := range splitList(*cmdSeverity) {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			log.Fatalf("-severity: %q is not check=severity", kv)
+		}
+		c, err := lp.LookupCheck(kv[:i])
+		if err != nil {
+			log.Fatalf("-severity: %v", err)
+		}
+		sev, err := parseSeverity(kv[i+1:])
+		if err != nil {
+			log.Fatalf("-severity: %v", err)
+		}
+		severities[c.ID] = sev
 	}
-}
 
-type LP struct {
-	Objective    Section
-	Constraints  Section
-	Bounds       Section
-	GeneralVars  Section
-	BinaryVars   Section
-	SemiContVars Section
-	CustomContVars Section
-}
+	stdinUses := 0
+	for _, arg := range append([]string{*cmdDiff, *cmdFilesFrom}, flag.Args()...) {
+		if arg == "-" {
+			stdinUses++
+		}
+	}
+	if stdinUses > 1 {
+		log.Fatal("stdin can only be read once: use - for one of -diff, -files-from, or a model")
+	}
 
-type Section struct {
-	syms   []Symbol
-	symSet map[string]bool
-}
+	if *cmdInput != "auto" {
+		if _, err := lp.ParseFormatName(*cmdInput); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-func (s *Section) AddSym(sym Symbol) {
-	if s.symSet == nil {
-		s.symSet = make(map[string]bool)
+	if *cmdSolver != "" {
+		var err error
+		if profile, err = lp.LookupProfile(*cmdSolver); err != nil {
+			log.Fatal(err)
+		}
+		dialectSet := false
+		flag.Visit(func(f *flag.Flag) {
+			dialectSet = dialectSet || f.Name == "dialect"
+		})
+		if !dialectSet {
+			*cmdDialect = profile.Dialect
+		}
 	}
-	s.syms = append(s.syms, sym)
-	s.symSet[sym.Value] = true
-}
 
-func (s *Section) Syms() []Symbol { return s.syms }
-func (s *Section) HasSym(sym Symbol) bool {
-	if s.symSet == nil {
-		return false
+	switch *cmdDialect {
+	case "cplex", "glpk", "lpsolve":
+	default:
+		log.Fatalf("unknown dialect %q", *cmdDialect)
 	}
-	return s.symSet[sym.Value]
-}
 
-type Symbol struct {
-	Value string
-	Pos   Pos
-}
+	if *cmdProbeSolver != "" {
+		if err := checkProbeSolver(*cmdProbeSolver); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-type Pos struct {
-	File string
-	Line int32
-}
+	if flag.Arg(0) == "check-sol" {
+		checkSolCmd(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "serve" {
+		serveCmd(flag.Args()[1:])
+		return
+	}
+	if *cmdLSP {
+		if err := serveLSP(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-func (p Pos) String() string {
-	return p.File + ":" + strconv.Itoa(int(p.Line))
-}
+	color = useColor(*cmdColor)
+	if _, err := newReporter(*cmdFormat, color); err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case *cmdDiffBase != "" && *cmdDiff != "":
+		log.Fatal("-diff-base and -diff are mutually exclusive")
+	case *cmdDiffBase != "":
+		if changes, err = gitChanges(*cmdDiffBase); err != nil {
+			log.Fatal(err)
+		}
+	case *cmdDiff == "-":
+		if changes, err = parseUnifiedDiff(os.Stdin); err != nil {
+			log.Fatal(err)
+		}
+	case *cmdDiff != "":
+		f, err := os.Open(*cmdDiff)
+		if err != nil {
+			log.Fatal(err)
+		}
+		changes, err = parseUnifiedDiff(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-const (
-	MaxLineLen           = 510
-	MaxVarLen            = 255
-	MaxConstraintNameLen = MaxVarLen
-)
+	if *cmdBaseline != "" {
+		if known, err = readBaseline(*cmdBaseline); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-func validVarName(n string) bool {
-	// implement
-	for _, c := range n {
-		switch {
-		case 'a' <= c && c <= 'z':
-		case 'A' <= c && c <= 'Z':
-		case '0' <= c && c <= '9':
-		default:
-			switch c {
-			case '!', '"', '#', '$', '%', '&', '(', ')', ',', '.', ';', '?', '@', '_', '‘', '\'', '{', '}', '~':
-			default:
-				return false
+	args = flag.Args()
+	if *cmdFilesFrom != "" {
+		list, err := readList(*cmdFilesFrom)
+		if err != nil {
+			log.Fatal(err)
+		}
+		args = append(args, list...)
+	}
+	if *cmdCache {
+		cache = openCache()
+	}
+	if *cmdMerge && *cmdProbeSolver != "" {
+		// Solvers cannot read the parts of a model on their own.
+		log.Fatal("-merge and -probe-solver are mutually exclusive")
+	}
+	if *cmdWatch {
+		if *cmdWriteBaseline != "" {
+			log.Fatal("-watch and -write-baseline are mutually exclusive")
+		}
+		if *cmdFix || *cmdFixDryRun {
+			log.Fatal("-watch and -fix are mutually exclusive")
+		}
+		watch(args, time.Second)
+	}
+	files, expanded, err := expandArgs(args, *cmdRecursive)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *cmdFix || *cmdFixDryRun {
+		for _, p := range files {
+			if p == "-" {
+				log.Fatal("-fix cannot rewrite stdin")
 			}
+			if err := fixFile(p, *cmdFixDryRun); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *cmdFixDryRun {
+			return
 		}
 	}
-	return true
+	if vetFiles(files, expanded) {
+		os.Exit(1)
+	}
 }
 
-func loadLP(p string) (*LP, error) {
-	f, err := os.Open(p)
+// vetFiles vets files and reports their problems,
+// or records them in the baseline for -write-baseline.
+// It reports whether there were any problems to report.
+// If expanded, a summary for each directory follows the problems.
+func vetFiles(files []string, expanded bool) bool {
+	rep, err := newReporter(*cmdFormat, color)
 	if err != nil {
-		return nil, err
+		log.Fatal(err)
 	}
-	defer f.Close()
-
-	var (
-		lp     LP
-		curSec *Section
-	)
-	pos := Pos{File: p}
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		pos.Line++
-		if len(s.Text()) > MaxLineLen {
-			return nil, fmt.Errorf("%s: line too long (%d > %d)", pos, len(s.Text()), MaxLineLen)
-		}
-		t := strings.TrimSpace(s.Text())
-		fields := strings.Fields(strings.TrimPrefix(t, "\\lpvet:"))
-		var h string
-		if len(fields) >= 1 {
-			h = strings.ToUpper(fields[0])
-		}
-		switch {
-		case t == "":
+	var all []lp.Diagnostic
+	summary := make(dirSummaries)
+	issuedMesg := false
+	// With -merge, the first file stands for all of them.
+	vetted, results := files, []chan vetResult(nil)
+	if *cmdMerge {
+		var kept []string
+		for _, p := range files {
+			if p == "-" || !cfg.ignored(p) {
+				kept = append(kept, p)
+			}
+		}
+		files = kept
+	}
+	if *cmdMerge && len(files) > 0 {
+		res := make(chan vetResult, 1)
+		diags, err := vetMerged(files, *cmdIssueWarnings)
+		res <- vetResult{diags, err}
+		vetted, results = files[:1], []chan vetResult{res}
+	} else {
+		results = vetConcurrently(files, *cmdJobs)
+	}
+	for i, p := range vetted {
+		res, ok := <-results[i]
+		if !ok {
+			continue // ignored
+		}
+		diags, err := res.diags, res.err
+		if err != nil {
+			log.Print(err)
 			continue
-		case strings.HasPrefix(t, "\\") && !strings.HasPrefix(t, "\\lpvet:"):
+		}
+		if *cmdWriteBaseline != "" {
+			all = append(all, diags...)
 			continue
-		default:
-			switch h {
-			case "MIN", "MAX", "MINIMIZE", "MAXIMIZE", "MINIMUM", "MAXIMUM":
-				curSec = &lp.Objective
-				continue
-			case "SUBJECT", "S.T", "SUCH", "ST", "ST.":
-				curSec = &lp.Constraints
-				continue
-			case "BOUNDS", "BOUND":
-				curSec = &lp.Bounds
-				continue
-			case "GENERAL", "GEN", "GENERALS":
-				curSec = &lp.GeneralVars
-				continue
-			case "BINARY", "BIN", "BINARIES":
-				curSec = &lp.BinaryVars
-				continue
-			case "SEMI-CONTINUOUS", "SEMI", "SEMIS":
-				curSec = &lp.SemiContVars
-				continue
-			case "CONTINUOUS":
-				curSec = &lp.CustomContVars
-				continue
-			case "END":
-				curSec = nil
-				continue
-			}
 		}
-		ci := strings.IndexByte(t, ':')
-		if ci < 0 {
-			ci = 0
+		if changes != nil {
+			diags = changes.filter(diags)
 		}
-		t = t[ci:]
-		fields = strings.FieldsFunc(t, func(r rune) bool {
-			if unicode.IsSpace(r) {
-				return true
-			}
-			switch r {
-			case '+', '-', '=', '>', '<':
-				return true
-			}
-			return false
-		})
-		if curSec == nil {
-			return nil, fmt.Errorf("%s: not in a section", pos)
-		}
-		// Remaining fields are either symbols or numerals.
-		// Assume if starts with letter or _, symbol.
-		for _, f := range fields {
-			// Not unicode safe. CPLEX isn't either.
-			if unicode.IsLetter(rune(f[0])) || f[0] == '_' {
-				if f == "inf" && curSec == &lp.Bounds {
-					continue
-				}
-				if len(f) > MaxVarLen {
-					return nil, fmt.Errorf("%s: variable too long: %q (%d > %d)", pos, f, len(f), MaxVarLen)
-				}
-				if !validVarName(f) {
-					return nil, fmt.Errorf("%s: invalid variable name: %q", pos, f)
-				}
-				curSec.AddSym(Symbol{
-					Value: f,
-					Pos:   pos,
-				})
-			}
+		if known != nil {
+			diags = filterBaseline(diags, known)
+		}
+		issuedMesg = issuedMesg || len(diags) > 0
+		names := []string{p}
+		if *cmdMerge {
+			names = files
+		}
+		for _, g := range groupByFile(names, diags) {
+			rep.report(g.file, g.diags)
+			summary.add(g.file, g.diags)
 		}
 	}
-	return &lp, s.Err()
+	if *cmdWriteBaseline != "" {
+		if err := writeBaseline(*cmdWriteBaseline, all); err != nil {
+			log.Fatal(err)
+		}
+		return false
+	}
+	if err := rep.close(); err != nil {
+		log.Fatal(err)
+	}
+	// Other formats are read by programs, which can count for themselves.
+	if expanded && *cmdFormat == "text" {
+		summary.print()
+	}
+	return issuedMesg
 }
 
-func vet(p string, issueWarnings bool) (error, bool) {
-	lp, err := loadLP(p)
-	if err != nil {
-		return err, false
+// A fileDiags holds the problems in one file.
+type fileDiags struct {
+	file  string
+	diags []lp.Diagnostic
+}
+
+// groupByFile splits diags by the files they are in, which are those of
+// the models named by paths, each of which has a group even if it has
+// no problems, followed by any files these include.
+func groupByFile(paths []string, diags []lp.Diagnostic) []fileDiags {
+	var groups []fileDiags
+	index := make(map[string]int)
+	group := func(file string) int {
+		i, ok := index[file]
+		if !ok {
+			i = len(groups)
+			index[file] = i
+			groups = append(groups, fileDiags{file: file})
+		}
+		return i
+	}
+	for _, p := range paths {
+		if p == "-" {
+			p = *cmdStdinName
+		}
+		group(p)
 	}
+	for _, d := range diags {
+		i := group(d.Pos.File)
+		groups[i].diags = append(groups[i].diags, d)
+	}
+	return groups
+}
 
-	issued := false
+type vetResult struct {
+	diags []lp.Diagnostic
+	err   error
+}
 
-	issuedFor := make(map[string]bool)
+// vetConcurrently vets files using up to jobs goroutines.
+// The result for files[i] is sent on the i'th channel,
+// which is closed without a result if the file is ignored.
+// Results are collected in order so output stays grouped by file.
+func vetConcurrently(files []string, jobs int) []chan vetResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]chan vetResult, len(files))
+	for i := range results {
+		results[i] = make(chan vetResult, 1)
+	}
+	next := make(chan int)
+	go func() {
+		for i := range files {
+			next <- i
+		}
+		close(next)
+	}()
+	for w := 0; w < jobs && w < len(files); w++ {
+		go func() {
+			for i := range next {
+				p := files[i]
+				if p == "-" || !cfg.ignored(p) {
+					diags, err := vet(p, *cmdIssueWarnings)
+					results[i] <- vetResult{diags, err}
+				}
+				close(results[i])
+			}
+		}()
+	}
+	return results
+}
 
-	issue := func(format string, s Symbol) {
-		if !issuedFor[s.Value] {
-			log.Printf(format, s.Pos, s.Value)
-			issued = true
-			issuedFor[s.Value] = true
+// splitList splits a comma-separated flag value.
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
 		}
 	}
+	return list
+}
 
-	haveDecl := func(sym Symbol) bool {
-		if lp.GeneralVars.HasSym(sym) {
-			return true
+// useColor reports whether messages should be colored.
+// With "auto", they are if stderr, where they go, is a terminal
+// and NO_COLOR is not set.
+func useColor(when string) bool {
+	switch when {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false
 		}
-		if lp.BinaryVars.HasSym(sym) {
-			return true
+		fi, err := os.Stderr.Stat()
+		return err == nil && fi.Mode()&os.ModeCharDevice != 0
+	}
+	log.Fatalf("unknown -color setting %q", when)
+	return false
+}
+
+// explain prints the documentation of the named checks,
+// or lists all checks if there are none.
+func explain(names []string) {
+	if len(names) == 0 {
+		for _, c := range lp.Checks() {
+			fmt.Println(c)
 		}
-		if lp.SemiContVars.HasSym(sym) {
-			return true
+		return
+	}
+	for i, name := range names {
+		c, err := lp.LookupCheck(name)
+		if err != nil {
+			log.Fatal(err)
 		}
-		if lp.CustomContVars.HasSym(sym) {
-			return true
+		if i > 0 {
+			fmt.Println()
 		}
-		return false
+		fmt.Printf("%s\n\n%s\n\n", c, c.Doc)
+		fmt.Printf("To suppress it for one line, add the comment \"\\ lpvet:ignore %s\"\nto the end of the line or on the line before.\n", c.ID)
 	}
+}
 
-	for _, sym := range lp.Objective.Syms() {
-		if !haveDecl(sym) {
-			issue("%s: error: no var declaration for %s", sym)
+// vet returns the problems in the model at path p,
+// or on stdin if p is "-".
+// Syntax errors are returned as diagnostics.
+func vet(p string, issueWarnings bool) ([]lp.Diagnostic, error) {
+	name := p
+	var data []byte
+	var err error
+	if p == "-" {
+		name = *cmdStdinName
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
 		}
+		// -show-source can't read stdin again.
+		cacheSource(name, data)
+	} else if data, err = os.ReadFile(p); err != nil {
+		return nil, err
 	}
-
-	for _, sym := range lp.Constraints.Syms() {
-		if !haveDecl(sym) {
-			issue("%s: error: no var declaration for %s", sym)
+	format := formatOf(name)
+	var included []modelFile
+	var includeErrs lp.ErrorList
+	if canInclude(format) {
+		included, includeErrs = readIncludes(name, p, data)
+	}
+	// Results are cached before applyPolicy,
+	// so that changing the policy doesn't invalidate them.
+	var key string
+	diags, ok := []lp.Diagnostic(nil), false
+	if cache != nil {
+		keyData := data
+		if len(included) > 0 {
+			// The included files are part of the model.
+			var b bytes.Buffer
+			b.Write(data)
+			for _, f := range included {
+				fmt.Fprintf(&b, "\n%s\n", f.name)
+				b.Write(f.data)
+			}
+			keyData = b.Bytes()
 		}
+		key = cache.key(name, format, keyData)
+		diags, ok = cache.get(key)
 	}
-
-	for _, sym := range lp.Bounds.Syms() {
-		if !haveDecl(sym) {
-			issue("%s: error: no var declaration for %s", sym)
+	if !ok {
+		if len(included) == 0 && len(includeErrs) == 0 {
+			_, diags, err = analyze(name, bytes.NewReader(data), format)
+		} else {
+			files := append([]modelFile{{name, data}}, included...)
+			_, diags, err = analyzeModel(parseFiles(files, includeErrs))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cache != nil {
+			cache.put(key, diags)
 		}
 	}
-
-	if issueWarnings {
-		for _, sym := range lp.GeneralVars.Syms() {
-			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) {
-				issue("%s: warning: no use of general var %s", sym)
-			}
+	// The solver is run every time, since it may have changed.
+	if *cmdProbeSolver != "" {
+		solverDiags, err := probe(name, p, data)
+		if err != nil {
+			return nil, err
 		}
+		diags = append(diags, solverDiags...)
+	}
+	return applyPolicy(diags, issueWarnings), nil
+}
 
-		for _, sym := range lp.BinaryVars.Syms() {
-			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) {
-				issue("%s: warning: no use of binary var %s", sym)
-			}
+// formatOf returns the format of the model called name
+// according to its extension and the -input and -dialect flags.
+func formatOf(name string) lp.Format {
+	format := lp.FormatFromPath(name)
+	if *cmdInput != "auto" {
+		format, _ = lp.ParseFormatName(*cmdInput)
+	}
+	if format == lp.FormatLP {
+		switch *cmdDialect {
+		case "glpk":
+			format = lp.FormatGLPK
+		case "lpsolve":
+			format = lp.FormatLPSolve
 		}
+	}
+	return format
+}
+
+// analyze parses the model read from r and returns it,
+// possibly partial, along with all its problems, including warnings.
+func analyze(name string, r io.Reader, format lp.Format) (*lp.LP, []lp.Diagnostic, error) {
+	return analyzeModel(lp.ParseFormatLimits(name, r, format, limits()))
+}
+
+// limits returns the longest lines and names that models may have:
+// those in the configuration file, or else those of -solver,
+// or else CPLEX's.
+func limits() lp.Limits {
+	lim := lp.DefaultLimits
+	if profile != nil {
+		lim = lp.Limits{MaxLineLen: profile.MaxLineLen, MaxVarLen: profile.MaxVarLen}
+	}
+	if cfg.maxLineLen > 0 {
+		lim.MaxLineLen = cfg.maxLineLen
+	}
+	if cfg.maxVarLen > 0 {
+		lim.MaxVarLen = cfg.maxVarLen
+	}
+	lim.NameChars = cfg.nameChars
+	return lim
+}
 
-		for _, sym := range lp.SemiContVars.Syms() {
-			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) {
-				issue("%s: warning: no use of semi-continuous var %s", sym)
+// analyzeModel is like analyze for a model m
+// that was already parsed with error err.
+func analyzeModel(m *lp.LP, err error) (*lp.LP, []lp.Diagnostic, error) {
+	if errs, ok := err.(lp.ErrorList); ok {
+		// Report every syntax error, but don't vet a partial model.
+		var diags []lp.Diagnostic
+		for _, e := range errs {
+			if d := e.Diagnostic(); !m.Ignored(d) {
+				diags = append(diags, d)
 			}
 		}
+		return m, diags, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// Warnings are filtered by applyPolicy,
+	// since some may be configured to be errors.
+	return m, lp.Vet(m, lp.Options{
+		Warnings:     true,
+		Profile:      profile,
+		MaxVarLen:    cfg.maxVarLen,
+		TruncateLen:  cfg.truncateLen,
+		MaxCoefRange: cfg.maxCoefRange,
+		StrictDecls:  *cmdStrictDecls,
+		Disabled:     disabled,
+	}), nil
+}
 
-		for _, sym := range lp.CustomContVars.Syms() {
-			if !lp.Objective.HasSym(sym) && !lp.Constraints.HasSym(sym) {
-				issue("%s: warning: no use of continuous var %s", sym)
-			}
+// applyPolicy drops diagnostics of disabled checks, adjusts severities
+// as configured, drops warnings unless issueWarnings is set, and then
+// promotes the remaining warnings to errors for -Werror.
+func applyPolicy(diags []lp.Diagnostic, issueWarnings bool) []lp.Diagnostic {
+	var kept []lp.Diagnostic
+	for _, d := range diags {
+		if disabled[d.Check] {
+			continue
+		}
+		if sev, ok := severities[d.Check]; ok {
+			d.Severity = sev
+		}
+		if d.Severity == lp.Warning && !issueWarnings {
+			continue
+		}
+		if *cmdWerror {
+			d.Severity = lp.Error
 		}
+		kept = append(kept, d)
 	}
-	return nil, issued
+	return kept
 }
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// minimizeCmd implements "lpvet minimize", which removes parts of a model
+// for as long as a command still fails on it, to make small reproducers
+// for solver bug reports.
+func minimizeCmd(args []string) {
+	fs := flag.NewFlagSet("minimize", flag.ExitOnError)
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	match := fs.String("match", "", "only count failures whose output matches this `regexp`")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet minimize [-match re] [-o out] in|- -- cmd [arg ...]")
+		fmt.Fprintln(os.Stderr, "{} in the arguments is replaced by the model's path, which is otherwise appended.")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	var command []string
+	for i, a := range args {
+		if a == "--" {
+			args, command = args[:i], args[i+1:]
+			break
+		}
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 || len(command) == 0 {
+		fs.Usage()
+	}
+	var re *regexp.Regexp
+	if *match != "" {
+		var err error
+		if re, err = regexp.Compile(*match); err != nil {
+			log.Fatalf("-match: %v", err)
+		}
+	}
+	in := ins[0]
+	name := in
+	if in == "-" {
+		name = *cmdStdinName
+	}
+	m := mustReadModel(in)
+	format := formatOf(name)
+	write := func(sub *lp.LP) []byte {
+		var b bytes.Buffer
+		var err error
+		if format == lp.FormatMPS || format == lp.FormatFreeMPS {
+			err = lp.WriteMPS(&b, sub, "model", format)
+		} else {
+			err = lp.WriteLP(&b, sub)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return b.Bytes()
+	}
+
+	dir, err := os.MkdirTemp("", "lpvet-minimize")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ext := ".lp"
+	if format == lp.FormatMPS || format == lp.FormatFreeMPS {
+		ext = ".mps"
+	}
+	file := filepath.Join(dir, "model"+ext)
+	cmdArgs := command[1:]
+	if !strings.Contains(strings.Join(cmdArgs, " "), "{}") {
+		cmdArgs = append(cmdArgs, "{}")
+	}
+	runs := 0
+	// fails reports whether the command fails on sub.
+	fails := func(sub *lp.LP) bool {
+		if err := os.WriteFile(file, write(sub), 0666); err != nil {
+			log.Fatal(err)
+		}
+		var args []string
+		for _, a := range cmdArgs {
+			args = append(args, strings.ReplaceAll(a, "{}", file))
+		}
+		runs++
+		output, err := exec.Command(command[0], args...).CombinedOutput()
+		if _, ok := err.(*exec.ExitError); err != nil && !ok {
+			log.Fatal(err)
+		}
+		return err != nil && (re == nil || re.Match(output))
+	}
+
+	s := &shrinker{m: m, drop: make(map[string]map[int]bool), dropVar: make(map[string]bool), fails: fails}
+	if !fails(s.model()) {
+		log.Fatalf("%s: %s does not fail on the model", name, command[0])
+	}
+	parts := []struct{ kind, noun string }{
+		{lp.PartConstraint, "constraint"},
+		{lp.PartSOS, "SOS constraint"},
+		{lp.PartGeneral, "general constraint"},
+		{lp.PartPWL, "piecewise-linear objective"},
+	}
+	// Removing parts can make others removable, so repeat until nothing is.
+	for changed := true; changed; {
+		changed = false
+		for _, p := range parts {
+			var items []int
+			for i, n := 0, s.count(p.kind); i < n; i++ {
+				if !s.drop[p.kind][i] {
+					items = append(items, i)
+				}
+			}
+			if n := s.removeParts(p.kind, items); n > 0 {
+				log.Printf("removed %s", plural(n, p.noun))
+				changed = true
+			}
+		}
+		var names []string
+		for _, v := range lp.Variables(s.model()) {
+			names = append(names, v.Name)
+		}
+		if n := s.removeVars(names); n > 0 {
+			log.Printf("removed %s", plural(n, "variable"))
+			changed = true
+		}
+	}
+	res := s.model()
+	log.Printf("%s left after %s", plural(len(res.Rows), "constraint"), plural(runs, "run"))
+	writeOutput(*out, write(res))
+}
+
+// A shrinker removes parts of a model while the model still fails.
+type shrinker struct {
+	m       *lp.LP
+	drop    map[string]map[int]bool // indexes of removed parts, by kind
+	dropVar map[string]bool         // removed variables
+	fails   func(*lp.LP) bool
+}
+
+// model returns the model without the removed parts and variables.
+func (s *shrinker) model() *lp.LP {
+	return lp.Subset(s.m,
+		func(kind string, i int) bool { return !s.drop[kind][i] },
+		func(name string) bool { return !s.dropVar[name] })
+}
+
+// count returns the number of parts of the given kind in the original model.
+func (s *shrinker) count(kind string) int {
+	switch kind {
+	case lp.PartConstraint:
+		return len(s.m.Rows)
+	case lp.PartSOS:
+		return len(s.m.SOS)
+	case lp.PartGeneral:
+		return len(s.m.GenCons)
+	case lp.PartPWL:
+		return len(s.m.PWLObjs)
+	}
+	return 0
+}
+
+// removeParts removes as many of the parts of the given kind with the given indexes
+// as it can, and returns how many.
+func (s *shrinker) removeParts(kind string, items []int) int {
+	if s.drop[kind] == nil {
+		s.drop[kind] = make(map[int]bool)
+	}
+	return ddmin(len(items), func(chunk []int, remove bool) bool {
+		for _, j := range chunk {
+			s.drop[kind][items[j]] = remove
+		}
+		return !remove || s.fails(s.model())
+	})
+}
+
+// removeVars removes as many of the given variables as it can,
+// and returns how many.
+func (s *shrinker) removeVars(names []string) int {
+	return ddmin(len(names), func(chunk []int, remove bool) bool {
+		for _, j := range chunk {
+			s.dropVar[names[j]] = remove
+		}
+		return !remove || s.fails(s.model())
+	})
+}
+
+// ddmin removes chunks of n items, which are removed by try(chunk, true)
+// and restored by try(chunk, false), keeping a removal if try reports true.
+// The chunks halve in size until single items cannot be removed,
+// as in delta debugging.
+// It returns the number of items removed.
+func ddmin(n int, try func(chunk []int, remove bool) bool) int {
+	left := make([]int, n)
+	for i := range left {
+		left[i] = i
+	}
+	for size := (n + 1) / 2; size > 0 && len(left) > 0; {
+		removed := false
+		for start := 0; start < len(left); {
+			end := start + size
+			if end > len(left) {
+				end = len(left)
+			}
+			chunk := left[start:end]
+			if try(chunk, true) {
+				left = append(left[:start:start], left[end:]...)
+				removed = true
+				continue
+			}
+			try(chunk, false)
+			start = end
+		}
+		if !removed || size > len(left) {
+			size /= 2
+		}
+	}
+	return n - len(left)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type jsonChange struct {
+	Kind   string `json:"kind"`
+	What   string `json:"what"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+}
+
+// diffCmd implements "lpvet diff", which compares two models structurally.
+// Like diff, it exits with status 1 if the models differ.
+func diffCmd(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	iso := fs.Bool("iso", false, "check whether the models are identical up to a renaming of variables and constraints")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet diff [-iso] [-format=text|json] old new")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 2 {
+		fs.Usage()
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("unknown output format %q", *format)
+	}
+	a, b := mustReadModel(ins[0]), mustReadModel(ins[1])
+	if *iso {
+		isoDiff(a, b, *format == "json")
+		return
+	}
+	changes := lp.Compare(a, b)
+	if *format == "json" {
+		out := []jsonChange{}
+		for _, c := range changes {
+			out = append(out, jsonChange{
+				Kind:   c.Kind.String(),
+				What:   c.What,
+				Name:   c.Name,
+				Detail: c.Detail,
+				Old:    c.Old,
+				New:    c.New,
+				File:   c.Pos.File,
+				Line:   int(c.Pos.Line),
+			})
+		}
+		data, err := json.MarshalIndent(out, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+	} else {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+type jsonIso struct {
+	Isomorphic  bool              `json:"isomorphic"`
+	Difference  string            `json:"difference,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Constraints map[string]string `json:"constraints,omitempty"`
+}
+
+// isoDiff prints the renaming that makes a identical to b,
+// or a difference between them, in which case it exits with status 1.
+func isoDiff(a, b *lp.LP, asJSON bool) {
+	vars, rows, err := lp.Isomorphism(a, b)
+	if asJSON {
+		out := jsonIso{Isomorphic: err == nil, Variables: vars, Constraints: rows}
+		if err != nil {
+			out.Difference = err.Error()
+		}
+		data, err := json.MarshalIndent(out, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+	} else if err == nil {
+		for _, m := range []struct {
+			what  string
+			names map[string]string
+		}{{"variable", vars}, {"constraint", rows}} {
+			var keys []string
+			for k := range m.names {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("%s %s -> %s\n", m.what, k, m.names[k])
+			}
+		}
+	} else {
+		fmt.Printf("not isomorphic: %v\n", err)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// normalizeCmd implements "lpvet normalize", which writes models
+// in a canonical form so that differences between them are meaningful.
+func normalizeCmd(args []string) {
+	fs := flag.NewFlagSet("normalize", flag.ExitOnError)
+	out := fs.String("o", "", "write to this `file` instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet normalize [-o out] in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 {
+		fs.Usage()
+	}
+	m := mustReadModel(ins[0])
+	lp.Normalize(m)
+	var b bytes.Buffer
+	if err := lp.WriteLP(&b, m); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*out, b.Bytes())
+}
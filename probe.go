@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A probeSolver describes how to have a solver read a model without solving it,
+// for -probe-solver.
+type probeSolver struct {
+	cmd  string
+	args func(file string) []string
+	// errors returns the errors in the solver's output,
+	// with the lines they refer to, or 0.
+	errors func(out []string) []probeError
+}
+
+type probeError struct {
+	line int
+	msg  string
+}
+
+var probeSolvers = map[string]probeSolver{
+	"cplex": {
+		cmd:  "cplex",
+		args: func(file string) []string { return []string{"-c", "read " + file, "quit"} },
+		errors: func(out []string) []probeError {
+			var errs []probeError
+			for _, l := range out {
+				if m := cplexErrorRE.FindStringSubmatch(l); m != nil {
+					errs = append(errs, probeError{lineOf(m[1]), m[1]})
+				}
+			}
+			return errs
+		},
+	},
+	"gurobi": {
+		cmd:  "gurobi_cl",
+		args: func(file string) []string { return []string{"TimeLimit=0", "Presolve=0", file} },
+		errors: func(out []string) []probeError {
+			var errs []probeError
+			for i, l := range out {
+				if m := gurobiReadErrorRE.FindStringSubmatch(l); m != nil {
+					msg := l
+					if i+1 < len(out) && strings.TrimSpace(out[i+1]) != "" {
+						msg = strings.TrimSpace(out[i+1])
+					}
+					n, _ := strconv.Atoi(m[1])
+					errs = append(errs, probeError{n, msg})
+				} else if m := gurobiErrorRE.FindStringSubmatch(l); m != nil && len(errs) == 0 {
+					// This summarizes the errors above, if there are any.
+					errs = append(errs, probeError{0, m[1]})
+				}
+			}
+			return errs
+		},
+	},
+	"highs": {
+		cmd:  "highs",
+		args: func(file string) []string { return []string{"--presolve", "off", "--time_limit", "0", file} },
+		errors: func(out []string) []probeError {
+			var errs []probeError
+			for _, l := range out {
+				if msg := strings.TrimSpace(strings.TrimPrefix(l, "ERROR:")); msg != l {
+					errs = append(errs, probeError{lineOf(msg), msg})
+				}
+			}
+			return errs
+		},
+	},
+}
+
+var (
+	// CPLEX Error  1434: Line 12: Couldn't convert '1e' to a number.
+	cplexErrorRE = regexp.MustCompile(`CPLEX Error\s+\d+:\s*(.*)`)
+
+	// Error reading LP format file model.lp at line 5
+	// Unrecognized constraint RHS or sense
+	// Neighboring tokens: " c3: x - y <= 4 "
+	//
+	// Error 10012: Unable to read file
+	gurobiReadErrorRE = regexp.MustCompile(`^Error reading .* at line (\d+)`)
+	gurobiErrorRE     = regexp.MustCompile(`^Error \d+: (.*)`)
+
+	lineRE = regexp.MustCompile(`(?i)\bline (\d+)`)
+)
+
+// lineOf returns the line number mentioned in msg, or 0.
+func lineOf(msg string) int {
+	if m := lineRE.FindStringSubmatch(msg); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// checkProbeSolver checks that the solver named by -probe-solver is known and installed.
+func checkProbeSolver(name string) error {
+	s, ok := probeSolvers[name]
+	if !ok {
+		return fmt.Errorf("unknown -probe-solver %q (want cplex, gurobi, or highs)", name)
+	}
+	if _, err := exec.LookPath(s.cmd); err != nil {
+		return fmt.Errorf("-probe-solver=%s: %v", name, err)
+	}
+	return nil
+}
+
+// probe has the solver named by -probe-solver read the model called name,
+// which is at path p, or whose contents are data if p is "-",
+// and returns the errors it reports as diagnostics.
+func probe(name, p string, data []byte) ([]lp.Diagnostic, error) {
+	s := probeSolvers[*cmdProbeSolver]
+	if formatOf(name) == lp.FormatProto {
+		return nil, fmt.Errorf("%s: -probe-solver cannot pass protocol buffer models to %s", name, *cmdProbeSolver)
+	}
+	file, err := filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	if p == "-" {
+		// Solvers read files, and tell their formats by extension.
+		dir, err := os.MkdirTemp("", "lpvet-probe")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		ext := ".lp"
+		if f := formatOf(name); f == lp.FormatMPS || f == lp.FormatFreeMPS {
+			ext = ".mps"
+		}
+		file = filepath.Join(dir, "model"+ext)
+		if err := os.WriteFile(file, data, 0666); err != nil {
+			return nil, err
+		}
+	}
+	cmd := exec.Command(s.cmd, s.args(file)...)
+	// Solvers write logs to the working directory.
+	cmd.Dir = os.TempDir()
+	out, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	lines := strings.Split(string(bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))), "\n")
+	errs := s.errors(lines)
+	if len(errs) == 0 && err != nil {
+		// The solver failed without an error we recognize.
+		last := ""
+		for _, l := range lines {
+			if l = strings.TrimSpace(l); l != "" {
+				last = l
+			}
+		}
+		errs = append(errs, probeError{0, fmt.Sprintf("%v: %s", err, last)})
+	}
+	var diags []lp.Diagnostic
+	for _, e := range errs {
+		line := e.line
+		if line == 0 {
+			line = 1
+		}
+		diags = append(diags, lp.Diagnostic{
+			Pos:      lp.Pos{File: name, Line: int32(line)},
+			Check:    "LP016",
+			Severity: lp.Error,
+			Message:  fmt.Sprintf("%s: %s", s.cmd, e.msg),
+		})
+	}
+	return diags, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type jsonMatch struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+	Pos    string `json:"pos,omitempty"`
+}
+
+// queryCmd implements "lpvet query", which lists the variables
+// or constraints of a model that match a query.
+func queryCmd(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet query [-format=text|json] in|- query")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\n%s\n", lp.QueryHelp())
+		os.Exit(2)
+	}
+	rest := parseInterspersed(fs, args)
+	if len(rest) != 2 {
+		fs.Usage()
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("unknown output format %q", *format)
+	}
+	m := mustReadModel(rest[0])
+	ms, err := lp.RunQuery(m, rest[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *format == "json" {
+		out := []jsonMatch{}
+		for _, m := range ms {
+			jm := jsonMatch{Kind: m.Kind, Name: m.Name, Detail: m.Detail}
+			if m.Pos.Line > 0 {
+				jm.Pos = m.Pos.String()
+			}
+			out = append(out, jm)
+		}
+		data, err := json.MarshalIndent(out, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+	} else {
+		for _, m := range ms {
+			detail := m.Detail
+			if len(detail) > 120 {
+				detail = detail[:117] + "..."
+			}
+			fmt.Printf("%s: %s %s: %s\n", posText(m.Pos), m.Kind, m.Name, detail)
+		}
+	}
+	if len(ms) == 0 {
+		os.Exit(1)
+	}
+}
+
+// posText is the position of p, or "-" if it is unknown.
+func posText(p lp.Pos) string {
+	if p.Line == 0 {
+		return "-"
+	}
+	return p.String()
+}
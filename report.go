@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// A reporter outputs the problems found in each file.
+type reporter interface {
+	// report is called once for each file that could be read,
+	// even if it has no problems.
+	report(file string, diags []lp.Diagnostic)
+	close() error
+}
+
+// newReporter returns a reporter for the named output format.
+// color only affects the text format.
+func newReporter(format string, color bool) (reporter, error) {
+	switch format {
+	case "text":
+		return textReporter{color: color}, nil
+	case "json":
+		return &jsonReporter{diags: []jsonDiag{}}, nil
+	case "sarif":
+		return newSARIFReporter(), nil
+	case "github":
+		return githubReporter{}, nil
+	case "checkstyle":
+		return new(checkstyleReporter), nil
+	case "junit":
+		return new(junitReporter), nil
+	case "html":
+		return new(htmlReporter), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// textReporter logs each problem as it is found.
+type textReporter struct {
+	color bool // highlight positions and severities with ANSI escapes
+}
+
+const (
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+func (r textReporter) report(file string, diags []lp.Diagnostic) {
+	for _, d := range diags {
+		if r.color {
+			sev := ansiRed
+			if d.Severity == lp.Warning {
+				sev = ansiYellow
+			}
+			log.Printf("%s%s%s: %s%s%s: %s [%s]",
+				ansiBold, d.Pos, ansiReset, sev, d.Severity, ansiReset, d.Message, d.Check)
+		} else {
+			log.Print(d)
+		}
+		if *cmdShowSource {
+			showSource(os.Stderr, d.Pos)
+		}
+	}
+}
+
+func (r textReporter) close() error { return nil }
+
+type jsonDiag struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Check     string `json:"check"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Symbol    string `json:"symbol,omitempty"`
+}
+
+// jsonReporter writes all problems to stdout as a JSON array.
+type jsonReporter struct {
+	diags []jsonDiag
+}
+
+func (r *jsonReporter) report(file string, diags []lp.Diagnostic) {
+	for _, d := range diags {
+		r.diags = append(r.diags, newJSONDiag(d))
+	}
+}
+
+func newJSONDiag(d lp.Diagnostic) jsonDiag {
+	return jsonDiag{
+		File:      d.Pos.File,
+		Line:      int(d.Pos.Line),
+		Column:    int(d.Pos.Col),
+		EndColumn: int(d.Pos.EndCol),
+		Check:     d.Check,
+		Severity:  d.Severity.String(),
+		Message:   d.Message,
+		Symbol:    d.Symbol,
+	}
+}
+
+func (r *jsonReporter) close() error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r.diags)
+}
+
+// githubReporter prints problems as GitHub Actions workflow commands,
+// which show up as annotations on the lines of a pull request.
+type githubReporter struct{}
+
+var (
+	githubData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func (githubReporter) report(file string, diags []lp.Diagnostic) {
+	for _, d := range diags {
+		var b strings.Builder
+		fmt.Fprintf(&b, "::%s file=%s", d.Severity, githubProperty.Replace(d.Pos.File))
+		if d.Pos.Line > 0 {
+			fmt.Fprintf(&b, ",line=%d", d.Pos.Line)
+		}
+		if d.Pos.Col > 0 {
+			fmt.Fprintf(&b, ",col=%d", d.Pos.Col)
+		}
+		if d.Pos.Col > 0 && d.Pos.EndCol > d.Pos.Col {
+			fmt.Fprintf(&b, ",endColumn=%d", d.Pos.EndCol)
+		}
+		fmt.Fprintf(&b, ",title=%s::%s", githubProperty.Replace("lpvet "+d.Check), githubData.Replace(d.Message))
+		fmt.Println(b.String())
+	}
+}
+
+func (githubReporter) close() error { return nil }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// The subset of SARIF 2.1.0 that lpvet writes.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifReporter writes all problems to stdout as a SARIF log,
+// which GitHub code scanning and other tools can import.
+type sarifReporter struct {
+	run       sarifRun
+	ruleIndex map[string]int
+}
+
+func newSARIFReporter() *sarifReporter {
+	r := &sarifReporter{
+		run: sarifRun{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "lpvet",
+				InformationURI: "https://github.com/uluyol/lpvet",
+			}},
+			Results: []sarifResult{},
+		},
+		ruleIndex: make(map[string]int),
+	}
+	for i, c := range lp.Checks() {
+		summary := c.Doc
+		if i := strings.IndexByte(summary, '\n'); i >= 0 {
+			summary = summary[:i]
+		}
+		r.run.Tool.Driver.Rules = append(r.run.Tool.Driver.Rules, sarifRule{
+			ID:               c.ID,
+			Name:             c.Name,
+			ShortDescription: sarifMessage{summary},
+			FullDescription:  sarifMessage{c.Doc},
+		})
+		r.ruleIndex[c.ID] = i
+	}
+	return r
+}
+
+func (r *sarifReporter) report(file string, diags []lp.Diagnostic) {
+	for _, d := range diags {
+		loc := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.Pos.File)},
+		}
+		if d.Pos.Line > 0 {
+			loc.Region = &sarifRegion{
+				StartLine:   int(d.Pos.Line),
+				StartColumn: int(d.Pos.Col),
+				EndColumn:   int(d.Pos.EndCol),
+			}
+		}
+		r.run.Results = append(r.run.Results, sarifResult{
+			RuleID:    d.Check,
+			RuleIndex: r.ruleIndex[d.Check],
+			Level:     d.Severity.String(),
+			Message:   sarifMessage{d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+}
+
+func (r *sarifReporter) close() error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{r.run},
+	})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// serveCmd implements "lpvet serve", which vets models uploaded over HTTP
+// so that services can use lpvet without running a process per model.
+// The global flags and configuration apply to every request.
+func serveCmd(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "listen on `address`")
+	maxSize := fs.Int64("max-size", 256<<20, "reject models larger than `n` bytes")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet [flags] serve [-addr host:port] [-max-size n]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	if len(parseInterspersed(fs, args)) != 0 {
+		fs.Usage()
+	}
+	s := &modelServer{maxSize: *maxSize}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vet", s.vet)
+	mux.HandleFunc("/stats", s.stats)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("serving on %s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
+
+type modelServer struct {
+	maxSize int64
+}
+
+// read returns the model uploaded in the body of r and its name,
+// which is taken from the name parameter and selects its format,
+// or writes an error to w and returns ok = false.
+func (s *modelServer) read(w http.ResponseWriter, r *http.Request) (name string, data []byte, ok bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "models must be sent with POST", http.StatusMethodNotAllowed)
+		return "", nil, false
+	}
+	name = r.URL.Query().Get("name")
+	if name == "" {
+		name = "model.lp"
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("model is larger than %d bytes", s.maxSize), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return "", nil, false
+	}
+	return name, data, true
+}
+
+// vet responds with the problems in the model as a JSON array,
+// in the form of -format=json.
+// Warnings are included if the warn parameter is true, or by default with -warn.
+func (s *modelServer) vet(w http.ResponseWriter, r *http.Request) {
+	name, data, ok := s.read(w, r)
+	if !ok {
+		return
+	}
+	warn := *cmdIssueWarnings
+	if v := r.URL.Query().Get("warn"); v != "" {
+		var err error
+		if warn, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "warn: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	_, diags, err := analyze(name, bytes.NewReader(data), formatOf(name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out := []jsonDiag{}
+	for _, d := range applyPolicy(diags, warn) {
+		out = append(out, newJSONDiag(d))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// stats responds with the statistics of the model, as "lpvet stats -format=json" reports them.
+// Models with syntax errors are rejected with the errors as in /vet.
+func (s *modelServer) stats(w http.ResponseWriter, r *http.Request) {
+	name, data, ok := s.read(w, r)
+	if !ok {
+		return
+	}
+	m, err := parseModel(name, bytes.NewReader(data))
+	if errs, ok := err.(lp.ErrorList); ok {
+		out := []jsonDiag{}
+		for _, e := range errs {
+			out = append(out, newJSONDiag(e.Diagnostic()))
+		}
+		writeJSON(w, http.StatusUnprocessableEntity, out)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ranges []lp.CoefRange
+	if r.URL.Query().Get("coef") == "true" {
+		ranges = lp.CoefficientRanges(m)
+	}
+	writeJSON(w, http.StatusOK, jsonStats{name, lp.ComputeStats(m), ranges})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
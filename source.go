@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// maxExcerpt is the number of bytes of a line shown around a span.
+const maxExcerpt = 100
+
+// sourceLines caches the lines of files for -show-source.
+// Models read from stdin are added while other files are vetted.
+var (
+	sourceMu    sync.Mutex
+	sourceLines = make(map[string][]string)
+)
+
+// cacheSource records the contents of the file called name.
+func cacheSource(name string, data []byte) {
+	sourceMu.Lock()
+	sourceLines[name] = strings.Split(string(bytes.TrimRight(data, "\n")), "\n")
+	sourceMu.Unlock()
+}
+
+// forgetSource removes name from the cache after it changes.
+func forgetSource(name string) {
+	sourceMu.Lock()
+	delete(sourceLines, name)
+	sourceMu.Unlock()
+}
+
+// linesOf returns the lines of file, or nil if it cannot be read.
+func linesOf(file string) []string {
+	sourceMu.Lock()
+	lines, ok := sourceLines[file]
+	sourceMu.Unlock()
+	if !ok {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(bytes.TrimRight(data, "\n")), "\n")
+		}
+		sourceMu.Lock()
+		sourceLines[file] = lines
+		sourceMu.Unlock()
+	}
+	return lines
+}
+
+func lineAt(pos lp.Pos) (string, bool) {
+	lines := linesOf(pos.File)
+	if pos.Line < 1 || int(pos.Line) > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[pos.Line-1], "\r"), true
+}
+
+// showSource writes the line at pos to w,
+// underlining the span of pos if it has one.
+// Long lines are cut to an excerpt around the span.
+func showSource(w io.Writer, pos lp.Pos) {
+	line, ok := lineAt(pos)
+	if !ok {
+		return
+	}
+	start, end := int(pos.Col)-1, int(pos.EndCol)-1
+	if start < 0 || start > len(line) {
+		start, end = 0, 0
+	}
+	if end <= start {
+		end = start + 1
+	}
+	if end > len(line)+1 {
+		end = len(line) + 1
+	}
+
+	lo, hi := 0, len(line)
+	if len(line) > maxExcerpt {
+		lo = start - maxExcerpt/4
+		if lo < 0 {
+			lo = 0
+		}
+		if hi = lo + maxExcerpt; hi > len(line) {
+			hi = len(line)
+		}
+	}
+	prefix, suffix := "", ""
+	if lo > 0 {
+		prefix = "..."
+	}
+	if hi < len(line) {
+		suffix = "..."
+	}
+	fmt.Fprintf(w, "\t%s%s%s\n", prefix, line[lo:hi], suffix)
+	if pos.Col == 0 {
+		return
+	}
+
+	// Keep tabs so the carets line up with the text.
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", len(prefix)))
+	for i := lo; i < start; i++ {
+		if line[i] == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	if end > hi+1 {
+		end = hi + 1
+	}
+	b.WriteString(strings.Repeat("^", end-start))
+	fmt.Fprintf(w, "\t%s\n", b.String())
+}
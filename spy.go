@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"image"
+	imgcolor "image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+// spyCmd implements "lpvet spy", which draws the nonzero pattern
+// of the constraint matrix of a model.
+func spyCmd(args []string) {
+	fs := flag.NewFlagSet("spy", flag.ExitOnError)
+	out := fs.String("o", "", "write the image to this `file`, ending in .png or .svg")
+	size := fs.Int("size", 800, "draw at most `n` pixels along each side, binning larger matrices")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet spy [-size n] -o out.png|out.svg in|-")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	ins := parseInterspersed(fs, args)
+	if len(ins) != 1 || *out == "" || *size < 1 {
+		fs.Usage()
+	}
+	ext := strings.ToLower(filepath.Ext(*out))
+	if ext != ".png" && ext != ".svg" {
+		log.Fatalf("%s: unknown image format; use .png or .svg", *out)
+	}
+
+	m := lp.ConstraintMatrix(mustReadModel(ins[0]))
+	if len(m.Rows) == 0 || len(m.Cols) == 0 {
+		log.Fatalf("%s: no constraint matrix to draw", ins[0])
+	}
+	w, h := spySize(len(m.Cols), len(m.Rows), *size)
+	var b bytes.Buffer
+	if ext == ".png" {
+		if err := png.Encode(&b, spyImage(m, w, h)); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		writeSpySVG(&b, m, w, h)
+	}
+	writeOutput(*out, b.Bytes())
+}
+
+// spySize returns the size of the image of a matrix with the given
+// number of columns and rows, which is drawn with square cells
+// as large as fit in max pixels, but at least one pixel.
+func spySize(cols, rows, max int) (w, h int) {
+	n := cols
+	if rows > n {
+		n = rows
+	}
+	cell := max / n
+	if cell < 1 {
+		cell = 1
+	}
+	w, h = cols*cell, rows*cell
+	if w > max {
+		w = max
+	}
+	if h > max {
+		h = max
+	}
+	return w, h
+}
+
+// spyImage draws m in a w by h image,
+// setting each pixel covered by a nonzero entry.
+func spyImage(m *lp.Matrix, w, h int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, w, h), imgcolor.Palette{imgcolor.White, imgcolor.Black})
+	rows, cols := len(m.Rows), len(m.Cols)
+	for _, e := range m.Entries {
+		x0, x1 := cellSpan(e.Col, cols, w)
+		y0, y1 := cellSpan(e.Row, rows, h)
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img
+}
+
+// cellSpan returns the pixels [lo, hi) that cell i of n covers
+// in a side of size pixels.
+func cellSpan(i, n, size int) (lo, hi int) {
+	lo, hi = i*size/n, (i+1)*size/n
+	if hi == lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// writeSpySVG draws m as an SVG image of w by h pixels,
+// with a square for each nonzero entry.
+// The names of the row and column are shown on hover.
+func writeSpySVG(b *bytes.Buffer, m *lp.Matrix, w, h int) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" shape-rendering="crispEdges">`+"\n",
+		w, h, len(m.Cols), len(m.Rows))
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", len(m.Cols), len(m.Rows))
+	b.WriteString(`<g fill="black">` + "\n")
+	for _, e := range m.Entries {
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="1" height="1"><title>%s, %s: %g</title></rect>`+"\n",
+			e.Col, e.Row, html.EscapeString(m.Rows[e.Row]), html.EscapeString(m.Cols[e.Col]), e.Value)
+	}
+	b.WriteString("</g>\n</svg>\n")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+type jsonStats struct {
+	File string `json:"file"`
+	lp.Stats
+	CoefRanges []lp.CoefRange `json:"coefRanges,omitempty"`
+}
+
+// statsCmd implements "lpvet stats", which reports the size of models.
+func statsCmd(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	coef := fs.Bool("coef", false, "also list the coefficient range of each constraint")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lpvet stats [-coef] [-format=text|json] f.lp|f.mps|- [...]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+	files := parseInterspersed(fs, args)
+	if len(files) == 0 {
+		fs.Usage()
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("unknown output format %q", *format)
+	}
+	out := []jsonStats{}
+	for _, p := range files {
+		m := mustReadModel(p)
+		st := lp.ComputeStats(m)
+		var ranges []lp.CoefRange
+		if *coef {
+			ranges = lp.CoefficientRanges(m)
+		}
+		if *format == "json" {
+			out = append(out, jsonStats{p, st, ranges})
+			continue
+		}
+		printStats(p, st)
+		printCoefRanges(ranges)
+	}
+	if *format == "json" {
+		data, err := json.MarshalIndent(out, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+	}
+}
+
+func printStats(p string, st lp.Stats) {
+	fmt.Printf("%s:\n", p)
+	for _, r := range statsRows(st) {
+		fmt.Printf("  %-15s %s\n", r[0]+":", r[1])
+	}
+}
+
+// statsRows returns the labels and values that describe st.
+func statsRows(st lp.Stats) [][2]string {
+	// counts lists the nonzero counts of m in the order of keys.
+	counts := func(m map[string]int, keys ...string) string {
+		var parts []string
+		for _, k := range keys {
+			if m[k] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", m[k], k))
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		return " (" + strings.Join(parts, ", ") + ")"
+	}
+	var rows [][2]string
+	row := func(label, value string) { rows = append(rows, [2]string{label, value}) }
+
+	v := fmt.Sprintf("%d%s", st.Variables,
+		counts(st.VariableTypes, "continuous", "general", "binary", "semi-continuous", "semi-integer"))
+	if st.FreeVariables > 0 {
+		v += fmt.Sprintf(", %d free", st.FreeVariables)
+	}
+	row("variables", v)
+	c := fmt.Sprintf("%d%s", st.Constraints, counts(st.Senses, "<=", ">=", "=", "ranged"))
+	if st.Indicators > 0 {
+		c += fmt.Sprintf(", %d indicator", st.Indicators)
+	}
+	if st.QuadraticConstraints > 0 {
+		c += fmt.Sprintf(", %d quadratic", st.QuadraticConstraints)
+	}
+	row("constraints", c)
+	row("nonzeros", fmt.Sprint(st.Nonzeros))
+	if st.MaxCoef > 0 {
+		row("coefficients", fmt.Sprintf("%g to %g (range %.3g)", st.MinCoef, st.MaxCoef, st.MaxCoef/st.MinCoef))
+	}
+	o := plural(st.ObjectiveTerms, "term")
+	if st.QuadraticObjectiveTerms > 0 {
+		o += " and " + plural(st.QuadraticObjectiveTerms, "quadratic term")
+	}
+	if st.Objectives > 1 {
+		o += fmt.Sprintf(" in %d objectives", st.Objectives)
+	}
+	row("objective", o)
+	row("bounds", fmt.Sprint(st.Bounds))
+	for _, x := range []struct {
+		label string
+		n     int
+	}{{"sos", st.SOS}, {"general cons", st.GeneralConstraints}, {"pwl objectives", st.PWLObjectives}} {
+		if x.n > 0 {
+			row(x.label, fmt.Sprint(x.n))
+		}
+	}
+	if len(st.Sections) > 0 {
+		var parts []string
+		for _, s := range st.Sections {
+			parts = append(parts, fmt.Sprintf("%s %d", s.Header, s.Lines))
+		}
+		row("section lines", strings.Join(parts, ", "))
+	}
+	return rows
+}
+
+// printCoefRanges lists the coefficient range of each constraint,
+// aligning the columns.
+func printCoefRanges(ranges []lp.CoefRange) {
+	if len(ranges) == 0 {
+		return
+	}
+	width := 0
+	for _, r := range ranges {
+		if len(r.Name) > width {
+			width = len(r.Name)
+		}
+	}
+	fmt.Printf("  coefficient ranges:\n")
+	for _, r := range ranges {
+		fmt.Printf("    %-*s %-10g %-10g %.3g\n", width, r.Name, r.Min, r.Max, r.Ratio())
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by lpvet's configuration:
+// comments, [tables], and key = value pairs where values are
+// strings, integers, floats, booleans, or arrays of those.
+// Tables are returned as nested maps.
+func parseTOML(name string, r io.Reader) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	cur := root
+	s := bufio.NewScanner(r)
+	lineno := 0
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%s:%d: %s", name, lineno, fmt.Sprintf(format, args...))
+	}
+	for s.Scan() {
+		lineno++
+		line := strings.TrimSpace(stripTOMLComment(s.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, errorf("malformed table header")
+			}
+			cur = root
+			for _, k := range strings.Split(line[1:len(line)-1], ".") {
+				k = unquoteTOMLKey(strings.TrimSpace(k))
+				if k == "" {
+					return nil, errorf("empty table name")
+				}
+				next, ok := cur[k].(map[string]interface{})
+				if !ok {
+					if _, exists := cur[k]; exists {
+						return nil, errorf("%s is not a table", k)
+					}
+					next = make(map[string]interface{})
+					cur[k] = next
+				}
+				cur = next
+			}
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, errorf("expected key = value")
+		}
+		key := unquoteTOMLKey(strings.TrimSpace(line[:eq]))
+		val := strings.TrimSpace(line[eq+1:])
+		// Arrays may span lines.
+		for strings.HasPrefix(val, "[") && !tomlArrayClosed(val) && s.Scan() {
+			lineno++
+			val += " " + strings.TrimSpace(stripTOMLComment(s.Text()))
+		}
+		v, rest, err := parseTOMLValue(val)
+		if err != nil {
+			return nil, errorf("%v", err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, errorf("unexpected %q after value", rest)
+		}
+		if _, exists := cur[key]; exists {
+			return nil, errorf("duplicate key %s", key)
+		}
+		cur[key] = v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// stripTOMLComment removes a trailing # comment outside of strings.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func tomlArrayClosed(val string) bool {
+	_, _, err := parseTOMLValue(val)
+	return err == nil
+}
+
+func unquoteTOMLKey(k string) string {
+	if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
+
+// parseTOMLValue parses the value at the start of s
+// and returns it along with the rest of s.
+func parseTOMLValue(s string) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	if s == "" {
+		return nil, "", fmt.Errorf("missing value")
+	}
+	switch s[0] {
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '"':
+				return b.String(), s[i+1:], nil
+			case '\\':
+				i++
+				if i == len(s) {
+					break
+				}
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(s[i])
+				default:
+					return nil, "", fmt.Errorf("unsupported escape \\%c", s[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return nil, "", fmt.Errorf("unterminated string")
+	case '\'':
+		i := strings.IndexByte(s[1:], '\'')
+		if i < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : i+1], s[i+2:], nil
+	case '[':
+		var arr []interface{}
+		s = strings.TrimLeft(s[1:], " \t")
+		for {
+			if strings.HasPrefix(s, "]") {
+				return arr, s[1:], nil
+			}
+			v, rest, err := parseTOMLValue(s)
+			if err != nil {
+				return nil, "", err
+			}
+			arr = append(arr, v)
+			s = strings.TrimLeft(rest, " \t")
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " \t")
+			} else if !strings.HasPrefix(s, "]") {
+				return nil, "", fmt.Errorf("expected ',' or ']' in array")
+			}
+		}
+	}
+	end := strings.IndexAny(s, " \t,]")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := s[:end], s[end:]
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	if n, err := strconv.ParseInt(strings.ReplaceAll(word, "_", ""), 10, 64); err == nil {
+		return n, rest, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(word, "_", ""), 64); err == nil {
+		return f, rest, nil
+	}
+	return nil, "", fmt.Errorf("invalid value %q", word)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// A fileState is what watch compares to notice that a file changed.
+type fileState struct {
+	mtime time.Time
+	size  int64
+}
+
+// watch vets the models named by args, and then polls them every interval,
+// vetting again the ones that changed. It never returns.
+//
+// Each run ends with a summary.
+// The arguments are expanded again each time so that new files are noticed,
+// and directories are searched recursively even without -r.
+func watch(args []string, interval time.Duration) {
+	for _, arg := range args {
+		if arg == "-" {
+			log.Fatal("-watch cannot read a model from stdin")
+		}
+	}
+	states := make(map[string]fileState)
+	for first := true; ; first = false {
+		files, _, err := expandArgs(args, true)
+		if err != nil {
+			log.Print(err)
+		}
+		seen := make(map[string]bool)
+		var changed []string
+		for _, p := range files {
+			fi, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			seen[p] = true
+			st := fileState{fi.ModTime(), fi.Size()}
+			if old, ok := states[p]; !ok || old.size != st.size || !old.mtime.Equal(st.mtime) {
+				states[p] = st
+				changed = append(changed, p)
+			}
+		}
+		for p := range states {
+			if !seen[p] {
+				delete(states, p)
+			}
+		}
+		if len(changed) > 0 {
+			if !first {
+				log.Printf("%s changed", plural(len(changed), "file"))
+			}
+			for _, p := range changed {
+				forgetSource(p)
+			}
+			// Each run is checked against the whole baseline.
+			if *cmdBaseline != "" {
+				if known, err = readBaseline(*cmdBaseline); err != nil {
+					log.Fatal(err)
+				}
+			}
+			// The summary also shows that fixed files are now clean.
+			if *cmdMerge {
+				// A change to one part can affect the problems in the others.
+				changed = files
+			}
+			vetFiles(changed, true)
+		} else if first {
+			log.Print("no models to watch")
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/uluyol/lpvet/lp"
+)
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type checkstyleLog struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleReporter writes all problems to stdout in Checkstyle's XML format.
+type checkstyleReporter struct {
+	log checkstyleLog
+}
+
+func (r *checkstyleReporter) report(file string, diags []lp.Diagnostic) {
+	f := checkstyleFile{Name: file}
+	for _, d := range diags {
+		f.Errors = append(f.Errors, checkstyleError{
+			Line:     int(d.Pos.Line),
+			Column:   int(d.Pos.Col),
+			Severity: d.Severity.String(),
+			Message:  d.Message,
+			Source:   "lpvet." + d.Check,
+		})
+	}
+	r.log.Files = append(r.log.Files, f)
+}
+
+func (r *checkstyleReporter) close() error {
+	r.log.Version = "4.3"
+	return writeXML(os.Stdout, r.log)
+}
+
+type junitSuites struct {
+	XMLName xml.Name   `xml:"testsuites"`
+	Suites  []junitRun `xml:"testsuite"`
+}
+
+type junitRun struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReporter writes a JUnit XML report to stdout
+// with a test case for each file, which fails if the file has problems.
+type junitReporter struct {
+	run junitRun
+}
+
+func (r *junitReporter) report(file string, diags []lp.Diagnostic) {
+	c := junitCase{Name: file, ClassName: "lpvet"}
+	if len(diags) > 0 {
+		typ := "warning"
+		var text strings.Builder
+		for _, d := range diags {
+			if d.Severity == lp.Error {
+				typ = "error"
+			}
+			fmt.Fprintln(&text, d)
+		}
+		msg := "1 problem"
+		if len(diags) > 1 {
+			msg = fmt.Sprintf("%d problems", len(diags))
+		}
+		c.Failure = &junitFailure{Message: msg, Type: typ, Text: text.String()}
+		r.run.Failures++
+	}
+	r.run.Tests++
+	r.run.Cases = append(r.run.Cases, c)
+}
+
+func (r *junitReporter) close() error {
+	r.run.Name = "lpvet"
+	return writeXML(os.Stdout, junitSuites{Suites: []junitRun{r.run}})
+}